@@ -0,0 +1,301 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func UnlockAccountAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOptions) error {
+	if apiImplementation.UnlockAccountPOST == nil || (*apiImplementation.UnlockAccountPOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON input"}
+	}
+	token, ok := readBody["token"]
+	if !ok {
+		return supertokens.BadInputError{Msg: "Please provide the unlock account token"}
+	}
+	if reflect.TypeOf(token).Kind() != reflect.String {
+		return supertokens.BadInputError{Msg: "The unlock account token must be a string"}
+	}
+
+	result, err := (*apiImplementation.UnlockAccountPOST)(token.(string), options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNLOCK_ACCOUNT_INVALID_TOKEN_ERROR",
+		})
+	}
+}
+
+// sleep delays the response to failed sign ins. It is replaced in tests
+var sleep = time.Sleep
+
+// signInAttempt holds the keys that failed sign ins are counted against. The account is the user if
+// there is one, so that signing in with their email and username counts towards the same lock
+type signInAttempt struct {
+	accountKey string
+	ipKey      string
+	user       *epmodels.User
+}
+
+func getSignInAttempt(options epmodels.APIOptions, identifier string, userContext supertokens.UserContext) (signInAttempt, error) {
+	var (
+		user *epmodels.User
+		err  error
+	)
+	if strings.Contains(identifier, "@") {
+		user, err = (*options.RecipeImplementation.GetUserByEmail)(identifier, userContext)
+	} else if options.Config.UsernameFeature.Enabled {
+		var userID *string
+		userID, err = options.Config.UsernameFeature.GetUserIDByUsername(identifier, userContext)
+		if err == nil && userID != nil {
+			user, err = (*options.RecipeImplementation.GetUserByID)(*userID, userContext)
+		}
+	}
+	if err != nil {
+		return signInAttempt{}, err
+	}
+
+	attempt := signInAttempt{
+		accountKey: "identifier:" + strings.ToLower(strings.TrimSpace(identifier)),
+		user:       user,
+	}
+	if user != nil {
+		attempt.accountKey = getUserBruteForceProtectionKey(user.ID)
+	}
+	// failed sign ins are only counted per IP address if the app has told us how to get it
+	getIPAddress := options.Config.BruteForceProtection.GetIPAddress
+	if getIPAddress != nil && options.Req != nil {
+		if ip := getIPAddress(options.Req); ip != "" {
+			attempt.ipKey = "ip:" + ip
+		}
+	}
+	return attempt, nil
+}
+
+// SignInWithBruteForceProtection checks the password the same way the sign in API does: it is not checked
+// while the account or IP address is locked, failed attempts count towards their locks, and the right
+// password resets the failed attempts of the account. locked is true if the password was not checked.
+// Checking the current password of a signed in user goes through this as well, so it cannot be used to
+// guess the password without limits. options.Req can be nil, in which case only the account is limited
+func SignInWithBruteForceProtection(options epmodels.APIOptions, identifier string, password string, userContext supertokens.UserContext) (response epmodels.SignInResponse, locked bool, err error) {
+	config := options.Config.BruteForceProtection
+	if !config.Enabled {
+		response, err = (*options.RecipeImplementation.SignIn)(identifier, password, userContext)
+		return response, false, err
+	}
+
+	now := getCurrTimeInMS()
+	attempt, err := getSignInAttempt(options, identifier, userContext)
+	if err != nil {
+		return epmodels.SignInResponse{}, false, err
+	}
+	locked, err = isSignInLocked(config, attempt, now, userContext)
+	if err != nil || locked {
+		return epmodels.SignInResponse{}, locked, err
+	}
+
+	response, err = (*options.RecipeImplementation.SignIn)(identifier, password, userContext)
+	if err != nil {
+		return epmodels.SignInResponse{}, false, err
+	}
+	if response.WrongCredentialsError != nil {
+		err = recordFailedSignIn(options, attempt, now, userContext)
+	} else {
+		err = config.Store.ResetFailedAttempts(attempt.accountKey, userContext)
+	}
+	if err != nil {
+		return epmodels.SignInResponse{}, false, err
+	}
+	return response, false, nil
+}
+
+func getUserBruteForceProtectionKey(userID string) string {
+	return "user:" + userID
+}
+
+func isSignInLocked(config epmodels.TypeNormalisedInputBruteForceProtection, attempt signInAttempt, now uint64, userContext supertokens.UserContext) (bool, error) {
+	for _, key := range []string{attempt.accountKey, attempt.ipKey} {
+		if key == "" {
+			continue
+		}
+		lock, err := config.Store.GetLock(key, userContext)
+		if err != nil {
+			return false, err
+		}
+		if lock != nil && lock.LockedUntil > now {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordFailedSignIn locks the account or IP address once they reach their maximum number of failed
+// sign ins, and then delays the response
+func recordFailedSignIn(options epmodels.APIOptions, attempt signInAttempt, now uint64, userContext supertokens.UserContext) error {
+	config := options.Config.BruteForceProtection
+	accountFailedAttempts, err := config.Store.IncrementFailedAttempts(attempt.accountKey, config.FailedAttemptsWindowMS, userContext)
+	if err != nil {
+		return err
+	}
+	if accountFailedAttempts >= config.MaxFailedAttemptsPerAccount {
+		err = lockAccount(options, attempt, now, userContext)
+		if err != nil {
+			return err
+		}
+	}
+
+	if attempt.ipKey != "" {
+		ipFailedAttempts, err := config.Store.IncrementFailedAttempts(attempt.ipKey, config.FailedAttemptsWindowMS, userContext)
+		if err != nil {
+			return err
+		}
+		if ipFailedAttempts >= config.MaxFailedAttemptsPerIP {
+			err = config.Store.SetLock(attempt.ipKey, epmodels.SignInLock{LockedUntil: now + config.LockoutDurationMS}, userContext)
+			if err != nil {
+				return err
+			}
+			err = config.Store.ResetFailedAttempts(attempt.ipKey, userContext)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	sleep(getFailedSignInDelay(config, accountFailedAttempts))
+	return nil
+}
+
+func getFailedSignInDelay(config epmodels.TypeNormalisedInputBruteForceProtection, failedAttempts int) time.Duration {
+	if config.InitialDelayMS == 0 || failedAttempts <= 0 {
+		return 0
+	}
+	delayMS := config.InitialDelayMS
+	for i := 1; i < failedAttempts && delayMS < config.MaxDelayMS; i++ {
+		delayMS *= 2
+	}
+	if delayMS > config.MaxDelayMS {
+		delayMS = config.MaxDelayMS
+	}
+	return time.Duration(delayMS) * time.Millisecond
+}
+
+// lockAccount starts counting failed sign ins from zero once the lock expires. The user is sent a link
+// that unlocks their account if CreateAndSendCustomEmail is set
+func lockAccount(options epmodels.APIOptions, attempt signInAttempt, now uint64, userContext supertokens.UserContext) error {
+	config := options.Config.BruteForceProtection
+	lock := epmodels.SignInLock{LockedUntil: now + config.LockoutDurationMS}
+
+	var unlockToken string
+	user := attempt.user
+	sendEmail := user != nil && config.CreateAndSendCustomEmail != nil && !strings.HasSuffix(user.Email, "@"+epmodels.UsernamePlaceholderEmailDomain)
+	if sendEmail {
+		secret := make([]byte, 32)
+		_, err := rand.Read(secret)
+		if err != nil {
+			return err
+		}
+		encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+		lock.UnlockTokenHash = hashUnlockAccountSecret(encodedSecret)
+		unlockToken = encodedSecret + "." + base64.RawURLEncoding.EncodeToString([]byte(user.ID))
+	}
+
+	err := config.Store.SetLock(attempt.accountKey, lock, userContext)
+	if err != nil {
+		return err
+	}
+	err = config.Store.ResetFailedAttempts(attempt.accountKey, userContext)
+	if err != nil {
+		return err
+	}
+
+	if sendEmail {
+		unlockAccountLink, err := config.GetUnlockAccountURL(*user, userContext)
+		if err != nil {
+			return err
+		}
+		unlockAccountLink = unlockAccountLink + "?token=" + unlockToken + "&rid=" + options.RecipeID
+		config.CreateAndSendCustomEmail(*user, unlockAccountLink, userContext)
+	}
+	return nil
+}
+
+func hashUnlockAccountSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// unlockAccountUsingToken returns false if the token is not the one from the email sent for the current lock
+func unlockAccountUsingToken(config epmodels.TypeNormalisedInputBruteForceProtection, token string, now uint64, userContext supertokens.UserContext) (bool, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return false, nil
+	}
+	userID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(userID) == 0 {
+		return false, nil
+	}
+	lock, err := config.Store.GetLock(getUserBruteForceProtectionKey(string(userID)), userContext)
+	if err != nil {
+		return false, err
+	}
+	if lock == nil || lock.LockedUntil <= now || lock.UnlockTokenHash == "" {
+		return false, nil
+	}
+	if !hmac.Equal([]byte(hashUnlockAccountSecret(parts[0])), []byte(lock.UnlockTokenHash)) {
+		return false, nil
+	}
+	return true, UnlockAccount(config, string(userID), userContext)
+}
+
+// UnlockAccount removes the lock on the account and forgets its failed sign ins
+func UnlockAccount(config epmodels.TypeNormalisedInputBruteForceProtection, userID string, userContext supertokens.UserContext) error {
+	key := getUserBruteForceProtectionKey(userID)
+	err := config.Store.RemoveLock(key, userContext)
+	if err != nil {
+		return err
+	}
+	return config.Store.ResetFailedAttempts(key, userContext)
+}
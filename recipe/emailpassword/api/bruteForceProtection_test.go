@@ -0,0 +1,272 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func getBruteForceProtectionOptionsForTests(sentLinks *[]string) epmodels.APIOptions {
+	failedAttempts := map[string]int{}
+	locks := map[string]epmodels.SignInLock{}
+	users := map[string]epmodels.User{
+		"test@example.com": {ID: "user1", Email: "test@example.com"},
+	}
+	getUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, error) {
+		user, ok := users[email]
+		if !ok {
+			return nil, nil
+		}
+		return &user, nil
+	}
+	req := httptest.NewRequest(http.MethodPost, "/auth/signin", nil)
+	return epmodels.APIOptions{
+		RecipeID: "emailpassword",
+		Req:      req,
+		RecipeImplementation: epmodels.RecipeInterface{
+			GetUserByEmail: &getUserByEmail,
+		},
+		Config: epmodels.TypeNormalisedInput{
+			BruteForceProtection: epmodels.TypeNormalisedInputBruteForceProtection{
+				Enabled:                     true,
+				MaxFailedAttemptsPerAccount: 3,
+				MaxFailedAttemptsPerIP:      5,
+				FailedAttemptsWindowMS:      60000,
+				LockoutDurationMS:           60000,
+				InitialDelayMS:              100,
+				MaxDelayMS:                  300,
+				GetIPAddress: func(req *http.Request) string {
+					return "203.0.113.7"
+				},
+				GetUnlockAccountURL: func(user epmodels.User, userContext supertokens.UserContext) (string, error) {
+					return "https://example.com/unlock-account", nil
+				},
+				CreateAndSendCustomEmail: func(user epmodels.User, unlockAccountURLWithToken string, userContext supertokens.UserContext) {
+					*sentLinks = append(*sentLinks, unlockAccountURLWithToken)
+				},
+				Store: epmodels.BruteForceProtectionStore{
+					IncrementFailedAttempts: func(key string, windowMS uint64, userContext supertokens.UserContext) (int, error) {
+						failedAttempts[key]++
+						return failedAttempts[key], nil
+					},
+					ResetFailedAttempts: func(key string, userContext supertokens.UserContext) error {
+						delete(failedAttempts, key)
+						return nil
+					},
+					GetLock: func(key string, userContext supertokens.UserContext) (*epmodels.SignInLock, error) {
+						lock, ok := locks[key]
+						if !ok {
+							return nil, nil
+						}
+						return &lock, nil
+					},
+					SetLock: func(key string, lock epmodels.SignInLock, userContext supertokens.UserContext) error {
+						locks[key] = lock
+						return nil
+					},
+					RemoveLock: func(key string, userContext supertokens.UserContext) error {
+						delete(locks, key)
+						return nil
+					},
+				},
+			},
+		},
+	}
+}
+
+func mockSleepForTests(delays *[]time.Duration) func() {
+	sleep = func(d time.Duration) {
+		*delays = append(*delays, d)
+	}
+	return func() {
+		sleep = time.Sleep
+	}
+}
+
+func TestAccountIsLockedAfterFailedSignIns(t *testing.T) {
+	var delays []time.Duration
+	defer mockSleepForTests(&delays)()
+	var sentLinks []string
+	options := getBruteForceProtectionOptionsForTests(&sentLinks)
+	config := options.Config.BruteForceProtection
+	userContext := &map[string]interface{}{}
+	now := uint64(1000)
+
+	attempt, err := getSignInAttempt(options, "test@example.com", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "user:user1", attempt.accountKey)
+	assert.Equal(t, "ip:203.0.113.7", attempt.ipKey)
+
+	for i := 0; i < 3; i++ {
+		locked, err := isSignInLocked(config, attempt, now, userContext)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.NoError(t, recordFailedSignIn(options, attempt, now, userContext))
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, delays)
+
+	locked, err := isSignInLocked(config, attempt, now, userContext)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	locked, err = isSignInLocked(config, attempt, now+60000, userContext)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+
+	assert.Len(t, sentLinks, 1)
+	assert.True(t, strings.HasPrefix(sentLinks[0], "https://example.com/unlock-account?token="))
+	assert.True(t, strings.HasSuffix(sentLinks[0], "&rid=emailpassword"))
+}
+
+func TestUnlockAccountUsingToken(t *testing.T) {
+	var delays []time.Duration
+	defer mockSleepForTests(&delays)()
+	var sentLinks []string
+	options := getBruteForceProtectionOptionsForTests(&sentLinks)
+	config := options.Config.BruteForceProtection
+	userContext := &map[string]interface{}{}
+	now := uint64(1000)
+
+	attempt, err := getSignInAttempt(options, "test@example.com", userContext)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, recordFailedSignIn(options, attempt, now, userContext))
+	}
+	token := strings.TrimSuffix(strings.TrimPrefix(sentLinks[0], "https://example.com/unlock-account?token="), "&rid=emailpassword")
+
+	unlocked, err := unlockAccountUsingToken(config, "invalid."+strings.Split(token, ".")[1], now, userContext)
+	assert.NoError(t, err)
+	assert.False(t, unlocked)
+
+	unlocked, err = unlockAccountUsingToken(config, token, now, userContext)
+	assert.NoError(t, err)
+	assert.True(t, unlocked)
+	locked, err := isSignInLocked(config, attempt, now, userContext)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+
+	unlocked, err = unlockAccountUsingToken(config, token, now, userContext)
+	assert.NoError(t, err)
+	assert.False(t, unlocked)
+}
+
+func TestUnknownAccountsAndIPAddressesAreLocked(t *testing.T) {
+	var delays []time.Duration
+	defer mockSleepForTests(&delays)()
+	var sentLinks []string
+	options := getBruteForceProtectionOptionsForTests(&sentLinks)
+	config := options.Config.BruteForceProtection
+	userContext := &map[string]interface{}{}
+	now := uint64(1000)
+
+	for i := 0; i < 5; i++ {
+		attempt, err := getSignInAttempt(options, "Unknown"+string(rune('a'+i))+"@example.com", userContext)
+		assert.NoError(t, err)
+		assert.Nil(t, attempt.user)
+		assert.NoError(t, recordFailedSignIn(options, attempt, now, userContext))
+	}
+	assert.Empty(t, sentLinks)
+
+	// every sign in from the IP address is locked, including for other accounts
+	attempt, err := getSignInAttempt(options, "test@example.com", userContext)
+	assert.NoError(t, err)
+	locked, err := isSignInLocked(config, attempt, now, userContext)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	assert.NoError(t, UnlockAccount(config, "user1", userContext))
+	locked, err = isSignInLocked(config, attempt, now, userContext)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+}
+
+func TestIPAddressesAreOnlyLimitedIfGetIPAddressIsSet(t *testing.T) {
+	var sentLinks []string
+	options := getBruteForceProtectionOptionsForTests(&sentLinks)
+	options.Config.BruteForceProtection.GetIPAddress = nil
+	userContext := &map[string]interface{}{}
+
+	attempt, err := getSignInAttempt(options, "test@example.com", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "user:user1", attempt.accountKey)
+	assert.Equal(t, "", attempt.ipKey)
+
+	options = getBruteForceProtectionOptionsForTests(&sentLinks)
+	options.Req = nil
+	attempt, err = getSignInAttempt(options, "test@example.com", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "", attempt.ipKey)
+}
+
+func TestSignInWithBruteForceProtection(t *testing.T) {
+	var delays []time.Duration
+	defer mockSleepForTests(&delays)()
+	var sentLinks []string
+	options := getBruteForceProtectionOptionsForTests(&sentLinks)
+	signInCalls := 0
+	signIn := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
+		signInCalls++
+		if password != "password1" {
+			return epmodels.SignInResponse{WrongCredentialsError: &struct{}{}}, nil
+		}
+		return epmodels.SignInResponse{OK: &struct{ User epmodels.User }{User: epmodels.User{ID: "user1", Email: email}}}, nil
+	}
+	options.RecipeImplementation.SignIn = &signIn
+	userContext := &map[string]interface{}{}
+
+	// the right password resets the failed attempts of the account
+	for i := 0; i < 2; i++ {
+		response, locked, err := SignInWithBruteForceProtection(options, "test@example.com", "wrong", userContext)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.NotNil(t, response.WrongCredentialsError)
+	}
+	response, locked, err := SignInWithBruteForceProtection(options, "test@example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.NotNil(t, response.OK)
+
+	for i := 0; i < 3; i++ {
+		_, locked, err = SignInWithBruteForceProtection(options, "test@example.com", "wrong", userContext)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+	}
+	assert.Equal(t, 6, signInCalls)
+
+	// the password is not checked at all while the account is locked
+	_, locked, err = SignInWithBruteForceProtection(options, "test@example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.Equal(t, 6, signInCalls)
+}
+
+func TestFailedSignInDelay(t *testing.T) {
+	config := epmodels.TypeNormalisedInputBruteForceProtection{InitialDelayMS: 250, MaxDelayMS: 5000}
+	assert.Equal(t, time.Duration(0), getFailedSignInDelay(config, 0))
+	assert.Equal(t, 250*time.Millisecond, getFailedSignInDelay(config, 1))
+	assert.Equal(t, 1000*time.Millisecond, getFailedSignInDelay(config, 3))
+	assert.Equal(t, 5000*time.Millisecond, getFailedSignInDelay(config, 100))
+
+	config.InitialDelayMS = 0
+	assert.Equal(t, time.Duration(0), getFailedSignInDelay(config, 3))
+}
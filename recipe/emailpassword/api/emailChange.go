@@ -0,0 +1,246 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func EmailChange(apiImplementation epmodels.APIInterface, options epmodels.APIOptions) error {
+	if apiImplementation.EmailChangePOST == nil || (*apiImplementation.EmailChangePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var formFieldsRaw map[string]interface{}
+	err = json.Unmarshal(body, &formFieldsRaw)
+	if err != nil {
+		return err
+	}
+
+	// the new email goes through the same validation as the one used to sign up
+	formFields, err := validateFormFieldsOrThrowError(options.Config.ResetPasswordUsingTokenFeature.FormFieldsForGenerateTokenForm, formFieldsRaw["formFields"].([]interface{}))
+	if err != nil {
+		return err
+	}
+	newEmail := getEmailFromFormFields(formFields)
+	err = validateEmailPolicyOrThrowError(options.Config.EmailPolicy, newEmail)
+	if err != nil {
+		return err
+	}
+	// the new email is kept in the email change token, so it is stored in its normalised form
+	newEmail = supertokens.NormaliseEmail(options.Config.EmailPolicy, newEmail)
+
+	result, err := (*apiImplementation.EmailChangePOST)(newEmail, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else {
+		return errors.FieldError{
+			Msg: "Error in input formFields",
+			Payload: []errors.ErrorPayload{{
+				ID:       "email",
+				ErrorMsg: "This email already exists. Please use another email.",
+			}},
+		}
+	}
+}
+
+func EmailChangeVerify(apiImplementation epmodels.APIInterface, options epmodels.APIOptions) error {
+	if apiImplementation.EmailChangeVerifyPOST == nil || (*apiImplementation.EmailChangeVerifyPOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	token, err := getEmailChangeTokenFromBody(options)
+	if err != nil {
+		return err
+	}
+
+	result, err := (*apiImplementation.EmailChangeVerifyPOST)(token, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"user":   result.OK.User,
+		})
+	} else if result.EmailAlreadyExistsError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "EMAIL_ALREADY_EXISTS_ERROR",
+		})
+	} else {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "EMAIL_CHANGE_INVALID_TOKEN_ERROR",
+		})
+	}
+}
+
+func EmailChangeRevert(apiImplementation epmodels.APIInterface, options epmodels.APIOptions) error {
+	if apiImplementation.EmailChangeRevertPOST == nil || (*apiImplementation.EmailChangeRevertPOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	token, err := getEmailChangeTokenFromBody(options)
+	if err != nil {
+		return err
+	}
+
+	result, err := (*apiImplementation.EmailChangeRevertPOST)(token, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"user":   result.OK.User,
+		})
+	} else if result.EmailAlreadyExistsError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "EMAIL_ALREADY_EXISTS_ERROR",
+		})
+	} else {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "EMAIL_CHANGE_INVALID_TOKEN_ERROR",
+		})
+	}
+}
+
+func getEmailChangeTokenFromBody(options epmodels.APIOptions) (string, error) {
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return "", err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return "", supertokens.BadInputError{Msg: "Please provide a JSON input"}
+	}
+	token, ok := readBody["token"]
+	if !ok {
+		return "", supertokens.BadInputError{Msg: "Please provide the email change token"}
+	}
+	if reflect.TypeOf(token).Kind() != reflect.String {
+		return "", supertokens.BadInputError{Msg: "The email change token must be a string"}
+	}
+	return token.(string), nil
+}
+
+const (
+	emailChangeTokenPurposeChange = "change"
+	emailChangeTokenPurposeRevert = "revert"
+)
+
+// emailChangeToken confirms an email change from the new address, or lets
+// the previous address undo it. It is signed rather than stored, and the
+// purpose keeps one kind of token from being used as the other. A change
+// token only applies while the user still has the previous email, and a
+// revert token only while they have the new one, so each stops working once
+// it has been used.
+type emailChangeToken struct {
+	Purpose       string `json:"typ"`
+	UserID        string `json:"sub"`
+	PreviousEmail string `json:"prev"`
+	NewEmail      string `json:"new"`
+	Expiry        uint64 `json:"exp"`
+}
+
+func signEmailChangeToken(signingKey []byte, payload string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func createEmailChangeToken(config epmodels.TypeNormalisedInputEmailChangeFeature, purpose string, userID string, previousEmail string, newEmail string) (string, error) {
+	validity := config.TokenValidityMS
+	if purpose == emailChangeTokenPurposeRevert {
+		validity = config.RevertTokenValidityMS
+	}
+	payloadBytes, err := json.Marshal(emailChangeToken{
+		Purpose:       purpose,
+		UserID:        userID,
+		PreviousEmail: previousEmail,
+		NewEmail:      newEmail,
+		Expiry:        getCurrTimeInMS() + validity,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	return payload + "." + signEmailChangeToken(config.SigningKey, payload), nil
+}
+
+// verifyEmailChangeToken returns nil if the token is not signed with the signing key, is
+// for another purpose or has expired
+func verifyEmailChangeToken(config epmodels.TypeNormalisedInputEmailChangeFeature, purpose string, token string, now uint64) *emailChangeToken {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(signEmailChangeToken(config.SigningKey, parts[0]))) {
+		return nil
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil
+	}
+	var changeToken emailChangeToken
+	err = json.Unmarshal(payloadBytes, &changeToken)
+	if err != nil || changeToken.Purpose != purpose || changeToken.UserID == "" || changeToken.PreviousEmail == "" || changeToken.NewEmail == "" {
+		return nil
+	}
+	if changeToken.Expiry < now {
+		return nil
+	}
+	return &changeToken
+}
+
+func markEmailAsVerified(options epmodels.APIOptions, userID string, email string, userContext supertokens.UserContext) error {
+	tokenResponse, err := (*options.EmailVerificationRecipeImplementation.CreateEmailVerificationToken)(userID, email, userContext)
+	if err != nil {
+		return err
+	}
+	if tokenResponse.EmailAlreadyVerifiedError != nil {
+		return nil
+	}
+	_, err = (*options.EmailVerificationRecipeImplementation.VerifyEmailUsingToken)(tokenResponse.OK.Token, userContext)
+	return err
+}
+
+func getCurrTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / 1000000)
+}
@@ -0,0 +1,59 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+func TestEmailChangeToken(t *testing.T) {
+	config := epmodels.TypeNormalisedInputEmailChangeFeature{
+		Enabled:               true,
+		SigningKey:            []byte("0123456789abcdef0123456789abcdef"),
+		TokenValidityMS:       30000,
+		RevertTokenValidityMS: 60000,
+	}
+	token, err := createEmailChangeToken(config, emailChangeTokenPurposeRevert, "user1", "old@example.com", "new@example.com")
+	assert.NoError(t, err)
+
+	revertToken := verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, token, getCurrTimeInMS())
+	assert.NotNil(t, revertToken)
+	assert.Equal(t, "user1", revertToken.UserID)
+	assert.Equal(t, "old@example.com", revertToken.PreviousEmail)
+	assert.Equal(t, "new@example.com", revertToken.NewEmail)
+
+	assert.NotNil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, token, getCurrTimeInMS()+59000))
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, token, getCurrTimeInMS()+60001))
+
+	otherConfig := config
+	otherConfig.SigningKey = []byte("fedcba9876543210fedcba9876543210")
+	assert.Nil(t, verifyEmailChangeToken(otherConfig, emailChangeTokenPurposeRevert, token, getCurrTimeInMS()))
+
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, "not-a-token", getCurrTimeInMS()))
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, token+"x", getCurrTimeInMS()))
+
+	// a token for one purpose cannot be used for the other
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeChange, token, getCurrTimeInMS()))
+
+	changeToken, err := createEmailChangeToken(config, emailChangeTokenPurposeChange, "user1", "old@example.com", "new@example.com")
+	assert.NoError(t, err)
+	assert.NotNil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeChange, changeToken, getCurrTimeInMS()))
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeChange, changeToken, getCurrTimeInMS()+30001))
+	assert.Nil(t, verifyEmailChangeToken(config, emailChangeTokenPurposeRevert, changeToken, getCurrTimeInMS()))
+}
@@ -16,6 +16,8 @@
 package api
 
 import (
+	defaultErrors "errors"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
@@ -33,6 +35,16 @@ func MakeAPIImplementation() epmodels.APIInterface {
 		}, nil
 	}
 
+	usernameExistsGET := func(username string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.UsernameExistsGETResponse, error) {
+		userID, err := options.Config.UsernameFeature.GetUserIDByUsername(username, userContext)
+		if err != nil {
+			return epmodels.UsernameExistsGETResponse{}, err
+		}
+		return epmodels.UsernameExistsGETResponse{
+			OK: &struct{ Exists bool }{Exists: userID != nil},
+		}, nil
+	}
+
 	generatePasswordResetTokenPOST := func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
 		var email string
 		for _, formField := range formFields {
@@ -107,10 +119,15 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			}
 		}
 
-		response, err := (*options.RecipeImplementation.SignIn)(email, password, userContext)
+		response, locked, err := SignInWithBruteForceProtection(options, email, password, userContext)
 		if err != nil {
 			return epmodels.SignInPOSTResponse{}, err
 		}
+		if locked {
+			return epmodels.SignInPOSTResponse{
+				LockedAccountError: &struct{}{},
+			}, nil
+		}
 		if response.WrongCredentialsError != nil {
 			return epmodels.SignInPOSTResponse{
 				WrongCredentialsError: &struct{}{},
@@ -118,7 +135,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 		}
 
 		user := response.OK.User
-		session, err := session.CreateNewSessionWithContext(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		session, err := session.CreateNewSessionUpgradingGuestSessionWithContext(options.Req, options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
 			return epmodels.SignInPOSTResponse{}, err
 		}
@@ -160,7 +177,7 @@ func MakeAPIImplementation() epmodels.APIInterface {
 
 		user := response.OK.User
 
-		session, err := session.CreateNewSessionWithContext(options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		session, err := session.CreateNewSessionUpgradingGuestSessionWithContext(options.Req, options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
 			return epmodels.SignUpPOSTResponse{}, err
 		}
@@ -175,11 +192,277 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			},
 		}, nil
 	}
+
+	changePasswordPOST := func(oldPassword string, formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ChangePasswordPOSTResponse, error) {
+		sessionContainer, err := session.GetSessionWithContext(options.Req, options.Res, nil, userContext)
+		if err != nil {
+			return epmodels.ChangePasswordPOSTResponse{}, err
+		}
+		if sessionContainer == nil {
+			return epmodels.ChangePasswordPOSTResponse{}, defaultErrors.New("session is nil. Should not come here")
+		}
+		userID := sessionContainer.GetUserIDWithContext(userContext)
+
+		user, err := (*options.RecipeImplementation.GetUserByID)(userID, userContext)
+		if err != nil {
+			return epmodels.ChangePasswordPOSTResponse{}, err
+		}
+		if user == nil {
+			return epmodels.ChangePasswordPOSTResponse{}, defaultErrors.New("unknown User ID provided")
+		}
+
+		signInResponse, locked, err := SignInWithBruteForceProtection(options, user.Email, oldPassword, userContext)
+		if err != nil {
+			return epmodels.ChangePasswordPOSTResponse{}, err
+		}
+		if locked {
+			return epmodels.ChangePasswordPOSTResponse{
+				LockedAccountError: &struct{}{},
+			}, nil
+		}
+		if signInResponse.WrongCredentialsError != nil {
+			return epmodels.ChangePasswordPOSTResponse{
+				WrongCredentialsError: &struct{}{},
+			}, nil
+		}
+
+		newPassword := getPasswordFromFormFields(formFields)
+		updateResponse, err := (*options.RecipeImplementation.UpdateEmailOrPassword)(userID, nil, &newPassword, userContext)
+		if err != nil {
+			return epmodels.ChangePasswordPOSTResponse{}, err
+		}
+		if updateResponse.OK == nil {
+			return epmodels.ChangePasswordPOSTResponse{}, defaultErrors.New("could not update the password of the user")
+		}
+
+		if options.Config.ChangePasswordFeature.RevokeOtherSessions {
+			sessionHandles, err := session.GetAllSessionHandlesForUserWithContext(userID, userContext)
+			if err != nil {
+				return epmodels.ChangePasswordPOSTResponse{}, err
+			}
+			currentSessionHandle := sessionContainer.GetHandleWithContext(userContext)
+			otherSessionHandles := []string{}
+			for _, sessionHandle := range sessionHandles {
+				if sessionHandle != currentSessionHandle {
+					otherSessionHandles = append(otherSessionHandles, sessionHandle)
+				}
+			}
+			if len(otherSessionHandles) > 0 {
+				_, err = session.RevokeMultipleSessionsWithContext(otherSessionHandles, userContext)
+				if err != nil {
+					return epmodels.ChangePasswordPOSTResponse{}, err
+				}
+			}
+		}
+
+		return epmodels.ChangePasswordPOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
+	emailChangePOST := func(newEmail string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.EmailChangePOSTResponse, error) {
+		sessionContainer, err := session.GetSessionWithContext(options.Req, options.Res, nil, userContext)
+		if err != nil {
+			return epmodels.EmailChangePOSTResponse{}, err
+		}
+		if sessionContainer == nil {
+			return epmodels.EmailChangePOSTResponse{}, defaultErrors.New("session is nil. Should not come here")
+		}
+		userID := sessionContainer.GetUserIDWithContext(userContext)
+
+		user, err := (*options.RecipeImplementation.GetUserByID)(userID, userContext)
+		if err != nil {
+			return epmodels.EmailChangePOSTResponse{}, err
+		}
+		if user == nil {
+			return epmodels.EmailChangePOSTResponse{}, defaultErrors.New("unknown User ID provided")
+		}
+		if user.Email == newEmail {
+			return epmodels.EmailChangePOSTResponse{
+				OK: &struct{}{},
+			}, nil
+		}
+
+		existingUser, err := (*options.RecipeImplementation.GetUserByEmail)(newEmail, userContext)
+		if err != nil {
+			return epmodels.EmailChangePOSTResponse{}, err
+		}
+		if existingUser != nil {
+			return epmodels.EmailChangePOSTResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+
+		// the pending address is kept in a signed token of its own, so that
+		// the link cannot be used with the email verification API
+		token, err := createEmailChangeToken(options.Config.EmailChangeFeature, emailChangeTokenPurposeChange, userID, user.Email, newEmail)
+		if err != nil {
+			return epmodels.EmailChangePOSTResponse{}, err
+		}
+
+		emailChangeLink, err := options.Config.EmailChangeFeature.GetEmailChangeURL(*user, newEmail, userContext)
+		if err != nil {
+			return epmodels.EmailChangePOSTResponse{}, err
+		}
+		emailChangeLink = emailChangeLink + "?token=" + token + "&rid=" + options.RecipeID
+
+		options.Config.EmailChangeFeature.CreateAndSendCustomEmail(*user, newEmail, emailChangeLink, userContext)
+
+		return epmodels.EmailChangePOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
+	emailChangeVerifyPOST := func(token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.EmailChangeVerifyPOSTResponse, error) {
+		changeToken := verifyEmailChangeToken(options.Config.EmailChangeFeature, emailChangeTokenPurposeChange, token, getCurrTimeInMS())
+		if changeToken == nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		userID := changeToken.UserID
+		newEmail := changeToken.NewEmail
+
+		user, err := (*options.RecipeImplementation.GetUserByID)(userID, userContext)
+		if err != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{}, err
+		}
+		if user == nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		if user.Email == newEmail {
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				OK: &struct{ User epmodels.User }{User: *user},
+			}, nil
+		}
+		if user.Email != changeToken.PreviousEmail {
+			// the email has changed since this token was sent
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		previousUser := *user
+
+		updateResponse, err := (*options.RecipeImplementation.UpdateEmailOrPassword)(userID, &newEmail, nil, userContext)
+		if err != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{}, err
+		}
+		if updateResponse.EmailAlreadyExistsError != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		if updateResponse.UnknownUserIdError != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+
+		// following the link proved that the user owns the new address
+		err = markEmailAsVerified(options, userID, newEmail, userContext)
+		if err != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{}, err
+		}
+
+		revertToken, err := createEmailChangeToken(options.Config.EmailChangeFeature, emailChangeTokenPurposeRevert, userID, previousUser.Email, newEmail)
+		if err != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{}, err
+		}
+		revertLink, err := options.Config.EmailChangeFeature.GetEmailChangeRevertURL(previousUser, userContext)
+		if err != nil {
+			return epmodels.EmailChangeVerifyPOSTResponse{}, err
+		}
+		revertLink = revertLink + "?token=" + revertToken + "&rid=" + options.RecipeID
+
+		options.Config.EmailChangeFeature.CreateAndSendCustomRevertEmail(previousUser, newEmail, revertLink, userContext)
+
+		if options.Config.EmailChangeFeature.RevokeSessionsOnEmailChange {
+			_, err = session.RevokeAllSessionsForUserWithContext(userID, userContext)
+			if err != nil {
+				return epmodels.EmailChangeVerifyPOSTResponse{}, err
+			}
+		}
+
+		user.Email = newEmail
+		return epmodels.EmailChangeVerifyPOSTResponse{
+			OK: &struct{ User epmodels.User }{User: *user},
+		}, nil
+	}
+
+	emailChangeRevertPOST := func(token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.EmailChangeRevertPOSTResponse, error) {
+		revertToken := verifyEmailChangeToken(options.Config.EmailChangeFeature, emailChangeTokenPurposeRevert, token, getCurrTimeInMS())
+		if revertToken == nil {
+			return epmodels.EmailChangeRevertPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+
+		user, err := (*options.RecipeImplementation.GetUserByID)(revertToken.UserID, userContext)
+		if err != nil {
+			return epmodels.EmailChangeRevertPOSTResponse{}, err
+		}
+		if user == nil || user.Email != revertToken.NewEmail {
+			return epmodels.EmailChangeRevertPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+
+		updateResponse, err := (*options.RecipeImplementation.UpdateEmailOrPassword)(user.ID, &revertToken.PreviousEmail, nil, userContext)
+		if err != nil {
+			return epmodels.EmailChangeRevertPOSTResponse{}, err
+		}
+		if updateResponse.EmailAlreadyExistsError != nil {
+			return epmodels.EmailChangeRevertPOSTResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		if updateResponse.UnknownUserIdError != nil {
+			return epmodels.EmailChangeRevertPOSTResponse{
+				EmailChangeInvalidTokenError: &struct{}{},
+			}, nil
+		}
+
+		// a change that the owner of the previous address did not ask for may
+		// mean that someone else has access to the account
+		_, err = session.RevokeAllSessionsForUserWithContext(user.ID, userContext)
+		if err != nil {
+			return epmodels.EmailChangeRevertPOSTResponse{}, err
+		}
+
+		user.Email = revertToken.PreviousEmail
+		return epmodels.EmailChangeRevertPOSTResponse{
+			OK: &struct{ User epmodels.User }{User: *user},
+		}, nil
+	}
+
+	unlockAccountPOST := func(token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.UnlockAccountPOSTResponse, error) {
+		unlocked, err := unlockAccountUsingToken(options.Config.BruteForceProtection, token, getCurrTimeInMS(), userContext)
+		if err != nil {
+			return epmodels.UnlockAccountPOSTResponse{}, err
+		}
+		if !unlocked {
+			return epmodels.UnlockAccountPOSTResponse{
+				UnlockAccountInvalidTokenError: &struct{}{},
+			}, nil
+		}
+		return epmodels.UnlockAccountPOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
 	return epmodels.APIInterface{
 		EmailExistsGET:                 &emailExistsGET,
+		UsernameExistsGET:              &usernameExistsGET,
 		GeneratePasswordResetTokenPOST: &generatePasswordResetTokenPOST,
 		PasswordResetPOST:              &passwordResetPOST,
 		SignInPOST:                     &signInPOST,
 		SignUpPOST:                     &signUpPOST,
+		ChangePasswordPOST:             &changePasswordPOST,
+		EmailChangePOST:                &emailChangePOST,
+		EmailChangeVerifyPOST:          &emailChangeVerifyPOST,
+		EmailChangeRevertPOST:          &emailChangeRevertPOST,
+		UnlockAccountPOST:              &unlockAccountPOST,
 	}
 }
@@ -0,0 +1,89 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	defaultErrors "errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// storeSignUpFormFields stores the values of the sign up form fields that have a storage configured
+func storeSignUpFormFields(config epmodels.TypeNormalisedInputSignUp, userID string, session sessmodels.SessionContainer, formFields []epmodels.TypeFormField, userContext supertokens.UserContext) error {
+	valuesByStorage := map[string]map[string]interface{}{}
+	for _, field := range config.FormFields {
+		if field.Storage == "" {
+			continue
+		}
+		for _, formField := range formFields {
+			if formField.ID == field.ID {
+				if valuesByStorage[field.Storage] == nil {
+					valuesByStorage[field.Storage] = map[string]interface{}{}
+				}
+				valuesByStorage[field.Storage][field.ID] = getFormFieldValue(formField)
+				break
+			}
+		}
+	}
+
+	if metadataUpdate, ok := valuesByStorage[epmodels.FormFieldStorageUserMetadata]; ok {
+		err := config.UpdateUserMetadata(userID, metadataUpdate, userContext)
+		if err != nil {
+			return err
+		}
+	}
+
+	sessionDataUpdate, updateSessionData := valuesByStorage[epmodels.FormFieldStorageSessionData]
+	accessTokenPayloadUpdate, updateAccessTokenPayload := valuesByStorage[epmodels.FormFieldStorageAccessTokenPayload]
+	if (updateSessionData || updateAccessTokenPayload) && session.GetSessionDataWithContext == nil {
+		return defaultErrors.New("sign up form fields are stored in the session, but SignUpPOST did not return a session")
+	}
+
+	if updateSessionData {
+		sessionData, err := session.GetSessionDataWithContext(userContext)
+		if err != nil {
+			return err
+		}
+		newSessionData := map[string]interface{}{}
+		for key, value := range sessionData {
+			newSessionData[key] = value
+		}
+		for key, value := range sessionDataUpdate {
+			newSessionData[key] = value
+		}
+		err = session.UpdateSessionDataWithContext(newSessionData, userContext)
+		if err != nil {
+			return err
+		}
+	}
+
+	if updateAccessTokenPayload {
+		newAccessTokenPayload := map[string]interface{}{}
+		for key, value := range session.GetAccessTokenPayloadWithContext(userContext) {
+			newAccessTokenPayload[key] = value
+		}
+		for key, value := range accessTokenPayloadUpdate {
+			newAccessTokenPayload[key] = value
+		}
+		err := session.UpdateAccessTokenPayloadWithContext(newAccessTokenPayload, userContext)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
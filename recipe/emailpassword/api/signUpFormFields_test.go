@@ -0,0 +1,171 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func getSignUpFormFieldsConfigForTests() []epmodels.NormalisedFormField {
+	noValidation := func(value interface{}) *string {
+		return nil
+	}
+	return []epmodels.NormalisedFormField{
+		{ID: "email", Validate: noValidation},
+		{ID: "password", Validate: noValidation},
+		{ID: "name", Validate: noValidation, Storage: epmodels.FormFieldStorageUserMetadata, AcceptsJSONValues: true},
+		{ID: "marketingOptIn", Validate: func(value interface{}) *string {
+			if _, ok := value.(bool); !ok {
+				msg := "must be a boolean"
+				return &msg
+			}
+			return nil
+		}, Storage: epmodels.FormFieldStorageAccessTokenPayload, AcceptsJSONValues: true},
+		{ID: "interests", Validate: noValidation, Optional: true, Storage: epmodels.FormFieldStorageSessionData, AcceptsJSONValues: true},
+	}
+}
+
+func TestSignUpFormFieldsAcceptJSONValues(t *testing.T) {
+	formFields, err := validateFormFieldsOrThrowError(getSignUpFormFieldsConfigForTests(), []interface{}{
+		map[string]interface{}{"id": "email", "value": " johndoe@gmail.com "},
+		map[string]interface{}{"id": "password", "value": "validpass123"},
+		map[string]interface{}{"id": "name", "value": "John"},
+		map[string]interface{}{"id": "marketingOptIn", "value": false},
+		map[string]interface{}{"id": "interests", "value": []interface{}{"go", float64(42)}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []epmodels.TypeFormField{
+		{ID: "email", Value: "johndoe@gmail.com", RawValue: "johndoe@gmail.com"},
+		{ID: "password", Value: "validpass123", RawValue: "validpass123"},
+		{ID: "name", Value: "John", RawValue: "John"},
+		{ID: "marketingOptIn", RawValue: false},
+		{ID: "interests", RawValue: []interface{}{"go", float64(42)}},
+	}, formFields)
+}
+
+func TestSignUpFormFieldsValidateJSONValues(t *testing.T) {
+	_, err := validateFormFieldsOrThrowError(getSignUpFormFieldsConfigForTests(), []interface{}{
+		map[string]interface{}{"id": "email", "value": true},
+		map[string]interface{}{"id": "password", "value": "validpass123"},
+		map[string]interface{}{"id": "name", "value": nil},
+		map[string]interface{}{"id": "marketingOptIn", "value": "yes"},
+		map[string]interface{}{"id": "interests", "value": nil},
+	})
+	assert.Equal(t, errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{
+			{ID: "email", ErrorMsg: "Field must be a string"},
+			{ID: "name", ErrorMsg: "Field is not optional"},
+			{ID: "marketingOptIn", ErrorMsg: "must be a boolean"},
+		},
+	}, err)
+}
+
+func TestSignUpFormFieldsTreatNullAsEmpty(t *testing.T) {
+	formFields, err := validateFormFieldsOrThrowError(getSignUpFormFieldsConfigForTests(), []interface{}{
+		map[string]interface{}{"id": "email", "value": "johndoe@gmail.com"},
+		map[string]interface{}{"id": "password", "value": "validpass123"},
+		map[string]interface{}{"id": "name", "value": "John"},
+		map[string]interface{}{"id": "marketingOptIn", "value": true},
+		map[string]interface{}{"id": "interests", "value": nil},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, epmodels.TypeFormField{ID: "interests", Value: "", RawValue: ""}, formFields[4])
+}
+
+func TestSignUpFormFieldsOnlyAcceptJSONValuesIfEnabled(t *testing.T) {
+	noValidation := func(value interface{}) *string {
+		return nil
+	}
+	configFormFields := []epmodels.NormalisedFormField{
+		{ID: "email", Validate: noValidation},
+		{ID: "password", Validate: noValidation},
+		{ID: "company", Validate: noValidation},
+		{ID: "age", Validate: noValidation, AcceptsJSONValues: true},
+	}
+	_, err := validateFormFieldsOrThrowError(configFormFields, []interface{}{
+		map[string]interface{}{"id": "email", "value": "johndoe@gmail.com"},
+		map[string]interface{}{"id": "password", "value": "validpass123"},
+		map[string]interface{}{"id": "company", "value": map[string]interface{}{"name": "SuperTokens"}},
+		map[string]interface{}{"id": "age", "value": float64(30)},
+	})
+	assert.Equal(t, errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{
+			{ID: "company", ErrorMsg: "Field must be a string"},
+		},
+	}, err)
+}
+
+func TestStoreSignUpFormFields(t *testing.T) {
+	var metadataUserID string
+	var metadataUpdate map[string]interface{}
+	config := epmodels.TypeNormalisedInputSignUp{
+		FormFields: getSignUpFormFieldsConfigForTests(),
+		UpdateUserMetadata: func(userID string, update map[string]interface{}, userContext supertokens.UserContext) error {
+			metadataUserID = userID
+			metadataUpdate = update
+			return nil
+		},
+	}
+	sessionData := map[string]interface{}{"cart": "guest"}
+	accessTokenPayload := map[string]interface{}{"plan": "free"}
+	session := sessmodels.SessionContainer{
+		GetSessionDataWithContext: func(userContext supertokens.UserContext) (map[string]interface{}, error) {
+			return sessionData, nil
+		},
+		UpdateSessionDataWithContext: func(newSessionData map[string]interface{}, userContext supertokens.UserContext) error {
+			sessionData = newSessionData
+			return nil
+		},
+		GetAccessTokenPayloadWithContext: func(userContext supertokens.UserContext) map[string]interface{} {
+			return accessTokenPayload
+		},
+		UpdateAccessTokenPayloadWithContext: func(newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+			accessTokenPayload = newAccessTokenPayload
+			return nil
+		},
+	}
+
+	err := storeSignUpFormFields(config, "userId", session, []epmodels.TypeFormField{
+		{ID: "email", Value: "johndoe@gmail.com", RawValue: "johndoe@gmail.com"},
+		{ID: "password", Value: "validpass123", RawValue: "validpass123"},
+		{ID: "name", Value: "John", RawValue: "John"},
+		{ID: "marketingOptIn", RawValue: true},
+		{ID: "interests", RawValue: []interface{}{"go"}},
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", metadataUserID)
+	assert.Equal(t, map[string]interface{}{"name": "John"}, metadataUpdate)
+	assert.Equal(t, map[string]interface{}{"cart": "guest", "interests": []interface{}{"go"}}, sessionData)
+	assert.Equal(t, map[string]interface{}{"plan": "free", "marketingOptIn": true}, accessTokenPayload)
+}
+
+func TestStoreSignUpFormFieldsRequiresASession(t *testing.T) {
+	config := epmodels.TypeNormalisedInputSignUp{
+		FormFields: getSignUpFormFieldsConfigForTests(),
+	}
+	err := storeSignUpFormFields(config, "userId", sessmodels.SessionContainer{}, []epmodels.TypeFormField{
+		{ID: "marketingOptIn", RawValue: true},
+	}, &map[string]interface{}{})
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -44,7 +45,12 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return err
 	}
 
-	result, err := (*apiImplementation.SignInPOST)(formFields, options, &map[string]interface{}{})
+	userContext := &map[string]interface{}{}
+	if rememberMe, ok := formFieldsRaw["rememberMe"].(bool); ok {
+		session.SetRememberMe(userContext, rememberMe)
+	}
+
+	result, err := (*apiImplementation.SignInPOST)(formFields, options, userContext)
 	if err != nil {
 		return err
 	}
@@ -52,6 +58,10 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "WRONG_CREDENTIALS_ERROR",
 		})
+	} else if result.LockedAccountError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "LOCKED_ACCOUNT_ERROR",
+		})
 	} else {
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "OK",
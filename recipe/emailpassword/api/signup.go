@@ -21,6 +21,7 @@ import (
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -45,11 +46,61 @@ func SignUpAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 		return err
 	}
 
-	result, err := (*apiImplementation.SignUpPOST)(formFields, options, &map[string]interface{}{})
+	email := getEmailFromFormFields(formFields)
+
+	userContext := &map[string]interface{}{}
+	if rememberMe, ok := formFieldsRaw["rememberMe"].(bool); ok {
+		session.SetRememberMe(userContext, rememberMe)
+	}
+	if inviteToken, ok := formFieldsRaw["inviteToken"].(string); ok {
+		supertokens.SetInviteToken(userContext, inviteToken)
+	}
+	err = validateSignUpAllowedOrThrowError(email, userContext)
+	if err != nil {
+		return err
+	}
+
+	username := getUsernameFromFormFields(formFields)
+	err = validateUsernameOrThrowError(options.Config.UsernameFeature, username, userContext)
+	if err != nil {
+		return err
+	}
+
+	err = validateUsernameNotInPasswordOrThrowError(options.Config.PasswordPolicy, username, getPasswordFromFormFields(formFields))
+	if err != nil {
+		return err
+	}
+
+	// the email can only be empty if usernameFeature.EmailOptional is set
+	if email == "" {
+		formFields, err = setPlaceholderEmail(formFields)
+		if err != nil {
+			return err
+		}
+	}
+
+	invitation, err := consumeInvitationOrThrowError(email, userContext)
+	if err != nil {
+		return err
+	}
+	userCreated := false
+	options = withInvitationRedeemedOnSignUp(options, invitation, &userCreated)
+	result, err := (*apiImplementation.SignUpPOST)(formFields, withUsernameReservedOnSignUp(options, username), userContext)
+	if !userCreated {
+		// no user was created, so the invitation can still be used
+		restoreErr := supertokens.RestoreInvitation(invitation, supertokens.GetInviteToken(userContext), userContext)
+		if restoreErr != nil {
+			return restoreErr
+		}
+	}
 	if err != nil {
 		return err
 	}
 	if result.OK != nil {
+		err = storeSignUpFormFields(options.Config.SignUpFeature, result.OK.User.ID, result.OK.Session, formFields, userContext)
+		if err != nil {
+			return err
+		}
 		return supertokens.Send200Response(options.Res, map[string]interface{}{
 			"status": "OK",
 			"user":   result.OK.User,
@@ -0,0 +1,150 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// usernames shorter than this are too common to forbid in a password
+const minUsernameLengthForPasswordCheck = 3
+
+// validateUsernameOrThrowError checks that the username is not taken yet. Two users can still race
+// each other to the same username, which is why it is reserved before the user is created as well
+func validateUsernameOrThrowError(config epmodels.TypeNormalisedInputUsernameFeature, username string, userContext supertokens.UserContext) error {
+	if !config.Enabled {
+		return nil
+	}
+	userID, err := config.GetUserIDByUsername(username, userContext)
+	if err != nil {
+		return err
+	}
+	if userID == nil {
+		return nil
+	}
+	return usernameAlreadyExistsError()
+}
+
+func usernameAlreadyExistsError() error {
+	return errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:       "username",
+			ErrorMsg: "This username already exists. Please sign in instead.",
+		}},
+	}
+}
+
+// validateUsernameNotInPasswordOrThrowError applies DisallowEmailAndUsernameInPassword of the password
+// policy to the username. The rest of the policy is checked by the recipe functions, which do not know
+// the username of the user
+func validateUsernameNotInPasswordOrThrowError(policy epmodels.TypeNormalisedInputPasswordPolicy, username string, password string) error {
+	if !policy.Enabled || !policy.DisallowEmailAndUsernameInPassword || utf8.RuneCountInString(username) < minUsernameLengthForPasswordCheck {
+		return nil
+	}
+	if !strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return nil
+	}
+	return errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:       "password",
+			ErrorMsg: "Password must not contain your email or username",
+			Violations: []errors.PasswordPolicyViolation{{
+				Rule:    errors.PasswordPolicyContainsEmailOrUsername,
+				Message: "Password must not contain your email or username",
+			}},
+		}},
+	}
+}
+
+// setPlaceholderEmail gives users who sign up with only a username an email that cannot receive
+// anything, since the core needs an email for every user. The email field is added if the client
+// did not send it
+func setPlaceholderEmail(formFields []epmodels.TypeFormField) ([]epmodels.TypeFormField, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	placeholderEmail := hex.EncodeToString(b) + "@" + epmodels.UsernamePlaceholderEmailDomain
+	for i, formField := range formFields {
+		if formField.ID == "email" {
+			formFields[i].Value = placeholderEmail
+			formFields[i].RawValue = placeholderEmail
+			return formFields, nil
+		}
+	}
+	return append(formFields, epmodels.TypeFormField{
+		ID:       "email",
+		Value:    placeholderEmail,
+		RawValue: placeholderEmail,
+	}), nil
+}
+
+// withUsernameReservedOnSignUp reserves the username right before the user is created, so that
+// nobody can be created with a username that is taken. The reservation is released if the user is
+// not created, and otherwise bound to the new user before SignUpPOST creates their session
+func withUsernameReservedOnSignUp(options epmodels.APIOptions, username string) epmodels.APIOptions {
+	config := options.Config.UsernameFeature
+	if !config.Enabled {
+		return options
+	}
+	originalSignUp := *options.RecipeImplementation.SignUp
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		reserved, err := config.ReserveUsername(username, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		if !reserved {
+			return epmodels.SignUpResponse{}, usernameAlreadyExistsError()
+		}
+		response, err := originalSignUp(email, password, userContext)
+		if err != nil || response.OK == nil {
+			releaseErr := config.ReleaseUsername(username, userContext)
+			if releaseErr != nil {
+				return epmodels.SignUpResponse{}, releaseErr
+			}
+			return response, err
+		}
+		err = config.SetUsername(response.OK.User.ID, username, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		return response, nil
+	}
+	// the recipe implementation is copied, so that only this request reserves the username
+	recipeImplementation := options.RecipeImplementation
+	recipeImplementation.SignUp = &signUp
+	options.RecipeImplementation = recipeImplementation
+	return options
+}
+
+func getUsernameFromFormFields(formFields []epmodels.TypeFormField) string {
+	for _, formField := range formFields {
+		if formField.ID == "username" {
+			return formField.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestValidateUsernameIsNotTaken(t *testing.T) {
+	config := epmodels.TypeNormalisedInputUsernameFeature{
+		Enabled: true,
+		GetUserIDByUsername: func(username string, userContext supertokens.UserContext) (*string, error) {
+			if username != "player_one" {
+				return nil, nil
+			}
+			userID := "user1"
+			return &userID, nil
+		},
+	}
+	userContext := &map[string]interface{}{}
+
+	assert.NoError(t, validateUsernameOrThrowError(config, "player_two", userContext))
+	err := validateUsernameOrThrowError(config, "player_one", userContext)
+	assert.Equal(t, "username", err.(errors.FieldError).Payload[0].ID)
+	assert.NoError(t, validateUsernameOrThrowError(epmodels.TypeNormalisedInputUsernameFeature{}, "player_one", userContext))
+}
+
+func TestUsernameIsReservedBeforeTheUserIsCreated(t *testing.T) {
+	usernames := map[string]string{}
+	config := epmodels.TypeNormalisedInputUsernameFeature{
+		Enabled: true,
+		ReserveUsername: func(username string, userContext supertokens.UserContext) (bool, error) {
+			if _, ok := usernames[username]; ok {
+				return false, nil
+			}
+			usernames[username] = ""
+			return true, nil
+		},
+		SetUsername: func(userID string, username string, userContext supertokens.UserContext) error {
+			usernames[username] = userID
+			return nil
+		},
+		ReleaseUsername: func(username string, userContext supertokens.UserContext) error {
+			delete(usernames, username)
+			return nil
+		},
+	}
+	signUpCalls := 0
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		signUpCalls++
+		if email == "taken@example.com" {
+			return epmodels.SignUpResponse{EmailAlreadyExistsError: &struct{}{}}, nil
+		}
+		return epmodels.SignUpResponse{
+			OK: &struct{ User epmodels.User }{User: epmodels.User{ID: "user1", Email: email}},
+		}, nil
+	}
+	options := epmodels.APIOptions{
+		Config:               epmodels.TypeNormalisedInput{UsernameFeature: config},
+		RecipeImplementation: epmodels.RecipeInterface{SignUp: &signUp},
+	}
+	userContext := &map[string]interface{}{}
+
+	response, err := (*withUsernameReservedOnSignUp(options, "player_one").RecipeImplementation.SignUp)("taken@example.com", "validpass123", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.EmailAlreadyExistsError)
+	assert.Empty(t, usernames)
+
+	response, err = (*withUsernameReservedOnSignUp(options, "player_one").RecipeImplementation.SignUp)("test@example.com", "validpass123", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+	assert.Equal(t, map[string]string{"player_one": "user1"}, usernames)
+
+	_, err = (*withUsernameReservedOnSignUp(options, "player_one").RecipeImplementation.SignUp)("other@example.com", "validpass123", userContext)
+	assert.Equal(t, "username", err.(errors.FieldError).Payload[0].ID)
+	assert.Equal(t, 2, signUpCalls)
+}
+
+func TestValidateUsernameNotInPassword(t *testing.T) {
+	policy := epmodels.TypeNormalisedInputPasswordPolicy{
+		Enabled:                            true,
+		DisallowEmailAndUsernameInPassword: true,
+	}
+
+	err := validateUsernameNotInPasswordOrThrowError(policy, "player_one", "xxPLAYER_ONE1")
+	fieldError := err.(errors.FieldError)
+	assert.Equal(t, "password", fieldError.Payload[0].ID)
+	assert.Equal(t, errors.PasswordPolicyContainsEmailOrUsername, fieldError.Payload[0].Violations[0].Rule)
+	assert.NoError(t, validateUsernameNotInPasswordOrThrowError(policy, "player_one", "validpass123"))
+	assert.NoError(t, validateUsernameNotInPasswordOrThrowError(policy, "", "validpass123"))
+	assert.NoError(t, validateUsernameNotInPasswordOrThrowError(policy, "ab", "ab12345678"))
+	assert.NoError(t, validateUsernameNotInPasswordOrThrowError(epmodels.TypeNormalisedInputPasswordPolicy{}, "player_one", "xxplayer_one1"))
+}
+
+func TestSetPlaceholderEmail(t *testing.T) {
+	formFields, err := setPlaceholderEmail([]epmodels.TypeFormField{
+		{ID: "email", Value: "", RawValue: ""},
+		{ID: "username", Value: "player_one", RawValue: "player_one"},
+	})
+	assert.NoError(t, err)
+	email := getEmailFromFormFields(formFields)
+	assert.True(t, strings.HasSuffix(email, "@"+epmodels.UsernamePlaceholderEmailDomain))
+	assert.Equal(t, email, formFields[0].RawValue)
+	assert.Equal(t, "player_one", getUsernameFromFormFields(formFields))
+
+	// clients can leave the email out altogether
+	formFields, err = setPlaceholderEmail([]epmodels.TypeFormField{
+		{ID: "username", Value: "player_one", RawValue: "player_one"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, formFields, 2)
+	email = getEmailFromFormFields(formFields)
+	assert.True(t, strings.HasSuffix(email, "@"+epmodels.UsernamePlaceholderEmailDomain))
+	assert.Equal(t, email, formFields[1].RawValue)
+}
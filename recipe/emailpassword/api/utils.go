@@ -22,6 +22,7 @@ import (
 
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 func validateFormFieldsOrThrowError(configFormFields []epmodels.NormalisedFormField, formFieldsRaw []interface{}) ([]epmodels.TypeFormField, error) {
@@ -39,21 +40,33 @@ func validateFormFieldsOrThrowError(configFormFields []epmodels.NormalisedFormFi
 		if err != nil {
 			return nil, err
 		}
-		var formField epmodels.TypeFormField
+		var formField struct {
+			ID    string      `json:"id"`
+			Value interface{} `json:"value"`
+		}
 		err = json.Unmarshal(jsonformField, &formField)
 		if err != nil {
 			return nil, err
 		}
 
-		if formField.ID == "email" {
+		if formField.Value == nil {
+			// null is treated like a field that was left empty
+			formField.Value = ""
+		}
+		value, isString := formField.Value.(string)
+		if isString && formField.ID == "email" {
+			value = strings.TrimSpace(value)
+		}
+		if isString {
 			formFields = append(formFields, epmodels.TypeFormField{
-				ID:    formField.ID,
-				Value: strings.TrimSpace(formField.Value),
+				ID:       formField.ID,
+				Value:    value,
+				RawValue: value,
 			})
 		} else {
 			formFields = append(formFields, epmodels.TypeFormField{
-				ID:    formField.ID,
-				Value: formField.Value,
+				ID:       formField.ID,
+				RawValue: formField.Value,
 			})
 		}
 	}
@@ -74,10 +87,14 @@ func validateFormOrThrowError(configFormFields []epmodels.NormalisedFormField, i
 				break
 			}
 		}
-		if input.Value == "" && !field.Optional {
+		value := getFormFieldValue(input)
+		_, isString := value.(string)
+		if value == "" && !field.Optional {
 			validationErrors = append(validationErrors, errors.ErrorPayload{ID: field.ID, ErrorMsg: "Field is not optional"})
+		} else if !isString && !field.AcceptsJSONValues {
+			validationErrors = append(validationErrors, errors.ErrorPayload{ID: field.ID, ErrorMsg: "Field must be a string"})
 		} else {
-			err := field.Validate(input.Value)
+			err := field.Validate(value)
 			if err != nil {
 				validationErrors = append(validationErrors, errors.ErrorPayload{
 					ID:       field.ID,
@@ -94,3 +111,103 @@ func validateFormOrThrowError(configFormFields []epmodels.NormalisedFormField, i
 	}
 	return nil
 }
+
+// getFormFieldValue returns the value of the form field as it was sent, falling back to Value
+// for form fields that are not from a request
+func getFormFieldValue(formField epmodels.TypeFormField) interface{} {
+	if formField.RawValue != nil {
+		return formField.RawValue
+	}
+	return formField.Value
+}
+
+func validateEmailPolicyOrThrowError(policy supertokens.NormalisedEmailPolicy, email string) error {
+	msg := supertokens.ValidateEmailDomain(policy, email)
+	if msg == nil {
+		return nil
+	}
+	return errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:       "email",
+			ErrorMsg: *msg,
+		}},
+	}
+}
+
+func validateSignUpAllowedOrThrowError(email string, userContext supertokens.UserContext) error {
+	msg, err := supertokens.CheckSignUpAllowed(&email, nil, supertokens.GetInviteToken(userContext), userContext)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return nil
+	}
+	return errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:       "email",
+			ErrorMsg: *msg,
+		}},
+	}
+}
+
+// consumeInvitationOrThrowError is called right before the user is created, so that the invitation cannot
+// be used by a concurrent sign up
+func consumeInvitationOrThrowError(email string, userContext supertokens.UserContext) (*supertokens.Invitation, error) {
+	invitation, msg, err := supertokens.ConsumeInvitation(&email, nil, supertokens.GetInviteToken(userContext), userContext)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return invitation, nil
+	}
+	return nil, errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:       "email",
+			ErrorMsg: *msg,
+		}},
+	}
+}
+
+// withInvitationRedeemedOnSignUp redeems the invitation as soon as the user is created, before anything
+// else is done for the new user. userCreated is set then, so that the invitation is restored otherwise
+func withInvitationRedeemedOnSignUp(options epmodels.APIOptions, invitation *supertokens.Invitation, userCreated *bool) epmodels.APIOptions {
+	originalSignUp := *options.RecipeImplementation.SignUp
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		response, err := originalSignUp(email, password, userContext)
+		if err != nil || response.OK == nil {
+			return response, err
+		}
+		*userCreated = true
+		err = supertokens.RedeemInvitation(response.OK.User.ID, invitation, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		return response, nil
+	}
+	// the recipe implementation is copied, so that only this request redeems the invitation
+	recipeImplementation := options.RecipeImplementation
+	recipeImplementation.SignUp = &signUp
+	options.RecipeImplementation = recipeImplementation
+	return options
+}
+
+func getEmailFromFormFields(formFields []epmodels.TypeFormField) string {
+	for _, formField := range formFields {
+		if formField.ID == "email" {
+			return formField.Value
+		}
+	}
+	return ""
+}
+
+func getPasswordFromFormFields(formFields []epmodels.TypeFormField) string {
+	for _, formField := range formFields {
+		if formField.ID == "password" {
+			return formField.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/api"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	defaultMaxFailedAttemptsPerAccount = 5
+	defaultMaxFailedAttemptsPerIP      = 50
+	defaultFailedAttemptsWindowMS      = uint64(15 * 60 * 1000)
+	defaultLockoutDurationMS           = uint64(15 * 60 * 1000)
+	defaultInitialSignInDelayMS        = uint64(250)
+	defaultMaxSignInDelayMS            = uint64(5000)
+)
+
+func validateAndNormaliseBruteForceProtectionConfig(appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInputBruteForceProtection) (epmodels.TypeNormalisedInputBruteForceProtection, error) {
+	bruteForceProtection := epmodels.TypeNormalisedInputBruteForceProtection{
+		Enabled:                     true,
+		MaxFailedAttemptsPerAccount: defaultMaxFailedAttemptsPerAccount,
+		MaxFailedAttemptsPerIP:      defaultMaxFailedAttemptsPerIP,
+		FailedAttemptsWindowMS:      defaultFailedAttemptsWindowMS,
+		LockoutDurationMS:           defaultLockoutDurationMS,
+		InitialDelayMS:              defaultInitialSignInDelayMS,
+		MaxDelayMS:                  defaultMaxSignInDelayMS,
+		GetIPAddress:                config.GetIPAddress,
+		CreateAndSendCustomEmail:    config.CreateAndSendCustomEmail,
+		GetUnlockAccountURL:         defaultGetUnlockAccountURL(appInfo),
+	}
+	if config.MaxFailedAttemptsPerAccount != nil {
+		if *config.MaxFailedAttemptsPerAccount <= 0 {
+			return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("bruteForceProtection.MaxFailedAttemptsPerAccount must be greater than 0")
+		}
+		bruteForceProtection.MaxFailedAttemptsPerAccount = *config.MaxFailedAttemptsPerAccount
+	}
+	if config.MaxFailedAttemptsPerIP != nil {
+		if *config.MaxFailedAttemptsPerIP <= 0 {
+			return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("bruteForceProtection.MaxFailedAttemptsPerIP must be greater than 0")
+		}
+		bruteForceProtection.MaxFailedAttemptsPerIP = *config.MaxFailedAttemptsPerIP
+	}
+	if config.FailedAttemptsWindowMS != nil {
+		if *config.FailedAttemptsWindowMS == 0 {
+			return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("bruteForceProtection.FailedAttemptsWindowMS must be greater than 0")
+		}
+		bruteForceProtection.FailedAttemptsWindowMS = *config.FailedAttemptsWindowMS
+	}
+	if config.LockoutDurationMS != nil {
+		if *config.LockoutDurationMS == 0 {
+			return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("bruteForceProtection.LockoutDurationMS must be greater than 0")
+		}
+		bruteForceProtection.LockoutDurationMS = *config.LockoutDurationMS
+	}
+	if config.InitialDelayMS != nil {
+		bruteForceProtection.InitialDelayMS = *config.InitialDelayMS
+	}
+	if config.MaxDelayMS != nil {
+		bruteForceProtection.MaxDelayMS = *config.MaxDelayMS
+	}
+	if bruteForceProtection.MaxDelayMS < bruteForceProtection.InitialDelayMS {
+		return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("bruteForceProtection.MaxDelayMS must not be less than InitialDelayMS")
+	}
+	if config.GetUnlockAccountURL != nil {
+		bruteForceProtection.GetUnlockAccountURL = config.GetUnlockAccountURL
+	}
+	if config.Store != nil {
+		store := config.Store
+		if store.IncrementFailedAttempts == nil || store.ResetFailedAttempts == nil || store.GetLock == nil || store.SetLock == nil || store.RemoveLock == nil {
+			return epmodels.TypeNormalisedInputBruteForceProtection{}, errors.New("please provide IncrementFailedAttempts, ResetFailedAttempts, GetLock, SetLock and RemoveLock in bruteForceProtection.Store")
+		}
+		bruteForceProtection.Store = *store
+	} else {
+		bruteForceProtection.Store = newInMemoryBruteForceProtectionStore(getCurrTimeInMS).toStore()
+	}
+	return bruteForceProtection, nil
+}
+
+func defaultGetUnlockAccountURL(appInfo supertokens.NormalisedAppinfo) func(_ epmodels.User, userContext supertokens.UserContext) (string, error) {
+	return func(_ epmodels.User, userContext supertokens.UserContext) (string, error) {
+		return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/unlock-account", nil
+	}
+}
+
+type inMemoryFailedAttempts struct {
+	count     int
+	expiresAt uint64
+}
+
+// inMemoryBruteForceProtectionStore is the default store. Failed sign ins are forgotten once their window
+// has passed, and locks once they expire. Both are only checked for the key that is being used, so that
+// every call does not have to go through all keys.
+type inMemoryBruteForceProtectionStore struct {
+	lock           sync.Mutex
+	failedAttempts map[string]inMemoryFailedAttempts
+	locks          map[string]epmodels.SignInLock
+	now            func() uint64
+}
+
+func newInMemoryBruteForceProtectionStore(now func() uint64) *inMemoryBruteForceProtectionStore {
+	return &inMemoryBruteForceProtectionStore{
+		failedAttempts: map[string]inMemoryFailedAttempts{},
+		locks:          map[string]epmodels.SignInLock{},
+		now:            now,
+	}
+}
+
+func (s *inMemoryBruteForceProtectionStore) toStore() epmodels.BruteForceProtectionStore {
+	return epmodels.BruteForceProtectionStore{
+		IncrementFailedAttempts: s.incrementFailedAttempts,
+		ResetFailedAttempts:     s.resetFailedAttempts,
+		GetLock:                 s.getLock,
+		SetLock:                 s.setLock,
+		RemoveLock:              s.removeLock,
+	}
+}
+
+func (s *inMemoryBruteForceProtectionStore) incrementFailedAttempts(key string, windowMS uint64, userContext supertokens.UserContext) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	failedAttempts, ok := s.failedAttempts[key]
+	if !ok || failedAttempts.expiresAt <= now {
+		failedAttempts = inMemoryFailedAttempts{expiresAt: now + windowMS}
+	}
+	failedAttempts.count++
+	s.failedAttempts[key] = failedAttempts
+	return failedAttempts.count, nil
+}
+
+func (s *inMemoryBruteForceProtectionStore) resetFailedAttempts(key string, userContext supertokens.UserContext) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.failedAttempts, key)
+	return nil
+}
+
+func (s *inMemoryBruteForceProtectionStore) getLock(key string, userContext supertokens.UserContext) (*epmodels.SignInLock, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	lock, ok := s.locks[key]
+	if !ok {
+		return nil, nil
+	}
+	if lock.LockedUntil <= s.now() {
+		delete(s.locks, key)
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+func (s *inMemoryBruteForceProtectionStore) setLock(key string, lock epmodels.SignInLock, userContext supertokens.UserContext) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.locks[key] = lock
+	return nil
+}
+
+func (s *inMemoryBruteForceProtectionStore) removeLock(key string, userContext supertokens.UserContext) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.locks, key)
+	return nil
+}
+
+func (r *Recipe) unlockAccount(userID string, userContext supertokens.UserContext) error {
+	if !r.Config.BruteForceProtection.Enabled {
+		return errors.New("please configure bruteForceProtection in the emailpassword recipe to unlock accounts")
+	}
+	return api.UnlockAccount(r.Config.BruteForceProtection, userID, userContext)
+}
+
+func getCurrTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / 1000000)
+}
@@ -0,0 +1,196 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestBruteForceProtectionConfigDefaults(t *testing.T) {
+	appInfo := supertokens.NormalisedAppinfo{}
+	config, err := validateAndNormaliseBruteForceProtectionConfig(appInfo, &epmodels.TypeInputBruteForceProtection{})
+	assert.NoError(t, err)
+	assert.True(t, config.Enabled)
+	assert.Equal(t, 5, config.MaxFailedAttemptsPerAccount)
+	assert.Equal(t, 50, config.MaxFailedAttemptsPerIP)
+	assert.Equal(t, uint64(15*60*1000), config.LockoutDurationMS)
+	assert.NotNil(t, config.Store.IncrementFailedAttempts)
+	// failed sign ins are only counted per IP address if the app provides it
+	assert.Nil(t, config.GetIPAddress)
+}
+
+func TestBruteForceProtectionConfigValidation(t *testing.T) {
+	appInfo := supertokens.NormalisedAppinfo{}
+	zero := 0
+	_, err := validateAndNormaliseBruteForceProtectionConfig(appInfo, &epmodels.TypeInputBruteForceProtection{MaxFailedAttemptsPerAccount: &zero})
+	assert.Error(t, err)
+
+	initialDelay := uint64(1000)
+	maxDelay := uint64(500)
+	_, err = validateAndNormaliseBruteForceProtectionConfig(appInfo, &epmodels.TypeInputBruteForceProtection{InitialDelayMS: &initialDelay, MaxDelayMS: &maxDelay})
+	assert.Error(t, err)
+
+	_, err = validateAndNormaliseBruteForceProtectionConfig(appInfo, &epmodels.TypeInputBruteForceProtection{Store: &epmodels.BruteForceProtectionStore{}})
+	assert.Error(t, err)
+}
+
+func TestInMemoryBruteForceProtectionStore(t *testing.T) {
+	now := uint64(1000)
+	store := newInMemoryBruteForceProtectionStore(func() uint64 { return now }).toStore()
+	userContext := &map[string]interface{}{}
+
+	count, err := store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, _ = store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.Equal(t, 2, count)
+
+	// the window starts at the first failed attempt
+	now = 1100
+	count, _ = store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.Equal(t, 1, count)
+	assert.NoError(t, store.ResetFailedAttempts("user:1", userContext))
+	count, _ = store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, store.SetLock("ip:203.0.113.7", epmodels.SignInLock{LockedUntil: 1200}, userContext))
+	lock, err := store.GetLock("ip:203.0.113.7", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1200), lock.LockedUntil)
+
+	now = 1200
+	lock, err = store.GetLock("ip:203.0.113.7", userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+}
+
+func TestInMemoryBruteForceProtectionStoreOnlyExpiresTheKeyInUse(t *testing.T) {
+	now := uint64(1000)
+	inMemoryStore := newInMemoryBruteForceProtectionStore(func() uint64 { return now })
+	store := inMemoryStore.toStore()
+	userContext := &map[string]interface{}{}
+
+	_, err := store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.NoError(t, err)
+	_, err = store.IncrementFailedAttempts("user:2", 100, userContext)
+	assert.NoError(t, err)
+	assert.NoError(t, store.SetLock("user:1", epmodels.SignInLock{LockedUntil: 1100}, userContext))
+	assert.NoError(t, store.SetLock("user:2", epmodels.SignInLock{LockedUntil: 1100}, userContext))
+
+	now = 1100
+	count, err := store.IncrementFailedAttempts("user:1", 100, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	lock, err := store.GetLock("user:1", userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, lock)
+
+	assert.Len(t, inMemoryStore.locks, 1)
+	assert.Equal(t, 1, inMemoryStore.failedAttempts["user:2"].count)
+}
+
+func TestChangePasswordAndReauthenticationCountTowardsTheAccountLock(t *testing.T) {
+	customAntiCsrfVal := "VIA_TOKEN"
+	maxFailedAttempts := 2
+	noDelay := uint64(0)
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				BruteForceProtection: &epmodels.TypeInputBruteForceProtection{
+					MaxFailedAttemptsPerAccount: &maxFailedAttempts,
+					InitialDelayMS:              &noDelay,
+					MaxDelayMS:                  &noDelay,
+				},
+			}),
+			session.Init(&sessmodels.TypeInput{
+				AntiCsrf: &customAntiCsrfVal,
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	resp, err := unittesting.SignupRequest("test@gmail.com", "testPass123", testServer.URL)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var response map[string]interface{}
+	_ = json.Unmarshal(data, &response)
+	assert.Equal(t, "OK", response["status"])
+	userId := response["user"].(map[string]interface{})["id"].(string)
+	cookieData := unittesting.ExtractInfoFromResponse(resp)
+
+	for i := 0; i < maxFailedAttempts; i++ {
+		response, err = changePasswordRequest(testServer.URL, "wrongPass123", "newPass123", cookieData)
+		assert.NoError(t, err)
+		assert.Equal(t, "WRONG_CREDENTIALS_ERROR", response["status"])
+	}
+	// the password is not checked while the account is locked, even if it is right
+	response, err = changePasswordRequest(testServer.URL, "testPass123", "newPass123", cookieData)
+	assert.NoError(t, err)
+	assert.Equal(t, "LOCKED_ACCOUNT_ERROR", response["status"])
+
+	verifier := VerifyPasswordForReauthentication()
+	verified, err := verifier(userId, map[string]interface{}{"password": "testPass123"}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, verified)
+
+	assert.NoError(t, UnlockAccount(userId))
+	verified, err = verifier(userId, map[string]interface{}{"password": "testPass123"}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	for i := 0; i < maxFailedAttempts; i++ {
+		verified, err = verifier(userId, map[string]interface{}{"password": "wrongPass123"}, &map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.False(t, verified)
+	}
+	// failed reauthentications lock sign ins as well
+	resp, err = unittesting.SignInRequest("test@gmail.com", "testPass123", testServer.URL)
+	assert.NoError(t, err)
+	data, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	response = map[string]interface{}{}
+	_ = json.Unmarshal(data, &response)
+	assert.Equal(t, "LOCKED_ACCOUNT_ERROR", response["status"])
+}
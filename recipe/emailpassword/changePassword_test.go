@@ -0,0 +1,143 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func changePasswordRequest(testUrl string, oldPassword string, newPassword string, cookieData map[string]string) (map[string]interface{}, error) {
+	postBody, err := json.Marshal(map[string]interface{}{
+		"oldPassword": oldPassword,
+		"formFields": []map[string]string{
+			{
+				"id":    "password",
+				"value": newPassword,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, testUrl+"/auth/user/password/change", bytes.NewBuffer(postBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+	req.Header.Add("anti-csrf", cookieData["antiCsrf"])
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]interface{}
+	err = json.Unmarshal(data, &response)
+	return response, err
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	customAntiCsrfVal := "VIA_TOKEN"
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				ChangePasswordFeature: &epmodels.TypeInputChangePasswordFeature{
+					RevokeOtherSessions: true,
+				},
+			}),
+			session.Init(&sessmodels.TypeInput{
+				AntiCsrf: &customAntiCsrfVal,
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	resp, err := unittesting.SignupRequest("test@gmail.com", "testPass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var response map[string]interface{}
+	_ = json.Unmarshal(data, &response)
+	assert.Equal(t, "OK", response["status"])
+	userId := response["user"].(map[string]interface{})["id"].(string)
+	cookieData := unittesting.ExtractInfoFromResponse(resp)
+
+	otherSessionResp, err := unittesting.SignInRequest("test@gmail.com", "testPass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, otherSessionResp.StatusCode)
+	otherSessionResp.Body.Close()
+	sessionHandles, err := session.GetAllSessionHandlesForUser(userId)
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 2)
+
+	response, err = changePasswordRequest(testServer.URL, "wrongPass123", "newPass123", cookieData)
+	assert.NoError(t, err)
+	assert.Equal(t, "WRONG_CREDENTIALS_ERROR", response["status"])
+
+	response, err = changePasswordRequest(testServer.URL, "testPass123", "short", cookieData)
+	assert.NoError(t, err)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+
+	response, err = changePasswordRequest(testServer.URL, "testPass123", "newPass123", cookieData)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+
+	signInResponse, err := SignIn("test@gmail.com", "testPass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+	signInResponse, err = SignIn("test@gmail.com", "newPass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+
+	sessionHandles, err = session.GetAllSessionHandlesForUser(userId)
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 1)
+}
@@ -21,4 +21,10 @@ const (
 	GeneratePasswordResetTokenAPI = "/user/password/reset/token"
 	PasswordResetAPI              = "/user/password/reset"
 	SignupEmailExistsAPI          = "/signup/email/exists"
+	SignupUsernameExistsAPI       = "/signup/username/exists"
+	ChangePasswordAPI             = "/user/password/change"
+	EmailChangeAPI                = "/user/email/change"
+	EmailChangeVerifyAPI          = "/user/email/change/verify"
+	EmailChangeRevertAPI          = "/user/email/change/revert"
+	UnlockAccountAPI              = "/user/unlock"
 )
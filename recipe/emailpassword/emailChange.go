@@ -0,0 +1,81 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+const (
+	minEmailChangeSigningKeyLength        = 32
+	defaultEmailChangeTokenValidity       = uint64(24 * 60 * 60 * 1000)
+	defaultEmailChangeRevertTokenValidity = uint64(7 * 24 * 60 * 60 * 1000)
+)
+
+func validateAndNormaliseEmailChangeConfig(appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInputEmailChangeFeature) (epmodels.TypeNormalisedInputEmailChangeFeature, error) {
+	if len(config.SigningKey) < minEmailChangeSigningKeyLength {
+		return epmodels.TypeNormalisedInputEmailChangeFeature{}, errors.New("emailChangeFeature.SigningKey must be at least 32 bytes long")
+	}
+	if config.CreateAndSendCustomEmail == nil || config.CreateAndSendCustomRevertEmail == nil {
+		return epmodels.TypeNormalisedInputEmailChangeFeature{}, errors.New("please provide CreateAndSendCustomEmail and CreateAndSendCustomRevertEmail in emailChangeFeature")
+	}
+
+	emailChangeFeature := epmodels.TypeNormalisedInputEmailChangeFeature{
+		Enabled:                        true,
+		SigningKey:                     config.SigningKey,
+		GetEmailChangeURL:              defaultGetEmailChangeURL(appInfo),
+		CreateAndSendCustomEmail:       config.CreateAndSendCustomEmail,
+		GetEmailChangeRevertURL:        defaultGetEmailChangeRevertURL(appInfo),
+		CreateAndSendCustomRevertEmail: config.CreateAndSendCustomRevertEmail,
+		TokenValidityMS:                defaultEmailChangeTokenValidity,
+		RevertTokenValidityMS:          defaultEmailChangeRevertTokenValidity,
+		RevokeSessionsOnEmailChange:    config.RevokeSessionsOnEmailChange,
+	}
+	if config.GetEmailChangeURL != nil {
+		emailChangeFeature.GetEmailChangeURL = config.GetEmailChangeURL
+	}
+	if config.GetEmailChangeRevertURL != nil {
+		emailChangeFeature.GetEmailChangeRevertURL = config.GetEmailChangeRevertURL
+	}
+	if config.TokenValidityMS != nil {
+		if *config.TokenValidityMS == 0 {
+			return epmodels.TypeNormalisedInputEmailChangeFeature{}, errors.New("emailChangeFeature.TokenValidityMS must be greater than 0")
+		}
+		emailChangeFeature.TokenValidityMS = *config.TokenValidityMS
+	}
+	if config.RevertTokenValidityMS != nil {
+		if *config.RevertTokenValidityMS == 0 {
+			return epmodels.TypeNormalisedInputEmailChangeFeature{}, errors.New("emailChangeFeature.RevertTokenValidityMS must be greater than 0")
+		}
+		emailChangeFeature.RevertTokenValidityMS = *config.RevertTokenValidityMS
+	}
+	return emailChangeFeature, nil
+}
+
+func defaultGetEmailChangeURL(appInfo supertokens.NormalisedAppinfo) func(_ epmodels.User, _ string, userContext supertokens.UserContext) (string, error) {
+	return func(_ epmodels.User, _ string, userContext supertokens.UserContext) (string, error) {
+		return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/verify-email-change", nil
+	}
+}
+
+func defaultGetEmailChangeRevertURL(appInfo supertokens.NormalisedAppinfo) func(_ epmodels.User, userContext supertokens.UserContext) (string, error) {
+	return func(_ epmodels.User, userContext supertokens.UserContext) (string, error) {
+		return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/revert-email-change", nil
+	}
+}
@@ -0,0 +1,188 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func postJSONRequest(testUrl string, path string, body map[string]interface{}, cookieData map[string]string) (map[string]interface{}, error) {
+	postBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, testUrl+path, bytes.NewBuffer(postBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cookieData != nil {
+		req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+		req.Header.Add("anti-csrf", cookieData["antiCsrf"])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]interface{}
+	err = json.Unmarshal(data, &response)
+	return response, err
+}
+
+func TestEmailChangeConfig(t *testing.T) {
+	websiteDomain, err := supertokens.NewNormalisedURLDomain("https://example.com")
+	assert.NoError(t, err)
+	websiteBasePath, err := supertokens.NewNormalisedURLPath("/auth")
+	assert.NoError(t, err)
+	appInfo := supertokens.NormalisedAppinfo{WebsiteDomain: websiteDomain, WebsiteBasePath: websiteBasePath}
+	sendEmail := func(user epmodels.User, newEmail string, link string, userContext supertokens.UserContext) {}
+
+	_, err = validateAndNormaliseEmailChangeConfig(appInfo, &epmodels.TypeInputEmailChangeFeature{
+		SigningKey:                     []byte("too short"),
+		CreateAndSendCustomEmail:       sendEmail,
+		CreateAndSendCustomRevertEmail: sendEmail,
+	})
+	assert.Error(t, err)
+
+	_, err = validateAndNormaliseEmailChangeConfig(appInfo, &epmodels.TypeInputEmailChangeFeature{
+		SigningKey:               []byte("0123456789abcdef0123456789abcdef"),
+		CreateAndSendCustomEmail: sendEmail,
+	})
+	assert.Error(t, err)
+
+	config, err := validateAndNormaliseEmailChangeConfig(appInfo, &epmodels.TypeInputEmailChangeFeature{
+		SigningKey:                     []byte("0123456789abcdef0123456789abcdef"),
+		CreateAndSendCustomEmail:       sendEmail,
+		CreateAndSendCustomRevertEmail: sendEmail,
+	})
+	assert.NoError(t, err)
+	assert.True(t, config.Enabled)
+	assert.Equal(t, uint64(24*60*60*1000), config.TokenValidityMS)
+	assert.Equal(t, uint64(7*24*60*60*1000), config.RevertTokenValidityMS)
+	assert.False(t, config.RevokeSessionsOnEmailChange)
+
+	url, err := config.GetEmailChangeURL(epmodels.User{}, "new@example.com", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/auth/verify-email-change", url)
+	url, err = config.GetEmailChangeRevertURL(epmodels.User{}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/auth/revert-email-change", url)
+}
+
+func TestEmailChangeTokenCannotVerifyEmail(t *testing.T) {
+	customAntiCsrfVal := "VIA_TOKEN"
+	emailChangeLink := ""
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				EmailChangeFeature: &epmodels.TypeInputEmailChangeFeature{
+					SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+					CreateAndSendCustomEmail: func(user epmodels.User, newEmail string, emailChangeURLWithToken string, userContext supertokens.UserContext) {
+						emailChangeLink = emailChangeURLWithToken
+					},
+					CreateAndSendCustomRevertEmail: func(user epmodels.User, newEmail string, emailChangeRevertURLWithToken string, userContext supertokens.UserContext) {
+					},
+				},
+			}),
+			session.Init(&sessmodels.TypeInput{
+				AntiCsrf: &customAntiCsrfVal,
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	resp, err := unittesting.SignupRequest("test@gmail.com", "testPass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	cookieData := unittesting.ExtractInfoFromResponse(resp)
+	resp.Body.Close()
+
+	response, err := postJSONRequest(testServer.URL, "/auth/user/email/change", map[string]interface{}{
+		"formFields": []map[string]string{{"id": "email", "value": "new@gmail.com"}},
+	}, cookieData)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+	assert.NotEmpty(t, emailChangeLink)
+	parsedLink, err := url.Parse(emailChangeLink)
+	assert.NoError(t, err)
+	token := parsedLink.Query().Get("token")
+
+	// the email verification API must not accept the link, as that would
+	// verify the new address without changing the email
+	response, err = postJSONRequest(testServer.URL, "/auth/user/email/verify", map[string]interface{}{
+		"method": "token",
+		"token":  token,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "EMAIL_VERIFICATION_INVALID_TOKEN_ERROR", response["status"])
+
+	// nor the revert API, which would otherwise swap the emails around
+	response, err = postJSONRequest(testServer.URL, "/auth/user/email/change/revert", map[string]interface{}{
+		"token": token,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "EMAIL_CHANGE_INVALID_TOKEN_ERROR", response["status"])
+
+	response, err = postJSONRequest(testServer.URL, "/auth/user/email/change/verify", map[string]interface{}{
+		"token": token,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+	userID := response["user"].(map[string]interface{})["id"].(string)
+
+	user, err := GetUserByID(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "new@gmail.com", user.Email)
+	isVerified, err := IsEmailVerified(userID)
+	assert.NoError(t, err)
+	assert.True(t, isVerified)
+}
@@ -0,0 +1,126 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// MakeEmailPolicyRecipeImplementation normalises the emails before they reach the core, so that
+// the same user cannot sign up twice with different forms of their email, and stops sign ups with
+// an email domain that the policy does not allow. Users are looked up by the canonical form of their
+// email, falling back to the email as it was entered so that users who signed up before the policy
+// was enabled are still found. It is exported for the recipes that are built on top of emailpassword
+func MakeEmailPolicyRecipeImplementation(originalImplementation epmodels.RecipeInterface, policy supertokens.NormalisedEmailPolicy) epmodels.RecipeInterface {
+	originalSignUp := *originalImplementation.SignUp
+	originalSignIn := *originalImplementation.SignIn
+	originalGetUserByEmail := *originalImplementation.GetUserByEmail
+	originalUpdateEmailOrPassword := *originalImplementation.UpdateEmailOrPassword
+
+	findUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, string, error) {
+		var user *epmodels.User
+		storedEmail, _, err := supertokens.FindStoredEmail(policy, email, func(email string) (bool, error) {
+			var err error
+			user, err = originalGetUserByEmail(email, userContext)
+			return user != nil, err
+		}, userContext)
+		return user, storedEmail, err
+	}
+
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		// users who sign up with only a username are given a placeholder email that is not theirs to check
+		isPlaceholderEmail := strings.HasSuffix(email, "@"+epmodels.UsernamePlaceholderEmailDomain)
+		if msg := supertokens.ValidateEmailDomain(policy, email); msg != nil && !isPlaceholderEmail {
+			return epmodels.SignUpResponse{}, errors.FieldError{
+				Msg: "Error in input formFields",
+				Payload: []errors.ErrorPayload{{
+					ID:       "email",
+					ErrorMsg: *msg,
+				}},
+			}
+		}
+		existingUser, storedEmail, err := findUserByEmail(email, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		if existingUser != nil {
+			return epmodels.SignUpResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		response, err := originalSignUp(storedEmail, password, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		if response.OK != nil {
+			err = supertokens.StoreCanonicalEmail(policy, storedEmail, userContext)
+			if err != nil {
+				return epmodels.SignUpResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	signIn := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
+		_, storedEmail, err := findUserByEmail(email, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		return originalSignIn(storedEmail, password, userContext)
+	}
+
+	getUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, error) {
+		user, _, err := findUserByEmail(email, userContext)
+		return user, err
+	}
+
+	updateEmailOrPassword := func(userId string, email *string, password *string, userContext supertokens.UserContext) (epmodels.UpdateEmailOrPasswordResponse, error) {
+		if email == nil {
+			return originalUpdateEmailOrPassword(userId, email, password, userContext)
+		}
+		existingUser, _, err := findUserByEmail(*email, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if existingUser != nil && existingUser.ID != userId {
+			return epmodels.UpdateEmailOrPasswordResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		normalisedEmail := supertokens.NormaliseEmail(policy, *email)
+		response, err := originalUpdateEmailOrPassword(userId, &normalisedEmail, password, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if response.OK != nil {
+			err = supertokens.StoreCanonicalEmail(policy, normalisedEmail, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	*originalImplementation.SignUp = signUp
+	*originalImplementation.SignIn = signIn
+	*originalImplementation.GetUserByEmail = getUserByEmail
+	*originalImplementation.UpdateEmailOrPassword = updateEmailOrPassword
+	return originalImplementation
+}
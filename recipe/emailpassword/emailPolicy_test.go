@@ -0,0 +1,200 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func getEmailPolicyForTests(canonicalEmails map[string]string) *supertokens.EmailPolicy {
+	return &supertokens.EmailPolicy{
+		StripProviderAliases: true,
+		GetEmailByCanonicalEmail: func(canonicalEmail string, userContext supertokens.UserContext) (*string, error) {
+			email, ok := canonicalEmails[canonicalEmail]
+			if !ok {
+				return nil, nil
+			}
+			return &email, nil
+		},
+		SetCanonicalEmail: func(canonicalEmail string, email string, userContext supertokens.UserContext) error {
+			canonicalEmails[canonicalEmail] = email
+			return nil
+		},
+	}
+}
+
+func TestEmailPolicyNormalisesEmails(t *testing.T) {
+	canonicalEmails := map[string]string{}
+	policy, err := supertokens.NormaliseEmailPolicyOrThrowError(getEmailPolicyForTests(canonicalEmails))
+	assert.NoError(t, err)
+	users := map[string]*inMemoryEmailPasswordUser{}
+	recipeImplementation := MakeEmailPolicyRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), policy)
+	userContext := &map[string]interface{}{}
+
+	signUpResponse, err := (*recipeImplementation.SignUp)("John.Doe@Gmail.com", "password1", userContext)
+	assert.NoError(t, err)
+	// the aliases are kept in the stored email, and only removed from the lookup key
+	assert.Equal(t, "john.doe@gmail.com", signUpResponse.OK.User.Email)
+	assert.Equal(t, map[string]string{"johndoe@gmail.com": "john.doe@gmail.com"}, canonicalEmails)
+
+	signUpResponse, err = (*recipeImplementation.SignUp)("johndoe+alias@googlemail.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.EmailAlreadyExistsError)
+
+	signInResponse, err := (*recipeImplementation.SignIn)("JOHNDOE@gmail.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+
+	user, err := (*recipeImplementation.GetUserByEmail)("j.o.h.n.d.o.e@gmail.com", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "john.doe@gmail.com", user.Email)
+
+	newEmail := "John@Example.com"
+	_, err = (*recipeImplementation.UpdateEmailOrPassword)(user.ID, &newEmail, nil, userContext)
+	assert.NoError(t, err)
+	user, err = (*recipeImplementation.GetUserByEmail)("john@example.com", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+}
+
+func TestEmailPolicyFindsUsersStoredBeforeIt(t *testing.T) {
+	policy, err := supertokens.NormaliseEmailPolicyOrThrowError(getEmailPolicyForTests(map[string]string{}))
+	assert.NoError(t, err)
+	users := map[string]*inMemoryEmailPasswordUser{
+		"Foo@Example.com": {user: epmodels.User{ID: "user1", Email: "Foo@Example.com"}, password: "password1"},
+	}
+	recipeImplementation := MakeEmailPolicyRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), policy)
+	userContext := &map[string]interface{}{}
+
+	signInResponse, err := (*recipeImplementation.SignIn)("Foo@Example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.OK)
+
+	user, err := (*recipeImplementation.GetUserByEmail)(" Foo@Example.com", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", user.ID)
+
+	signUpResponse, err := (*recipeImplementation.SignUp)("Foo@Example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.EmailAlreadyExistsError)
+
+	otherEmail := "Foo@Example.com"
+	updateResponse, err := (*recipeImplementation.UpdateEmailOrPassword)("user2", &otherEmail, nil, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, updateResponse.EmailAlreadyExistsError)
+}
+
+func TestEmailPolicyIsCheckedBySignUp(t *testing.T) {
+	policy, err := supertokens.NormaliseEmailPolicyOrThrowError(&supertokens.EmailPolicy{
+		AllowedDomains: []string{"example.com"},
+	})
+	assert.NoError(t, err)
+	users := map[string]*inMemoryEmailPasswordUser{}
+	recipeImplementation := MakeEmailPolicyRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), policy)
+	userContext := &map[string]interface{}{}
+
+	_, err = (*recipeImplementation.SignUp)("john@gmail.com", "password1", userContext)
+	fieldError := err.(errors.FieldError)
+	assert.Equal(t, "email", fieldError.Payload[0].ID)
+	assert.Equal(t, "Email domain is not allowed", fieldError.Payload[0].ErrorMsg)
+	assert.Empty(t, users)
+
+	signUpResponse, err := (*recipeImplementation.SignUp)("john@example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.OK)
+
+	signUpResponse, err = (*recipeImplementation.SignUp)("0123abcd@"+epmodels.UsernamePlaceholderEmailDomain, "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.OK)
+}
+
+func TestEmailPolicyKeepsExistingUsersAndStoredEmails(t *testing.T) {
+	canonicalEmails := map[string]string{}
+	getConfig := func(emailPolicy *supertokens.EmailPolicy) supertokens.TypeInput {
+		return supertokens.TypeInput{
+			Supertokens: &supertokens.ConnectionInfo{
+				ConnectionURI: "http://localhost:8080",
+			},
+			AppInfo: supertokens.AppInfo{
+				APIDomain:     "api.supertokens.io",
+				AppName:       "SuperTokens",
+				WebsiteDomain: "supertokens.io",
+			},
+			RecipeList: []supertokens.Recipe{
+				Init(&epmodels.TypeInput{
+					EmailPolicy: emailPolicy,
+				}),
+				session.Init(nil),
+			},
+		}
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(getConfig(nil))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	signUpResponse, err := SignUp("Foo@Example.com", "validpass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.OK)
+
+	// the policy is enabled after the user signed up
+	resetAll()
+	err = supertokens.Init(getConfig(getEmailPolicyForTests(canonicalEmails)))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	getResponse := func(res *http.Response, err error) map[string]interface{} {
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		defer res.Body.Close()
+		var data map[string]interface{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+		return data
+	}
+
+	response := getResponse(unittesting.SignInRequest("Foo@Example.com", "validpass123", testServer.URL))
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, "Foo@Example.com", response["user"].(map[string]interface{})["email"])
+
+	response = getResponse(unittesting.SignupRequest("J.Doe+x@gmail.com", "validpass123", testServer.URL))
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, "j.doe+x@gmail.com", response["user"].(map[string]interface{})["email"])
+	assert.Equal(t, map[string]string{"jdoe@gmail.com": "j.doe+x@gmail.com"}, canonicalEmails)
+
+	response = getResponse(unittesting.SignupRequest("jdoe@gmail.com", "validpass123", testServer.URL))
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+
+	response = getResponse(unittesting.SignInRequest("JDoe@gmail.com", "validpass123", testServer.URL))
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, "j.doe+x@gmail.com", response["user"].(map[string]interface{})["email"])
+}
@@ -35,10 +35,16 @@ type APIOptions struct {
 
 type APIInterface struct {
 	EmailExistsGET                 *func(email string, options APIOptions, userContext supertokens.UserContext) (EmailExistsGETResponse, error)
+	UsernameExistsGET              *func(username string, options APIOptions, userContext supertokens.UserContext) (UsernameExistsGETResponse, error)
 	GeneratePasswordResetTokenPOST *func(formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (GeneratePasswordResetTokenPOSTResponse, error)
 	PasswordResetPOST              *func(formFields []TypeFormField, token string, options APIOptions, userContext supertokens.UserContext) (ResetPasswordUsingTokenResponse, error)
 	SignInPOST                     *func(formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (SignInPOSTResponse, error)
 	SignUpPOST                     *func(formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (SignUpPOSTResponse, error)
+	ChangePasswordPOST             *func(oldPassword string, formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (ChangePasswordPOSTResponse, error)
+	EmailChangePOST                *func(newEmail string, options APIOptions, userContext supertokens.UserContext) (EmailChangePOSTResponse, error)
+	EmailChangeVerifyPOST          *func(token string, options APIOptions, userContext supertokens.UserContext) (EmailChangeVerifyPOSTResponse, error)
+	EmailChangeRevertPOST          *func(token string, options APIOptions, userContext supertokens.UserContext) (EmailChangeRevertPOSTResponse, error)
+	UnlockAccountPOST              *func(token string, options APIOptions, userContext supertokens.UserContext) (UnlockAccountPOSTResponse, error)
 }
 
 type SignUpPOSTResponse struct {
@@ -55,12 +61,49 @@ type SignInPOSTResponse struct {
 		Session sessmodels.SessionContainer
 	}
 	WrongCredentialsError *struct{}
+	LockedAccountError    *struct{}
 }
 
 type EmailExistsGETResponse struct {
 	OK *struct{ Exists bool }
 }
 
+type UsernameExistsGETResponse struct {
+	OK *struct{ Exists bool }
+}
+
 type GeneratePasswordResetTokenPOSTResponse struct {
 	OK *struct{}
 }
+
+type ChangePasswordPOSTResponse struct {
+	OK                    *struct{}
+	WrongCredentialsError *struct{}
+	LockedAccountError    *struct{}
+}
+
+type EmailChangePOSTResponse struct {
+	OK                      *struct{}
+	EmailAlreadyExistsError *struct{}
+}
+
+type EmailChangeVerifyPOSTResponse struct {
+	OK *struct {
+		User User
+	}
+	EmailChangeInvalidTokenError *struct{}
+	EmailAlreadyExistsError      *struct{}
+}
+
+type EmailChangeRevertPOSTResponse struct {
+	OK *struct {
+		User User
+	}
+	EmailChangeInvalidTokenError *struct{}
+	EmailAlreadyExistsError      *struct{}
+}
+
+type UnlockAccountPOSTResponse struct {
+	OK                             *struct{}
+	UnlockAccountInvalidTokenError *struct{}
+}
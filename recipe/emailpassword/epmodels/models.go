@@ -16,6 +16,8 @@
 package epmodels
 
 import (
+	"net/http"
+
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -25,6 +27,13 @@ type TypeNormalisedInput struct {
 	SignInFeature                  TypeNormalisedInputSignIn
 	ResetPasswordUsingTokenFeature TypeNormalisedInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       evmodels.TypeInput
+	PasswordPolicy                 TypeNormalisedInputPasswordPolicy
+	LegacyPasswordMigration        *TypeInputLegacyPasswordMigration
+	ChangePasswordFeature          TypeNormalisedInputChangePasswordFeature
+	EmailChangeFeature             TypeNormalisedInputEmailChangeFeature
+	EmailPolicy                    supertokens.NormalisedEmailPolicy
+	UsernameFeature                TypeNormalisedInputUsernameFeature
+	BruteForceProtection           TypeNormalisedInputBruteForceProtection
 	Override                       OverrideStruct
 }
 
@@ -39,24 +48,42 @@ type TypeInputEmailVerificationFeature struct {
 	CreateAndSendCustomEmail func(user User, emailVerificationURLWithToken string, userContext supertokens.UserContext)
 }
 
+// where the value of a sign up form field is stored after a successful sign up
+const (
+	FormFieldStorageUserMetadata       = "USER_METADATA"
+	FormFieldStorageSessionData        = "SESSION_DATA"
+	FormFieldStorageAccessTokenPayload = "ACCESS_TOKEN_PAYLOAD"
+)
+
 type TypeInputFormField struct {
 	ID       string
 	Validate func(value interface{}) *string
 	Optional *bool
+	// Storage is one of the FormFieldStorage constants. The value is stored under the ID of the field
+	Storage *string
+	// AcceptsJSONValues lets the field be sent as a bool, number, array or object rather than only as a
+	// string. It is true by default for fields with a Storage, and is ignored for the email and password
+	AcceptsJSONValues *bool
 }
 
 type TypeInputSignUp struct {
 	FormFields []TypeInputFormField
+	// UpdateUserMetadata is required if a form field is stored in the user metadata. metadataUpdate only
+	// contains the fields of the sign up form, and should be merged into any existing metadata
+	UpdateUserMetadata func(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) error
 }
 
 type NormalisedFormField struct {
-	ID       string
-	Validate func(value interface{}) *string
-	Optional bool
+	ID                string
+	Validate          func(value interface{}) *string
+	Optional          bool
+	Storage           string
+	AcceptsJSONValues bool
 }
 
 type TypeNormalisedInputSignUp struct {
-	FormFields []NormalisedFormField
+	FormFields         []NormalisedFormField
+	UpdateUserMetadata func(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) error
 }
 
 type TypeNormalisedInputSignIn struct {
@@ -75,6 +102,144 @@ type TypeNormalisedInputResetPasswordUsingTokenFeature struct {
 	FormFieldsForPasswordResetForm []NormalisedFormField
 }
 
+type TypeInputChangePasswordFeature struct {
+	// revokes all sessions of the user except the one used to change the password
+	RevokeOtherSessions bool
+}
+
+type TypeNormalisedInputChangePasswordFeature struct {
+	RevokeOtherSessions bool
+}
+
+type TypeInputEmailChangeFeature struct {
+	// used to sign the links that confirm a change and the ones that let the previous address undo it.
+	// Must be at least 32 bytes long
+	SigningKey []byte
+
+	GetEmailChangeURL func(user User, newEmail string, userContext supertokens.UserContext) (string, error)
+	// sends the confirmation link to the new address
+	CreateAndSendCustomEmail func(user User, newEmail string, emailChangeURLWithToken string, userContext supertokens.UserContext)
+
+	GetEmailChangeRevertURL func(user User, userContext supertokens.UserContext) (string, error)
+	// tells the previous address about the change once it is applied. user.Email is the previous address
+	CreateAndSendCustomRevertEmail func(user User, newEmail string, emailChangeRevertURLWithToken string, userContext supertokens.UserContext)
+
+	// defaults to 1 day
+	TokenValidityMS *uint64
+	// defaults to 7 days
+	RevertTokenValidityMS *uint64
+
+	RevokeSessionsOnEmailChange bool
+}
+
+type TypeNormalisedInputEmailChangeFeature struct {
+	Enabled                        bool
+	SigningKey                     []byte
+	GetEmailChangeURL              func(user User, newEmail string, userContext supertokens.UserContext) (string, error)
+	CreateAndSendCustomEmail       func(user User, newEmail string, emailChangeURLWithToken string, userContext supertokens.UserContext)
+	GetEmailChangeRevertURL        func(user User, userContext supertokens.UserContext) (string, error)
+	CreateAndSendCustomRevertEmail func(user User, newEmail string, emailChangeRevertURLWithToken string, userContext supertokens.UserContext)
+	TokenValidityMS                uint64
+	RevertTokenValidityMS          uint64
+	RevokeSessionsOnEmailChange    bool
+}
+
+// users who sign up with only a username get a placeholder email on this domain. The domain is
+// reserved, so these emails can never receive anything
+const UsernamePlaceholderEmailDomain = "username.invalid"
+
+type TypeInputUsernameFeature struct {
+	// lets users sign up without an email, in which case they can only sign in with their username
+	EmailOptional bool
+
+	// defaults to 3 to 32 letters, numbers, dots, underscores and hyphens. Usernames can never
+	// contain an @, so that they are not mistaken for emails when signing in
+	Validate func(value interface{}) *string
+
+	// storage for the usernames, which are trimmed and lower cased before they reach these functions.
+	// ReserveUsername is called before the user is created, and should return false if the username
+	// is already taken or reserved, for example by inserting it with a unique index. SetUsername then
+	// stores the ID of the new user for the reserved username, and ReleaseUsername frees the reservation
+	// if the user could not be created. GetUserIDByUsername should return nil for reserved usernames
+	GetUserIDByUsername func(username string, userContext supertokens.UserContext) (*string, error)
+	ReserveUsername     func(username string, userContext supertokens.UserContext) (bool, error)
+	SetUsername         func(userID string, username string, userContext supertokens.UserContext) error
+	ReleaseUsername     func(username string, userContext supertokens.UserContext) error
+}
+
+type TypeNormalisedInputUsernameFeature struct {
+	Enabled             bool
+	EmailOptional       bool
+	Validate            func(value interface{}) *string
+	GetUserIDByUsername func(username string, userContext supertokens.UserContext) (*string, error)
+	ReserveUsername     func(username string, userContext supertokens.UserContext) (bool, error)
+	SetUsername         func(userID string, username string, userContext supertokens.UserContext) error
+	ReleaseUsername     func(username string, userContext supertokens.UserContext) error
+}
+
+type TypeInputBruteForceProtection struct {
+	// failed sign ins for one account before it is locked. Defaults to 5
+	MaxFailedAttemptsPerAccount *int
+	// failed sign ins from one IP address before all sign ins from it are locked. Defaults to 50, and is
+	// only used if GetIPAddress is set
+	MaxFailedAttemptsPerIP *int
+	// failed sign ins older than this are forgotten. Defaults to 15 minutes
+	FailedAttemptsWindowMS *uint64
+	// defaults to 15 minutes
+	LockoutDurationMS *uint64
+
+	// every failed sign in for an account takes twice as long to respond as the one before, starting at
+	// InitialDelayMS and up to MaxDelayMS. These default to 250 milliseconds and 5 seconds. Set
+	// InitialDelayMS to 0 to respond without a delay
+	InitialDelayMS *uint64
+	MaxDelayMS     *uint64
+
+	// failed sign ins are only counted per IP address if this is set. Behind a proxy, it has to return the
+	// address of the client rather than the remote address of the connection
+	GetIPAddress func(req *http.Request) string
+
+	// sends a link that unlocks the account once it is locked. No email is sent if this is nil
+	CreateAndSendCustomEmail func(user User, unlockAccountURLWithToken string, userContext supertokens.UserContext)
+	GetUnlockAccountURL      func(user User, userContext supertokens.UserContext) (string, error)
+
+	// defaults to keeping the failed sign ins and locks in memory, which only works with a single instance
+	// of the backend. Use a shared store, such as Redis, when running more than one
+	Store *BruteForceProtectionStore
+}
+
+type TypeNormalisedInputBruteForceProtection struct {
+	Enabled                     bool
+	MaxFailedAttemptsPerAccount int
+	MaxFailedAttemptsPerIP      int
+	FailedAttemptsWindowMS      uint64
+	LockoutDurationMS           uint64
+	InitialDelayMS              uint64
+	MaxDelayMS                  uint64
+	GetIPAddress                func(req *http.Request) string
+	CreateAndSendCustomEmail    func(user User, unlockAccountURLWithToken string, userContext supertokens.UserContext)
+	GetUnlockAccountURL         func(user User, userContext supertokens.UserContext) (string, error)
+	Store                       BruteForceProtectionStore
+}
+
+// BruteForceProtectionStore keeps the failed sign ins and the locks. Keys start with "user:" for known
+// accounts, "identifier:" for emails and usernames without an account and "ip:" for IP addresses
+type BruteForceProtectionStore struct {
+	// adds a failed sign in and returns the number of failed sign ins for the key. The count starts
+	// again once windowMS has passed since the first failed sign in it includes
+	IncrementFailedAttempts func(key string, windowMS uint64, userContext supertokens.UserContext) (int, error)
+	ResetFailedAttempts     func(key string, userContext supertokens.UserContext) error
+	// returns nil if the key is not locked. Expired locks may be returned, and are ignored
+	GetLock    func(key string, userContext supertokens.UserContext) (*SignInLock, error)
+	SetLock    func(key string, lock SignInLock, userContext supertokens.UserContext) error
+	RemoveLock func(key string, userContext supertokens.UserContext) error
+}
+
+type SignInLock struct {
+	LockedUntil uint64
+	// the hash of the token in the unlock email. Empty if no email was sent
+	UnlockTokenHash string
+}
+
 type User struct {
 	ID         string `json:"id"`
 	Email      string `json:"email"`
@@ -85,10 +250,107 @@ type TypeInput struct {
 	SignUpFeature                  *TypeInputSignUp
 	ResetPasswordUsingTokenFeature *TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       *TypeInputEmailVerificationFeature
+	PasswordPolicy                 *TypeInputPasswordPolicy
+	LegacyPasswordMigration        *TypeInputLegacyPasswordMigration
+	ChangePasswordFeature          *TypeInputChangePasswordFeature
+	EmailChangeFeature             *TypeInputEmailChangeFeature
+	EmailPolicy                    *supertokens.EmailPolicy
+	UsernameFeature                *TypeInputUsernameFeature
+	BruteForceProtection           *TypeInputBruteForceProtection
 	Override                       *OverrideStruct
 }
 
+type TypeInputPasswordPolicy struct {
+	// defaults to 8
+	MinLength *int
+	// defaults to 100, exclusive
+	MaxLength *int
+	// defaults to true
+	RequireLetter *bool
+	// defaults to true
+	RequireNumber    *bool
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireSymbol    bool
+
+	DisallowEmailAndUsernameInPassword bool
+
+	PasswordHistory   *TypeInputPasswordHistory
+	BreachedPasswords *TypeInputBreachedPasswords
+}
+
+type TypeInputPasswordHistory struct {
+	// number of previous passwords that cannot be reused
+	Size              int
+	GetPasswordHashes func(userID string, userContext supertokens.UserContext) ([]string, error)
+	SetPasswordHashes func(userID string, passwordHashes []string, userContext supertokens.UserContext) error
+	// SigningKey signs the user ID into password reset tokens, so that the
+	// history of the user can be checked before their password is reset. It
+	// must be at least 32 bytes long.
+	SigningKey []byte
+}
+
+type TypeInputBreachedPasswords struct {
+	// directory containing one file per SHA-1 hash prefix (the first five hex
+	// characters, for example 5BAA6.txt). Each line of a file is the remaining
+	// hash suffix, optionally followed by ":<count>", which is the format served
+	// by the Have I Been Pwned range API.
+	HashPrefixDirectory *string
+	IsPasswordBreached  func(password string, userContext supertokens.UserContext) (bool, error)
+}
+
+type TypeNormalisedInputPasswordPolicy struct {
+	Enabled                            bool
+	MinLength                          int
+	MaxLength                          int
+	RequireLetter                      bool
+	RequireNumber                      bool
+	RequireLowercase                   bool
+	RequireUppercase                   bool
+	RequireSymbol                      bool
+	DisallowEmailAndUsernameInPassword bool
+	PasswordHistory                    *TypeInputPasswordHistory
+	IsPasswordBreached                 func(password string, userContext supertokens.UserContext) (bool, error)
+}
+
 type TypeFormField struct {
-	ID    string `json:"id"`
+	ID string `json:"id"`
+	// Value is empty if the field was not sent as a string
 	Value string `json:"value"`
+	// RawValue is the value as it was sent: a string, bool, float64, []interface{} or map[string]interface{}.
+	// A null value is treated as an empty string
+	RawValue interface{} `json:"-"`
+}
+
+type LegacyPasswordHash struct {
+	Algorithm string
+	Hash      string
+}
+
+type TypeInputLegacyPasswordMigration struct {
+	// Verifiers checks a password against a legacy hash, keyed by the
+	// algorithm name used when importing the user. Verifiers for "bcrypt",
+	// "argon2id", "scrypt" and "pbkdf2_sha256" are built in, and can be
+	// replaced by a verifier with the same name.
+	Verifiers map[string]func(password string, passwordHash string) (bool, error)
+
+	// storage for the legacy hashes of imported users that have not signed in yet
+	GetLegacyPasswordHash     func(userID string, userContext supertokens.UserContext) (*LegacyPasswordHash, error)
+	SetLegacyPasswordHash     func(userID string, passwordHash LegacyPasswordHash, userContext supertokens.UserContext) error
+	RemoveLegacyPasswordHash  func(userID string, userContext supertokens.UserContext) error
+	CountLegacyPasswordHashes func(userContext supertokens.UserContext) (int, error)
+}
+
+type ImportUserWithPasswordHashResponse struct {
+	OK *struct {
+		User User
+	}
+	EmailAlreadyExistsError *struct{}
+}
+
+type LegacyPasswordMigrationStatus struct {
+	// all users of the recipe, including those that signed up normally
+	TotalUsers int
+	// imported users that still have a legacy password hash
+	UsersWithLegacyPassword int
 }
@@ -21,8 +21,30 @@ type FieldError struct {
 }
 
 type ErrorPayload struct {
-	ID       string `json:"id"`
-	ErrorMsg string `json:"error"`
+	ID         string                    `json:"id"`
+	ErrorMsg   string                    `json:"error"`
+	Violations []PasswordPolicyViolation `json:"violations,omitempty"`
+}
+
+const (
+	PasswordPolicyMinLength               = "MIN_LENGTH"
+	PasswordPolicyMaxLength               = "MAX_LENGTH"
+	PasswordPolicyLetter                  = "LETTER"
+	PasswordPolicyNumber                  = "NUMBER"
+	PasswordPolicyLowercase               = "LOWERCASE"
+	PasswordPolicyUppercase               = "UPPERCASE"
+	PasswordPolicySymbol                  = "SYMBOL"
+	PasswordPolicyContainsEmailOrUsername = "CONTAINS_EMAIL_OR_USERNAME"
+	PasswordPolicyReused                  = "REUSED"
+	PasswordPolicyBreached                = "BREACHED"
+)
+
+// PasswordPolicyViolation is one failing rule of the password policy. All
+// violations of a password are returned together so that the frontend can
+// show every rule that needs fixing.
+type PasswordPolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 func (err FieldError) Error() string {
@@ -0,0 +1,182 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func validateAndNormaliseLegacyPasswordMigrationConfig(config *epmodels.TypeInputLegacyPasswordMigration) (*epmodels.TypeInputLegacyPasswordMigration, error) {
+	verifiers := getBuiltInLegacyPasswordVerifiers()
+	for algorithm, verifier := range config.Verifiers {
+		if verifier == nil {
+			return nil, errors.New("legacyPasswordMigration verifier for " + algorithm + " is nil")
+		}
+		verifiers[algorithm] = verifier
+	}
+	if config.GetLegacyPasswordHash == nil || config.SetLegacyPasswordHash == nil || config.RemoveLegacyPasswordHash == nil || config.CountLegacyPasswordHashes == nil {
+		return nil, errors.New("please provide GetLegacyPasswordHash, SetLegacyPasswordHash, RemoveLegacyPasswordHash and CountLegacyPasswordHashes in legacyPasswordMigration")
+	}
+	normalisedConfig := *config
+	normalisedConfig.Verifiers = verifiers
+	return &normalisedConfig, nil
+}
+
+// makeLegacyPasswordMigrationRecipeImplementation verifies the password of an
+// imported user against their legacy hash when the core rejects it, and moves
+// the user over to the core's hashing on success. The legacy hash is removed
+// whenever the password is changed.
+func makeLegacyPasswordMigrationRecipeImplementation(originalImplementation epmodels.RecipeInterface, config *epmodels.TypeInputLegacyPasswordMigration) epmodels.RecipeInterface {
+	originalSignIn := *originalImplementation.SignIn
+	originalResetPasswordUsingToken := *originalImplementation.ResetPasswordUsingToken
+	originalUpdateEmailOrPassword := *originalImplementation.UpdateEmailOrPassword
+
+	updateEmailOrPassword := func(userId string, email *string, password *string, userContext supertokens.UserContext) (epmodels.UpdateEmailOrPasswordResponse, error) {
+		response, err := originalUpdateEmailOrPassword(userId, email, password, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if response.OK != nil && password != nil {
+			err = config.RemoveLegacyPasswordHash(userId, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	resetPasswordUsingToken := func(token string, newPassword string, userContext supertokens.UserContext) (epmodels.ResetPasswordUsingTokenResponse, error) {
+		response, err := originalResetPasswordUsingToken(token, newPassword, userContext)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		if response.OK != nil && response.OK.UserId != nil {
+			err = config.RemoveLegacyPasswordHash(*response.OK.UserId, userContext)
+			if err != nil {
+				return epmodels.ResetPasswordUsingTokenResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	signIn := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
+		response, err := originalSignIn(email, password, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if response.WrongCredentialsError == nil {
+			return response, nil
+		}
+
+		user, err := (*originalImplementation.GetUserByEmail)(email, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if user == nil {
+			return response, nil
+		}
+		legacyPasswordHash, err := config.GetLegacyPasswordHash(user.ID, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if legacyPasswordHash == nil {
+			return response, nil
+		}
+		verifier, ok := config.Verifiers[legacyPasswordHash.Algorithm]
+		if !ok {
+			return epmodels.SignInResponse{}, errors.New("no legacy password verifier for algorithm " + legacyPasswordHash.Algorithm)
+		}
+		matches, err := verifier(password, legacyPasswordHash.Hash)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if !matches {
+			return response, nil
+		}
+
+		// rehash the password in the core so that the legacy hash is no longer needed
+		updateResponse, err := updateEmailOrPassword(user.ID, nil, &password, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if updateResponse.OK == nil {
+			return response, nil
+		}
+		return epmodels.SignInResponse{
+			OK: &struct{ User epmodels.User }{User: *user},
+		}, nil
+	}
+
+	*originalImplementation.SignIn = signIn
+	*originalImplementation.ResetPasswordUsingToken = resetPasswordUsingToken
+	*originalImplementation.UpdateEmailOrPassword = updateEmailOrPassword
+	return originalImplementation
+}
+
+func (r *Recipe) importUserWithPasswordHash(email string, passwordHash epmodels.LegacyPasswordHash, userContext supertokens.UserContext) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	config := r.Config.LegacyPasswordMigration
+	if config == nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, errors.New("please configure legacyPasswordMigration in the emailpassword recipe to import users")
+	}
+	if _, ok := config.Verifiers[passwordHash.Algorithm]; !ok {
+		return epmodels.ImportUserWithPasswordHashResponse{}, errors.New("no legacy password verifier for algorithm " + passwordHash.Algorithm)
+	}
+
+	// the user gets a random password in the core until they first sign in
+	randomPassword, err := generateRandomPassword()
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	response, err := (*r.RecipeImpl.SignUp)(email, randomPassword, userContext)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	if response.EmailAlreadyExistsError != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{
+			EmailAlreadyExistsError: &struct{}{},
+		}, nil
+	}
+
+	err = config.SetLegacyPasswordHash(response.OK.User.ID, passwordHash, userContext)
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	return epmodels.ImportUserWithPasswordHashResponse{
+		OK: &struct{ User epmodels.User }{User: response.OK.User},
+	}, nil
+}
+
+func (r *Recipe) getLegacyPasswordMigrationStatus(userContext supertokens.UserContext) (epmodels.LegacyPasswordMigrationStatus, error) {
+	config := r.Config.LegacyPasswordMigration
+	if config == nil {
+		return epmodels.LegacyPasswordMigrationStatus{}, errors.New("please configure legacyPasswordMigration in the emailpassword recipe to get the migration status")
+	}
+	totalUsers, err := supertokens.GetUserCount(&[]string{r.RecipeModule.GetRecipeID()})
+	if err != nil {
+		return epmodels.LegacyPasswordMigrationStatus{}, err
+	}
+	usersWithLegacyPassword, err := config.CountLegacyPasswordHashes(userContext)
+	if err != nil {
+		return epmodels.LegacyPasswordMigrationStatus{}, err
+	}
+	return epmodels.LegacyPasswordMigrationStatus{
+		TotalUsers:              int(totalUsers),
+		UsersWithLegacyPassword: usersWithLegacyPassword,
+	}, nil
+}
@@ -0,0 +1,200 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func makeInMemoryLegacyPasswordMigrationConfig(hashes map[string]epmodels.LegacyPasswordHash) *epmodels.TypeInputLegacyPasswordMigration {
+	return &epmodels.TypeInputLegacyPasswordMigration{
+		Verifiers: map[string]func(password string, passwordHash string) (bool, error){
+			"plain": func(password string, passwordHash string) (bool, error) {
+				return password == passwordHash, nil
+			},
+		},
+		GetLegacyPasswordHash: func(userID string, userContext supertokens.UserContext) (*epmodels.LegacyPasswordHash, error) {
+			hash, ok := hashes[userID]
+			if !ok {
+				return nil, nil
+			}
+			return &hash, nil
+		},
+		SetLegacyPasswordHash: func(userID string, passwordHash epmodels.LegacyPasswordHash, userContext supertokens.UserContext) error {
+			hashes[userID] = passwordHash
+			return nil
+		},
+		RemoveLegacyPasswordHash: func(userID string, userContext supertokens.UserContext) error {
+			delete(hashes, userID)
+			return nil
+		},
+		CountLegacyPasswordHashes: func(userContext supertokens.UserContext) (int, error) {
+			return len(hashes), nil
+		},
+	}
+}
+
+func TestLegacyPasswordIsMigratedOnSignIn(t *testing.T) {
+	users := map[string]*inMemoryEmailPasswordUser{
+		"test@example.com": {user: epmodels.User{ID: "user1", Email: "test@example.com"}, password: "random"},
+	}
+	hashes := map[string]epmodels.LegacyPasswordHash{"user1": {Algorithm: "plain", Hash: "legacyPassword1"}}
+	recipeImplementation := makeLegacyPasswordMigrationRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), makeInMemoryLegacyPasswordMigrationConfig(hashes))
+	userContext := &map[string]interface{}{}
+
+	response, err := (*recipeImplementation.SignIn)("test@example.com", "wrongPassword1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.WrongCredentialsError)
+	assert.Len(t, hashes, 1)
+
+	response, err = (*recipeImplementation.SignIn)("test@example.com", "legacyPassword1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+	assert.Equal(t, "user1", response.OK.User.ID)
+	assert.Equal(t, "legacyPassword1", users["test@example.com"].password)
+	assert.Empty(t, hashes)
+
+	response, err = (*recipeImplementation.SignIn)("test@example.com", "legacyPassword1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+}
+
+func TestLegacyPasswordIsRemovedOnPasswordReset(t *testing.T) {
+	users := map[string]*inMemoryEmailPasswordUser{
+		"test@example.com": {user: epmodels.User{ID: "user1", Email: "test@example.com"}, password: "random"},
+	}
+	hashes := map[string]epmodels.LegacyPasswordHash{"user1": {Algorithm: "plain", Hash: "legacyPassword1"}}
+	recipeImplementation := makeLegacyPasswordMigrationRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), makeInMemoryLegacyPasswordMigrationConfig(hashes))
+	userContext := &map[string]interface{}{}
+
+	response, err := (*recipeImplementation.ResetPasswordUsingToken)("token-user1", "newPassword1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+	assert.Empty(t, hashes)
+
+	signInResponse, err := (*recipeImplementation.SignIn)("test@example.com", "legacyPassword1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+}
+
+func TestLegacyPasswordWithUnknownAlgorithm(t *testing.T) {
+	users := map[string]*inMemoryEmailPasswordUser{
+		"test@example.com": {user: epmodels.User{ID: "user1", Email: "test@example.com"}, password: "random"},
+	}
+	hashes := map[string]epmodels.LegacyPasswordHash{"user1": {Algorithm: "md5", Hash: "legacyPassword1"}}
+	recipeImplementation := makeLegacyPasswordMigrationRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), makeInMemoryLegacyPasswordMigrationConfig(hashes))
+
+	_, err := (*recipeImplementation.SignIn)("test@example.com", "legacyPassword1", &map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestLegacyPasswordMigrationConfigValidation(t *testing.T) {
+	_, err := validateAndNormaliseLegacyPasswordMigrationConfig(&epmodels.TypeInputLegacyPasswordMigration{})
+	assert.Error(t, err)
+
+	config := makeInMemoryLegacyPasswordMigrationConfig(map[string]epmodels.LegacyPasswordHash{})
+	config.CountLegacyPasswordHashes = nil
+	_, err = validateAndNormaliseLegacyPasswordMigrationConfig(config)
+	assert.Error(t, err)
+
+	_, err = validateAndNormaliseLegacyPasswordMigrationConfig(makeInMemoryLegacyPasswordMigrationConfig(map[string]epmodels.LegacyPasswordHash{}))
+	assert.NoError(t, err)
+}
+
+func TestLegacyPasswordMigrationHasBuiltInVerifiers(t *testing.T) {
+	config, err := validateAndNormaliseLegacyPasswordMigrationConfig(makeInMemoryLegacyPasswordMigrationConfig(map[string]epmodels.LegacyPasswordHash{}))
+	assert.NoError(t, err)
+	for _, algorithm := range []string{LegacyPasswordAlgorithmBcrypt, LegacyPasswordAlgorithmArgon2id, LegacyPasswordAlgorithmScrypt, LegacyPasswordAlgorithmPBKDF2SHA256, "plain"} {
+		assert.NotNil(t, config.Verifiers[algorithm], algorithm)
+	}
+
+	overridden := makeInMemoryLegacyPasswordMigrationConfig(map[string]epmodels.LegacyPasswordHash{})
+	overridden.Verifiers[LegacyPasswordAlgorithmBcrypt] = func(password string, passwordHash string) (bool, error) {
+		return true, nil
+	}
+	config, err = validateAndNormaliseLegacyPasswordMigrationConfig(overridden)
+	assert.NoError(t, err)
+	matches, err := config.Verifiers[LegacyPasswordAlgorithmBcrypt]("password", "not a hash")
+	assert.NoError(t, err)
+	assert.True(t, matches)
+}
+
+func TestImportedUserSignsInWithBuiltInVerifier(t *testing.T) {
+	hashes := map[string]epmodels.LegacyPasswordHash{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				LegacyPasswordMigration: makeInMemoryLegacyPasswordMigrationConfig(hashes),
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	importResponse, err := ImportUserWithPasswordHash("random@gmail.com", epmodels.LegacyPasswordHash{
+		Algorithm: LegacyPasswordAlgorithmPBKDF2SHA256,
+		Hash:      "pbkdf2_sha256$1000$seasalt$cwjfV6rMyB4JPleS6hcvA8eIXRM97iYOZWLk8XjiLrw=",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, importResponse.OK)
+	assert.Len(t, hashes, 1)
+
+	signIn := func(password string) map[string]interface{} {
+		res, err := unittesting.SignInRequest("random@gmail.com", password, testServer.URL)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		defer res.Body.Close()
+		var data map[string]interface{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&data))
+		return data
+	}
+
+	assert.Equal(t, "WRONG_CREDENTIALS_ERROR", signIn("legacyPassword2")["status"])
+	assert.Len(t, hashes, 1)
+
+	assert.Equal(t, "OK", signIn("legacyPassword1")["status"])
+	assert.Empty(t, hashes)
+
+	// the core now knows the password, so the user no longer needs the legacy hash
+	assert.Equal(t, "OK", signIn("legacyPassword1")["status"])
+}
@@ -0,0 +1,172 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// the algorithm names of the built in legacy password verifiers
+const (
+	LegacyPasswordAlgorithmBcrypt       = "bcrypt"
+	LegacyPasswordAlgorithmArgon2id     = "argon2id"
+	LegacyPasswordAlgorithmScrypt       = "scrypt"
+	LegacyPasswordAlgorithmPBKDF2SHA256 = "pbkdf2_sha256"
+)
+
+func getBuiltInLegacyPasswordVerifiers() map[string]func(password string, passwordHash string) (bool, error) {
+	return map[string]func(password string, passwordHash string) (bool, error){
+		LegacyPasswordAlgorithmBcrypt:       VerifyBcryptPasswordHash,
+		LegacyPasswordAlgorithmArgon2id:     VerifyArgon2idPasswordHash,
+		LegacyPasswordAlgorithmScrypt:       VerifyScryptPasswordHash,
+		LegacyPasswordAlgorithmPBKDF2SHA256: VerifyPBKDF2SHA256PasswordHash,
+	}
+}
+
+// VerifyBcryptPasswordHash checks a password against a bcrypt hash in the
+// modular crypt format, for example $2b$10$<salt and hash>
+func VerifyBcryptPasswordHash(password string, passwordHash string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// VerifyArgon2idPasswordHash checks a password against an argon2id hash in
+// the PHC string format used by the reference implementation, for example
+// $argon2id$v=19$m=65536,t=2,p=1$<salt>$<hash> with the salt and hash base64
+// encoded without padding
+func VerifyArgon2idPasswordHash(password string, passwordHash string) (bool, error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return false, errors.New("invalid argon2id password hash")
+	}
+	if parts[2] != "v="+strconv.Itoa(argon2.Version) {
+		return false, errors.New("unsupported argon2id version " + parts[2])
+	}
+	params, err := parsePasswordHashParams(parts[3], "m", "t", "p")
+	if err != nil {
+		return false, err
+	}
+	if params["p"] > 255 {
+		return false, errors.New("invalid argon2id password hash")
+	}
+	salt, expectedKey, err := decodePasswordHashSaltAndKey(parts[4], parts[5])
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), salt, uint32(params["t"]), uint32(params["m"]), uint8(params["p"]), uint32(len(expectedKey)))
+	return subtle.ConstantTimeCompare(key, expectedKey) == 1, nil
+}
+
+// VerifyScryptPasswordHash checks a password against a scrypt hash in the PHC
+// string format, for example $scrypt$ln=14,r=8,p=1$<salt>$<hash> where ln is
+// log2 of the cost parameter N, and the salt and hash are base64 encoded
+// without padding
+func VerifyScryptPasswordHash(password string, passwordHash string) (bool, error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != "scrypt" {
+		return false, errors.New("invalid scrypt password hash")
+	}
+	params, err := parsePasswordHashParams(parts[2], "ln", "r", "p")
+	if err != nil {
+		return false, err
+	}
+	if params["ln"] > 30 {
+		return false, errors.New("invalid scrypt password hash")
+	}
+	salt, expectedKey, err := decodePasswordHashSaltAndKey(parts[3], parts[4])
+	if err != nil {
+		return false, err
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<params["ln"], int(params["r"]), int(params["p"]), len(expectedKey))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(key, expectedKey) == 1, nil
+}
+
+// VerifyPBKDF2SHA256PasswordHash checks a password against a PBKDF2-HMAC-SHA256
+// hash in the format used by Django: pbkdf2_sha256$<iterations>$<salt>$<hash>
+// where the hash is base64 encoded and the salt is used as is
+func VerifyPBKDF2SHA256PasswordHash(password string, passwordHash string) (bool, error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2_sha256" {
+		return false, errors.New("invalid pbkdf2_sha256 password hash")
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false, errors.New("invalid pbkdf2_sha256 password hash")
+	}
+	expectedKey, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(expectedKey) == 0 {
+		return false, errors.New("invalid pbkdf2_sha256 password hash")
+	}
+	key := pbkdf2.Key([]byte(password), []byte(parts[2]), iterations, len(expectedKey), sha256.New)
+	return subtle.ConstantTimeCompare(key, expectedKey) == 1, nil
+}
+
+// parsePasswordHashParams parses the parameters of a PHC string, like
+// m=65536,t=2,p=1, which must contain exactly the given names
+func parsePasswordHashParams(params string, names ...string) (map[string]uint64, error) {
+	result := map[string]uint64{}
+	for _, param := range strings.Split(params, ",") {
+		nameAndValue := strings.SplitN(param, "=", 2)
+		if len(nameAndValue) != 2 {
+			return nil, errors.New("invalid password hash parameter " + param)
+		}
+		value, err := strconv.ParseUint(nameAndValue[1], 10, 32)
+		if err != nil || value == 0 {
+			return nil, errors.New("invalid password hash parameter " + param)
+		}
+		result[nameAndValue[0]] = value
+	}
+	if len(result) != len(names) {
+		return nil, errors.New("invalid password hash parameters " + params)
+	}
+	for _, name := range names {
+		if _, ok := result[name]; !ok {
+			return nil, errors.New("missing password hash parameter " + name)
+		}
+	}
+	return result, nil
+}
+
+func decodePasswordHashSaltAndKey(encodedSalt string, encodedKey string) ([]byte, []byte, error) {
+	salt, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encodedSalt, "="))
+	if err != nil {
+		return nil, nil, errors.New("invalid password hash salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(encodedKey, "="))
+	if err != nil || len(key) == 0 {
+		return nil, nil, errors.New("invalid password hash")
+	}
+	return salt, key, nil
+}
@@ -0,0 +1,83 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyBcryptPasswordHash(t *testing.T) {
+	// test vector from the OpenBSD bcrypt implementation, as used by John the Ripper
+	matches, err := VerifyBcryptPasswordHash("U*U", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW")
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = VerifyBcryptPasswordHash("U*U*", "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW")
+	assert.NoError(t, err)
+	assert.False(t, matches)
+
+	_, err = VerifyBcryptPasswordHash("U*U", "not a hash")
+	assert.Error(t, err)
+}
+
+func TestVerifyArgon2idPasswordHash(t *testing.T) {
+	// test vector from the argon2 reference implementation
+	passwordHash := "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	matches, err := VerifyArgon2idPasswordHash("password", passwordHash)
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = VerifyArgon2idPasswordHash("differentpassword", passwordHash)
+	assert.NoError(t, err)
+	assert.False(t, matches)
+
+	_, err = VerifyArgon2idPasswordHash("password", "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.Error(t, err)
+	_, err = VerifyArgon2idPasswordHash("password", "$argon2id$v=19$m=65536,t=2$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	assert.Error(t, err)
+}
+
+func TestVerifyScryptPasswordHash(t *testing.T) {
+	// created with hashlib.scrypt in Python
+	passwordHash := "$scrypt$ln=14,r=8,p=1$c2NyeXB0c2FsdDEyMzQ1Ng$cQiRvGCqFIDl6EdJ25XLEbnubRJIcQgRy+NkNYOemmQ"
+	matches, err := VerifyScryptPasswordHash("legacyPassword1", passwordHash)
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = VerifyScryptPasswordHash("legacyPassword2", passwordHash)
+	assert.NoError(t, err)
+	assert.False(t, matches)
+
+	_, err = VerifyScryptPasswordHash("legacyPassword1", "$scrypt$ln=14,r=8$c2NyeXB0c2FsdDEyMzQ1Ng$cQiRvGCqFIDl6EdJ25XLEbnubRJIcQgRy+NkNYOemmQ")
+	assert.Error(t, err)
+}
+
+func TestVerifyPBKDF2SHA256PasswordHash(t *testing.T) {
+	// in the format of Django, created with hashlib.pbkdf2_hmac in Python
+	passwordHash := "pbkdf2_sha256$1000$seasalt$cwjfV6rMyB4JPleS6hcvA8eIXRM97iYOZWLk8XjiLrw="
+	matches, err := VerifyPBKDF2SHA256PasswordHash("legacyPassword1", passwordHash)
+	assert.NoError(t, err)
+	assert.True(t, matches)
+
+	matches, err = VerifyPBKDF2SHA256PasswordHash("legacyPassword2", passwordHash)
+	assert.NoError(t, err)
+	assert.False(t, matches)
+
+	_, err = VerifyPBKDF2SHA256PasswordHash("legacyPassword1", "pbkdf2_sha1$1000$seasalt$cwjfV6rMyB4JPleS6hcvA8eIXRM97iYOZWLk8XjiLrw=")
+	assert.Error(t, err)
+}
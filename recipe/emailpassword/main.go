@@ -59,6 +59,14 @@ func GetUserByEmailWithContext(email string, userContext supertokens.UserContext
 	return (*instance.RecipeImpl.GetUserByEmail)(email, userContext)
 }
 
+func GetUserByUsernameWithContext(username string, userContext supertokens.UserContext) (*epmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return instance.GetUserByUsername(username, userContext)
+}
+
 func CreateResetPasswordTokenWithContext(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -83,6 +91,35 @@ func UpdateEmailOrPasswordWithContext(userId string, email *string, password *st
 	return (*instance.RecipeImpl.UpdateEmailOrPassword)(userId, email, password, userContext)
 }
 
+// ImportUserWithPasswordHashWithContext creates a user that keeps signing in
+// with the password hash from a previous auth system. The hash is verified
+// using the LegacyPasswordMigration config and replaced on the first
+// successful sign in.
+func ImportUserWithPasswordHashWithContext(email string, passwordHash epmodels.LegacyPasswordHash, userContext supertokens.UserContext) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return epmodels.ImportUserWithPasswordHashResponse{}, err
+	}
+	return instance.importUserWithPasswordHash(email, passwordHash, userContext)
+}
+
+func GetLegacyPasswordMigrationStatusWithContext(userContext supertokens.UserContext) (epmodels.LegacyPasswordMigrationStatus, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return epmodels.LegacyPasswordMigrationStatus{}, err
+	}
+	return instance.getLegacyPasswordMigrationStatus(userContext)
+}
+
+// UnlockAccountWithContext removes a lock from brute force protection before it expires
+func UnlockAccountWithContext(userID string, userContext supertokens.UserContext) error {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	return instance.unlockAccount(userID, userContext)
+}
+
 func CreateEmailVerificationTokenWithContext(userID string, userContext supertokens.UserContext) (evmodels.CreateEmailVerificationTokenResponse, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -162,6 +199,10 @@ func GetUserByEmail(email string) (*epmodels.User, error) {
 	return GetUserByEmailWithContext(email, &map[string]interface{}{})
 }
 
+func GetUserByUsername(username string) (*epmodels.User, error) {
+	return GetUserByUsernameWithContext(username, &map[string]interface{}{})
+}
+
 func CreateResetPasswordToken(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
 	return CreateResetPasswordTokenWithContext(userID, &map[string]interface{}{})
 }
@@ -174,6 +215,18 @@ func UpdateEmailOrPassword(userId string, email *string, password *string) (epmo
 	return UpdateEmailOrPasswordWithContext(userId, email, password, &map[string]interface{}{})
 }
 
+func ImportUserWithPasswordHash(email string, passwordHash epmodels.LegacyPasswordHash) (epmodels.ImportUserWithPasswordHashResponse, error) {
+	return ImportUserWithPasswordHashWithContext(email, passwordHash, &map[string]interface{}{})
+}
+
+func GetLegacyPasswordMigrationStatus() (epmodels.LegacyPasswordMigrationStatus, error) {
+	return GetLegacyPasswordMigrationStatusWithContext(&map[string]interface{}{})
+}
+
+func UnlockAccount(userID string) error {
+	return UnlockAccountWithContext(userID, &map[string]interface{}{})
+}
+
 func CreateEmailVerificationToken(userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
 	return CreateEmailVerificationTokenWithContext(userID, &map[string]interface{}{})
 }
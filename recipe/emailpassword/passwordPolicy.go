@@ -0,0 +1,564 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	defaultErrors "errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	defaultPasswordPolicyMinLength = 8
+	defaultPasswordPolicyMaxLength = 100
+	breachedPasswordHashPrefixLen  = 5
+
+	minPasswordHistorySigningKeyLength = 32
+
+	passwordHistoryHashAlgorithm  = "pbkdf2_sha256"
+	passwordHistoryHashIterations = 100000
+	passwordHistorySaltLength     = 16
+	passwordHistoryKeyLength      = 32
+
+	// parts of the email or username shorter than this are too common to
+	// forbid in a password
+	minIdentifierLengthForPasswordCheck = 3
+)
+
+// ValidateAndNormalisePasswordPolicy is exported so that recipes built on top of this one can
+// apply the same policy with MakePasswordPolicyRecipeImplementation
+func ValidateAndNormalisePasswordPolicy(config *epmodels.TypeInputPasswordPolicy) (epmodels.TypeNormalisedInputPasswordPolicy, error) {
+	if config == nil {
+		return epmodels.TypeNormalisedInputPasswordPolicy{}, nil
+	}
+	passwordPolicy := epmodels.TypeNormalisedInputPasswordPolicy{
+		Enabled:                            true,
+		MinLength:                          defaultPasswordPolicyMinLength,
+		MaxLength:                          defaultPasswordPolicyMaxLength,
+		RequireLetter:                      true,
+		RequireNumber:                      true,
+		RequireLowercase:                   config.RequireLowercase,
+		RequireUppercase:                   config.RequireUppercase,
+		RequireSymbol:                      config.RequireSymbol,
+		DisallowEmailAndUsernameInPassword: config.DisallowEmailAndUsernameInPassword,
+	}
+
+	if config.MinLength != nil {
+		if *config.MinLength < 1 {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("passwordPolicy.MinLength must be at least 1")
+		}
+		passwordPolicy.MinLength = *config.MinLength
+	}
+	if config.MaxLength != nil {
+		passwordPolicy.MaxLength = *config.MaxLength
+	}
+	if passwordPolicy.MaxLength <= passwordPolicy.MinLength {
+		return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("passwordPolicy.MaxLength must be greater than passwordPolicy.MinLength")
+	}
+	if config.RequireLetter != nil {
+		passwordPolicy.RequireLetter = *config.RequireLetter
+	}
+	if config.RequireNumber != nil {
+		passwordPolicy.RequireNumber = *config.RequireNumber
+	}
+
+	if config.PasswordHistory != nil {
+		if config.PasswordHistory.Size < 1 {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("passwordPolicy.PasswordHistory.Size must be at least 1")
+		}
+		if config.PasswordHistory.GetPasswordHashes == nil || config.PasswordHistory.SetPasswordHashes == nil {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("please provide both GetPasswordHashes and SetPasswordHashes in passwordPolicy.PasswordHistory")
+		}
+		if len(config.PasswordHistory.SigningKey) < minPasswordHistorySigningKeyLength {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("passwordPolicy.PasswordHistory.SigningKey must be at least 32 bytes long")
+		}
+		passwordPolicy.PasswordHistory = config.PasswordHistory
+	}
+
+	if config.BreachedPasswords != nil {
+		if config.BreachedPasswords.HashPrefixDirectory != nil && config.BreachedPasswords.IsPasswordBreached != nil {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("please provide only one of HashPrefixDirectory or IsPasswordBreached in passwordPolicy.BreachedPasswords")
+		}
+		if config.BreachedPasswords.HashPrefixDirectory != nil {
+			directory := *config.BreachedPasswords.HashPrefixDirectory
+			info, err := os.Stat(directory)
+			if err != nil {
+				return epmodels.TypeNormalisedInputPasswordPolicy{}, err
+			}
+			if !info.IsDir() {
+				return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("passwordPolicy.BreachedPasswords.HashPrefixDirectory must be a directory")
+			}
+			passwordPolicy.IsPasswordBreached = isPasswordInHashPrefixDirectory(directory)
+		} else if config.BreachedPasswords.IsPasswordBreached != nil {
+			passwordPolicy.IsPasswordBreached = config.BreachedPasswords.IsPasswordBreached
+		} else {
+			return epmodels.TypeNormalisedInputPasswordPolicy{}, defaultErrors.New("please provide one of HashPrefixDirectory or IsPasswordBreached in passwordPolicy.BreachedPasswords")
+		}
+	}
+
+	return passwordPolicy, nil
+}
+
+// when a password policy is configured, the rules are checked by the recipe
+// functions so that all the violations can be reported together.
+func passwordPolicyFormFieldValidator(value interface{}) *string {
+	if reflect.TypeOf(value).Kind() != reflect.String {
+		msg := "Development bug: Please make sure the password field yields a string"
+		return &msg
+	}
+	return nil
+}
+
+func isPasswordInHashPrefixDirectory(directory string) func(password string, userContext supertokens.UserContext) (bool, error) {
+	return func(password string, userContext supertokens.UserContext) (bool, error) {
+		return IsPasswordInBreachedHashRange(password, func(hashPrefix string) (string, error) {
+			content, err := os.ReadFile(filepath.Join(directory, hashPrefix+".txt"))
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return string(content), err
+		})
+	}
+}
+
+// IsPasswordInBreachedHashRange checks a password against a breached password
+// list using k-anonymity: only the first five characters of the password's
+// uppercase hex SHA-1 hash are passed to getHashSuffixes, which must return the
+// matching range, one hash suffix per line with an optional ":<count>". This
+// is the format of the Have I Been Pwned range API, so it can be used to query
+// that API without sending the password or its full hash.
+func IsPasswordInBreachedHashRange(password string, getHashSuffixes func(hashPrefix string) (string, error)) (bool, error) {
+	hash := sha1.Sum([]byte(password))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+
+	hashSuffixes, err := getHashSuffixes(hexHash[:breachedPasswordHashPrefixLen])
+	if err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(hashSuffixes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix := strings.SplitN(line, ":", 2)[0]
+		if strings.EqualFold(strings.TrimSpace(suffix), hexHash[breachedPasswordHashPrefixLen:]) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// validatePasswordPolicy returns every rule of the policy that the password
+// breaks. userID is nil when the user does not exist yet, in which case the
+// password history is not checked.
+func validatePasswordPolicy(policy epmodels.TypeNormalisedInputPasswordPolicy, password string, identifiers []string, userID *string, userContext supertokens.UserContext) ([]errors.PasswordPolicyViolation, error) {
+	var violations []errors.PasswordPolicyViolation
+	if !policy.Enabled {
+		return violations, nil
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < policy.MinLength {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyMinLength,
+			Message: "Password must contain at least " + strconv.Itoa(policy.MinLength) + " characters",
+		})
+	}
+	if length >= policy.MaxLength {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyMaxLength,
+			Message: "Password's length must be lesser than " + strconv.Itoa(policy.MaxLength) + " characters",
+		})
+	}
+
+	var hasLetter, hasNumber, hasLowercase, hasUppercase, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+			if unicode.IsLower(r) {
+				hasLowercase = true
+			}
+			if unicode.IsUpper(r) {
+				hasUppercase = true
+			}
+		case unicode.IsDigit(r):
+			hasNumber = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireLetter && !hasLetter {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyLetter,
+			Message: "Password must contain at least one alphabet",
+		})
+	}
+	if policy.RequireNumber && !hasNumber {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyNumber,
+			Message: "Password must contain at least one number",
+		})
+	}
+	if policy.RequireLowercase && !hasLowercase {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyLowercase,
+			Message: "Password must contain at least one lowercase letter",
+		})
+	}
+	if policy.RequireUppercase && !hasUppercase {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyUppercase,
+			Message: "Password must contain at least one uppercase letter",
+		})
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicySymbol,
+			Message: "Password must contain at least one symbol",
+		})
+	}
+
+	if policy.DisallowEmailAndUsernameInPassword && passwordContainsIdentifier(password, identifiers) {
+		violations = append(violations, errors.PasswordPolicyViolation{
+			Rule:    errors.PasswordPolicyContainsEmailOrUsername,
+			Message: "Password must not contain your email or username",
+		})
+	}
+
+	if policy.PasswordHistory != nil && userID != nil {
+		reused, err := isPasswordInHistory(*policy.PasswordHistory, *userID, password, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if reused {
+			violations = append(violations, errors.PasswordPolicyViolation{
+				Rule:    errors.PasswordPolicyReused,
+				Message: "Password must not be one of your last " + strconv.Itoa(policy.PasswordHistory.Size) + " passwords",
+			})
+		}
+	}
+
+	if policy.IsPasswordBreached != nil {
+		breached, err := policy.IsPasswordBreached(password, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			violations = append(violations, errors.PasswordPolicyViolation{
+				Rule:    errors.PasswordPolicyBreached,
+				Message: "This password has appeared in a data breach. Please choose a different password",
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+func validatePasswordPolicyOrThrowError(policy epmodels.TypeNormalisedInputPasswordPolicy, password string, identifiers []string, userID *string, userContext supertokens.UserContext) error {
+	violations, err := validatePasswordPolicy(policy, password, identifiers, userID, userContext)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return errors.FieldError{
+		Msg: "Error in input formFields",
+		Payload: []errors.ErrorPayload{{
+			ID:         "password",
+			ErrorMsg:   violations[0].Message,
+			Violations: violations,
+		}},
+	}
+}
+
+// getPasswordPolicyIdentifiers returns the values that a password may not
+// contain: the local part of the email. The username is checked by the sign
+// up API, since the recipe functions do not know it.
+func getPasswordPolicyIdentifiers(email string) []string {
+	return []string{strings.SplitN(email, "@", 2)[0]}
+}
+
+func passwordContainsIdentifier(password string, identifiers []string) bool {
+	lowerCasePassword := strings.ToLower(password)
+	for _, identifier := range identifiers {
+		if utf8.RuneCountInString(identifier) < minIdentifierLengthForPasswordCheck {
+			continue
+		}
+		if strings.Contains(lowerCasePassword, strings.ToLower(identifier)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPasswordInHistory(history epmodels.TypeInputPasswordHistory, userID string, password string, userContext supertokens.UserContext) (bool, error) {
+	passwordHashes, err := history.GetPasswordHashes(userID, userContext)
+	if err != nil {
+		return false, err
+	}
+	if len(passwordHashes) > history.Size {
+		passwordHashes = passwordHashes[len(passwordHashes)-history.Size:]
+	}
+	for _, passwordHash := range passwordHashes {
+		if verifyPasswordHistoryHash(password, passwordHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addPasswordToHistory stores the hash of the user's new password, keeping
+// only the most recent history.Size hashes, oldest first.
+func addPasswordToHistory(policy epmodels.TypeNormalisedInputPasswordPolicy, userID string, password string, userContext supertokens.UserContext) error {
+	if !policy.Enabled || policy.PasswordHistory == nil {
+		return nil
+	}
+	history := policy.PasswordHistory
+	passwordHashes, err := history.GetPasswordHashes(userID, userContext)
+	if err != nil {
+		return err
+	}
+	passwordHash, err := hashPasswordForHistory(password)
+	if err != nil {
+		return err
+	}
+	passwordHashes = append(passwordHashes, passwordHash)
+	if len(passwordHashes) > history.Size {
+		passwordHashes = passwordHashes[len(passwordHashes)-history.Size:]
+	}
+	return history.SetPasswordHashes(userID, passwordHashes, userContext)
+}
+
+// password history hashes are of the form pbkdf2_sha256$<iterations>$<salt>$<hash>
+// with the salt and hash base64 encoded.
+func hashPasswordForHistory(password string) (string, error) {
+	salt := make([]byte, passwordHistorySaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	key := pbkdf2.Key([]byte(password), salt, passwordHistoryHashIterations, passwordHistoryKeyLength, sha256.New)
+	return strings.Join([]string{
+		passwordHistoryHashAlgorithm,
+		strconv.Itoa(passwordHistoryHashIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+func verifyPasswordHistoryHash(password string, passwordHash string) bool {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 4 || parts[0] != passwordHistoryHashAlgorithm {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expectedKey, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(expectedKey) == 0 {
+		return false
+	}
+	key := pbkdf2.Key([]byte(password), salt, iterations, len(expectedKey), sha256.New)
+	return hmac.Equal(key, expectedKey)
+}
+
+// signPasswordResetToken adds the ID of the user to a password reset token
+// created by the core, signed with the signing key of the password history.
+// This lets the password history of the user be checked before the token is
+// consumed.
+func signPasswordResetToken(history epmodels.TypeInputPasswordHistory, token string, userID string) string {
+	payload := token + "." + base64.RawURLEncoding.EncodeToString([]byte(userID))
+	return payload + "." + signPasswordResetTokenPayload(history.SigningKey, payload)
+}
+
+// parsePasswordResetToken returns the token created by the core and the ID of
+// the user from a token signed by signPasswordResetToken. ok is false if the
+// token is not signed with the signing key of the password history.
+func parsePasswordResetToken(history epmodels.TypeInputPasswordHistory, token string) (coreToken string, userID string, ok bool) {
+	signatureIndex := strings.LastIndex(token, ".")
+	if signatureIndex == -1 {
+		return "", "", false
+	}
+	payload := token[:signatureIndex]
+	if !hmac.Equal([]byte(token[signatureIndex+1:]), []byte(signPasswordResetTokenPayload(history.SigningKey, payload))) {
+		return "", "", false
+	}
+	userIDIndex := strings.LastIndex(payload, ".")
+	if userIDIndex == -1 {
+		return "", "", false
+	}
+	userIDBytes, err := base64.RawURLEncoding.DecodeString(payload[userIDIndex+1:])
+	if err != nil || len(userIDBytes) == 0 {
+		return "", "", false
+	}
+	return payload[:userIDIndex], string(userIDBytes), true
+}
+
+func signPasswordResetTokenPayload(signingKey []byte, payload string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// MakePasswordPolicyRecipeImplementation checks every password that is set
+// against the policy, and keeps the password history of the users. A password
+// that breaks the policy is rejected with an errors.FieldError for the
+// password field. With a password history, the ID of the user is signed into
+// password reset tokens, so that their history can be checked before the
+// token is consumed by the core. Tokens that are not signed with the signing
+// key are rejected. It is exported so that recipes built on top of this one
+// can apply the same policy to their email password users.
+func MakePasswordPolicyRecipeImplementation(originalImplementation epmodels.RecipeInterface, policy epmodels.TypeNormalisedInputPasswordPolicy) epmodels.RecipeInterface {
+	originalSignUp := *originalImplementation.SignUp
+	originalCreateResetPasswordToken := *originalImplementation.CreateResetPasswordToken
+	originalResetPasswordUsingToken := *originalImplementation.ResetPasswordUsingToken
+	originalUpdateEmailOrPassword := *originalImplementation.UpdateEmailOrPassword
+
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		err := validatePasswordPolicyOrThrowError(policy, password, getPasswordPolicyIdentifiers(email), nil, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		response, err := originalSignUp(email, password, userContext)
+		if err != nil {
+			return epmodels.SignUpResponse{}, err
+		}
+		if response.OK != nil {
+			err = addPasswordToHistory(policy, response.OK.User.ID, password, userContext)
+			if err != nil {
+				return epmodels.SignUpResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	createResetPasswordToken := func(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
+		response, err := originalCreateResetPasswordToken(userID, userContext)
+		if err != nil {
+			return epmodels.CreateResetPasswordTokenResponse{}, err
+		}
+		if response.OK != nil && policy.PasswordHistory != nil {
+			response.OK.Token = signPasswordResetToken(*policy.PasswordHistory, response.OK.Token, userID)
+		}
+		return response, nil
+	}
+
+	resetPasswordUsingToken := func(token string, newPassword string, userContext supertokens.UserContext) (epmodels.ResetPasswordUsingTokenResponse, error) {
+		// without a password history, the user is only known once the token
+		// is consumed, so only the rules that do not depend on them are checked
+		var (
+			userID      *string
+			identifiers []string
+		)
+		if policy.PasswordHistory != nil {
+			coreToken, tokenUserID, ok := parsePasswordResetToken(*policy.PasswordHistory, token)
+			if !ok {
+				return epmodels.ResetPasswordUsingTokenResponse{
+					ResetPasswordInvalidTokenError: &struct{}{},
+				}, nil
+			}
+			token = coreToken
+			userID = &tokenUserID
+			user, err := (*originalImplementation.GetUserByID)(tokenUserID, userContext)
+			if err != nil {
+				return epmodels.ResetPasswordUsingTokenResponse{}, err
+			}
+			if user != nil {
+				identifiers = getPasswordPolicyIdentifiers(user.Email)
+			}
+		}
+		err := validatePasswordPolicyOrThrowError(policy, newPassword, identifiers, userID, userContext)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		response, err := originalResetPasswordUsingToken(token, newPassword, userContext)
+		if err != nil {
+			return epmodels.ResetPasswordUsingTokenResponse{}, err
+		}
+		if response.OK != nil && userID != nil {
+			err = addPasswordToHistory(policy, *userID, newPassword, userContext)
+			if err != nil {
+				return epmodels.ResetPasswordUsingTokenResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	updateEmailOrPassword := func(userId string, email *string, password *string, userContext supertokens.UserContext) (epmodels.UpdateEmailOrPasswordResponse, error) {
+		if password == nil {
+			return originalUpdateEmailOrPassword(userId, email, password, userContext)
+		}
+		var identifiers []string
+		if email != nil {
+			identifiers = getPasswordPolicyIdentifiers(*email)
+		} else {
+			user, err := (*originalImplementation.GetUserByID)(userId, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+			if user != nil {
+				identifiers = getPasswordPolicyIdentifiers(user.Email)
+			}
+		}
+		err := validatePasswordPolicyOrThrowError(policy, *password, identifiers, &userId, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		response, err := originalUpdateEmailOrPassword(userId, email, password, userContext)
+		if err != nil {
+			return epmodels.UpdateEmailOrPasswordResponse{}, err
+		}
+		if response.OK != nil {
+			err = addPasswordToHistory(policy, userId, *password, userContext)
+			if err != nil {
+				return epmodels.UpdateEmailOrPasswordResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	*originalImplementation.SignUp = signUp
+	*originalImplementation.CreateResetPasswordToken = createResetPasswordToken
+	*originalImplementation.ResetPasswordUsingToken = resetPasswordUsingToken
+	*originalImplementation.UpdateEmailOrPassword = updateEmailOrPassword
+	return originalImplementation
+}
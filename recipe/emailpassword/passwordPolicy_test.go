@@ -0,0 +1,339 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestPasswordPolicyConfigDefaults(t *testing.T) {
+	policy, err := ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{})
+	assert.NoError(t, err)
+	assert.True(t, policy.Enabled)
+	assert.Equal(t, 8, policy.MinLength)
+	assert.Equal(t, 100, policy.MaxLength)
+	assert.True(t, policy.RequireLetter)
+	assert.True(t, policy.RequireNumber)
+	assert.False(t, policy.RequireSymbol)
+	assert.Nil(t, policy.PasswordHistory)
+	assert.Nil(t, policy.IsPasswordBreached)
+}
+
+func TestPasswordPolicyConfigValidation(t *testing.T) {
+	minLength := 12
+	maxLength := 10
+	_, err := ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		MinLength: &minLength,
+		MaxLength: &maxLength,
+	})
+	assert.Error(t, err)
+
+	_, err = ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		PasswordHistory: &epmodels.TypeInputPasswordHistory{Size: 3},
+	})
+	assert.Error(t, err)
+
+	_, err = ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		PasswordHistory: &epmodels.TypeInputPasswordHistory{
+			Size: 3,
+			GetPasswordHashes: func(userID string, userContext supertokens.UserContext) ([]string, error) {
+				return nil, nil
+			},
+			SetPasswordHashes: func(userID string, passwordHashes []string, userContext supertokens.UserContext) error {
+				return nil
+			},
+			SigningKey: []byte("too short"),
+		},
+	})
+	assert.EqualError(t, err, "passwordPolicy.PasswordHistory.SigningKey must be at least 32 bytes long")
+
+	missingDirectory := filepath.Join(t.TempDir(), "missing")
+	_, err = ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		BreachedPasswords: &epmodels.TypeInputBreachedPasswords{HashPrefixDirectory: &missingDirectory},
+	})
+	assert.Error(t, err)
+
+	_, err = ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		BreachedPasswords: &epmodels.TypeInputBreachedPasswords{},
+	})
+	assert.Error(t, err)
+}
+
+func TestPasswordPolicyReplacesDefaultPasswordValidator(t *testing.T) {
+	policy, err := ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{})
+	assert.NoError(t, err)
+	signUpConfig := validateAndNormaliseSignupConfig(nil, policy)
+	for _, formField := range signUpConfig.FormFields {
+		if formField.ID == "password" {
+			assert.Nil(t, formField.Validate("asd"))
+		}
+	}
+
+	signUpConfig = validateAndNormaliseSignupConfig(nil, epmodels.TypeNormalisedInputPasswordPolicy{})
+	for _, formField := range signUpConfig.FormFields {
+		if formField.ID == "password" {
+			assert.NotNil(t, formField.Validate("asd"))
+		}
+	}
+}
+
+func TestBreachedPasswordHashPrefixDirectory(t *testing.T) {
+	directory := t.TempDir()
+	hash := sha1.Sum([]byte("password1"))
+	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	content := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n" + hexHash[5:] + ":2254650\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(directory, hexHash[:5]+".txt"), []byte(content), 0600))
+
+	policy, err := ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		BreachedPasswords: &epmodels.TypeInputBreachedPasswords{HashPrefixDirectory: &directory},
+	})
+	assert.NoError(t, err)
+
+	breached, err := policy.IsPasswordBreached("password1", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = policy.IsPasswordBreached("correct horse battery staple 42", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestIsPasswordInBreachedHashRangeOnlySendsPrefix(t *testing.T) {
+	var requestedPrefix string
+	breached, err := IsPasswordInBreachedHashRange("password", func(hashPrefix string) (string, error) {
+		requestedPrefix = hashPrefix
+		return "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n", nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, breached)
+	assert.Equal(t, "5BAA6", requestedPrefix)
+}
+
+func getViolatedRules(violations []errors.PasswordPolicyViolation) []string {
+	rules := []string{}
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicyReportsAllViolations(t *testing.T) {
+	policy := epmodels.TypeNormalisedInputPasswordPolicy{
+		Enabled:                            true,
+		MinLength:                          10,
+		MaxLength:                          100,
+		RequireLetter:                      true,
+		RequireNumber:                      true,
+		RequireUppercase:                   true,
+		RequireSymbol:                      true,
+		DisallowEmailAndUsernameInPassword: true,
+		IsPasswordBreached: func(password string, userContext supertokens.UserContext) (bool, error) {
+			return password == "johnny", nil
+		},
+	}
+
+	violations, err := validatePasswordPolicy(policy, "johnny", []string{"johnny"}, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		errors.PasswordPolicyMinLength,
+		errors.PasswordPolicyNumber,
+		errors.PasswordPolicyUppercase,
+		errors.PasswordPolicySymbol,
+		errors.PasswordPolicyContainsEmailOrUsername,
+		errors.PasswordPolicyBreached,
+	}, getViolatedRules(violations))
+
+	violations, err = validatePasswordPolicy(policy, "Tr0ub4dor&3x", []string{"johnny"}, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	err = validatePasswordPolicyOrThrowError(policy, "johnny", nil, nil, &map[string]interface{}{})
+	fieldError, ok := err.(errors.FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "password", fieldError.Payload[0].ID)
+	assert.Equal(t, fieldError.Payload[0].Violations[0].Message, fieldError.Payload[0].ErrorMsg)
+	assert.Len(t, fieldError.Payload[0].Violations, 5)
+}
+
+func TestPasswordPolicyCountsCharactersNotBytes(t *testing.T) {
+	policy := epmodels.TypeNormalisedInputPasswordPolicy{
+		Enabled:   true,
+		MinLength: 8,
+		MaxLength: 10,
+	}
+	violations, err := validatePasswordPolicy(policy, "pässwörd", nil, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestPasswordPolicyIgnoresShortIdentifiers(t *testing.T) {
+	assert.False(t, passwordContainsIdentifier("ab12345678", []string{"ab"}))
+	assert.True(t, passwordContainsIdentifier("xxJohnDoe99", getPasswordPolicyIdentifiers("johndoe@example.com")))
+}
+
+func TestPasswordHistory(t *testing.T) {
+	stored := map[string][]string{}
+	policy := epmodels.TypeNormalisedInputPasswordPolicy{
+		Enabled:   true,
+		MinLength: 1,
+		MaxLength: 100,
+		PasswordHistory: &epmodels.TypeInputPasswordHistory{
+			Size: 2,
+			GetPasswordHashes: func(userID string, userContext supertokens.UserContext) ([]string, error) {
+				return stored[userID], nil
+			},
+			SetPasswordHashes: func(userID string, passwordHashes []string, userContext supertokens.UserContext) error {
+				stored[userID] = passwordHashes
+				return nil
+			},
+		},
+	}
+	userID := "user1"
+	userContext := &map[string]interface{}{}
+
+	for _, password := range []string{"first", "second", "third"} {
+		assert.NoError(t, addPasswordToHistory(policy, userID, password, userContext))
+	}
+	assert.Len(t, stored[userID], 2)
+	assert.NotContains(t, stored[userID][0], "second")
+
+	violations, err := validatePasswordPolicy(policy, "third", nil, &userID, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{errors.PasswordPolicyReused}, getViolatedRules(violations))
+
+	violations, err = validatePasswordPolicy(policy, "first", nil, &userID, userContext)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+
+	// the history is not checked for users that do not exist yet
+	violations, err = validatePasswordPolicy(policy, "third", nil, nil, userContext)
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestVerifyPasswordHistoryHash(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vector from RFC 7914, section 11
+	key, err := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+	assert.NoError(t, err)
+	passwordHash := "pbkdf2_sha256$1$" + base64.RawStdEncoding.EncodeToString([]byte("salt")) + "$" + base64.RawStdEncoding.EncodeToString(key)
+	assert.True(t, verifyPasswordHistoryHash("passwd", passwordHash))
+	assert.False(t, verifyPasswordHistoryHash("password", passwordHash))
+
+	passwordHash, err = hashPasswordForHistory("passwd")
+	assert.NoError(t, err)
+	assert.True(t, verifyPasswordHistoryHash("passwd", passwordHash))
+}
+
+func TestPasswordResetTokenSigning(t *testing.T) {
+	history := epmodels.TypeInputPasswordHistory{SigningKey: []byte("0123456789abcdef0123456789abcdef")}
+	token := signPasswordResetToken(history, "coreToken", "user1")
+
+	coreToken, userID, ok := parsePasswordResetToken(history, token)
+	assert.True(t, ok)
+	assert.Equal(t, "coreToken", coreToken)
+	assert.Equal(t, "user1", userID)
+
+	// the user ID cannot be changed without the signing key
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("user2")) + "." + parts[2]
+	_, _, ok = parsePasswordResetToken(history, forged)
+	assert.False(t, ok)
+	_, _, ok = parsePasswordResetToken(history, "coreToken")
+	assert.False(t, ok)
+	_, _, ok = parsePasswordResetToken(epmodels.TypeInputPasswordHistory{SigningKey: []byte("fedcba9876543210fedcba9876543210")}, token)
+	assert.False(t, ok)
+}
+
+func TestPasswordPolicyIsCheckedByTheRecipeFunctions(t *testing.T) {
+	passwordHashes := map[string][]string{}
+	policy, err := ValidateAndNormalisePasswordPolicy(&epmodels.TypeInputPasswordPolicy{
+		DisallowEmailAndUsernameInPassword: true,
+		PasswordHistory: &epmodels.TypeInputPasswordHistory{
+			Size: 2,
+			GetPasswordHashes: func(userID string, userContext supertokens.UserContext) ([]string, error) {
+				return passwordHashes[userID], nil
+			},
+			SetPasswordHashes: func(userID string, hashes []string, userContext supertokens.UserContext) error {
+				passwordHashes[userID] = hashes
+				return nil
+			},
+			SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+		},
+	})
+	assert.NoError(t, err)
+	users := map[string]*inMemoryEmailPasswordUser{}
+	recipeImplementation := MakePasswordPolicyRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), policy)
+	userContext := &map[string]interface{}{}
+
+	getViolatedRulesOfError := func(err error) []string {
+		fieldError, ok := err.(errors.FieldError)
+		assert.True(t, ok)
+		assert.Equal(t, "password", fieldError.Payload[0].ID)
+		return getViolatedRules(fieldError.Payload[0].Violations)
+	}
+
+	_, err = (*recipeImplementation.SignUp)("johndoe@example.com", "johndoe", userContext)
+	assert.Equal(t, []string{errors.PasswordPolicyMinLength, errors.PasswordPolicyNumber, errors.PasswordPolicyContainsEmailOrUsername}, getViolatedRulesOfError(err))
+	assert.Empty(t, users)
+
+	signUpResponse, err := (*recipeImplementation.SignUp)("johndoe@example.com", "validpass123", userContext)
+	assert.NoError(t, err)
+	userID := signUpResponse.OK.User.ID
+	assert.Len(t, passwordHashes[userID], 1)
+
+	oldPassword := "validpass123"
+	_, err = (*recipeImplementation.UpdateEmailOrPassword)(userID, nil, &oldPassword, userContext)
+	assert.Equal(t, []string{errors.PasswordPolicyReused}, getViolatedRulesOfError(err))
+	passwordWithEmail := "johndoe1234"
+	_, err = (*recipeImplementation.UpdateEmailOrPassword)(userID, nil, &passwordWithEmail, userContext)
+	assert.Equal(t, []string{errors.PasswordPolicyContainsEmailOrUsername}, getViolatedRulesOfError(err))
+	newPassword := "newpass123"
+	updateResponse, err := (*recipeImplementation.UpdateEmailOrPassword)(userID, nil, &newPassword, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, updateResponse.OK)
+	assert.Equal(t, "newpass123", users["johndoe@example.com"].password)
+	assert.Len(t, passwordHashes[userID], 2)
+
+	tokenResponse, err := (*recipeImplementation.CreateResetPasswordToken)(userID, userContext)
+	assert.NoError(t, err)
+	token := tokenResponse.OK.Token
+	assert.NotEqual(t, "token-"+userID, token)
+
+	_, err = (*recipeImplementation.ResetPasswordUsingToken)(token, "validpass123", userContext)
+	assert.Equal(t, []string{errors.PasswordPolicyReused}, getViolatedRulesOfError(err))
+	resetResponse, err := (*recipeImplementation.ResetPasswordUsingToken)("token-"+userID, "resetpass123", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.ResetPasswordInvalidTokenError)
+	resetResponse, err = (*recipeImplementation.ResetPasswordUsingToken)(token, "resetpass123", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.OK)
+	assert.Equal(t, "resetpass123", users["johndoe@example.com"].password)
+
+	// the oldest password is no longer in the history
+	_, err = (*recipeImplementation.UpdateEmailOrPassword)(userID, nil, &oldPassword, userContext)
+	assert.NoError(t, err)
+}
@@ -429,3 +429,101 @@ func TestValidTokenInputAndPasswordHasChanged(t *testing.T) {
 	assert.Equal(t, userInfo["id"], result3["user"].(map[string]interface{})["id"].(string))
 	assert.Equal(t, userInfo["email"], result3["user"].(map[string]interface{})["email"].(string))
 }
+
+func TestPasswordResetChecksPasswordHistory(t *testing.T) {
+	token := ""
+	passwordHashes := map[string][]string{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				PasswordPolicy: &epmodels.TypeInputPasswordPolicy{
+					PasswordHistory: &epmodels.TypeInputPasswordHistory{
+						Size: 3,
+						GetPasswordHashes: func(userID string, userContext supertokens.UserContext) ([]string, error) {
+							return passwordHashes[userID], nil
+						},
+						SetPasswordHashes: func(userID string, hashes []string, userContext supertokens.UserContext) error {
+							passwordHashes[userID] = hashes
+							return nil
+						},
+						SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+					},
+				},
+				ResetPasswordUsingTokenFeature: &epmodels.TypeInputResetPasswordUsingTokenFeature{
+					CreateAndSendCustomEmail: func(user epmodels.User, passwordResetURLWithToken string, userContext supertokens.UserContext) {
+						token = strings.Split(strings.Split(strings.Split(passwordResetURLWithToken, "?")[1], "&")[0], "=")[1]
+					},
+				},
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	res, err := unittesting.SignupRequest("random@gmail.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	postBody, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]string{{"id": "email", "value": "random@gmail.com"}},
+	})
+	assert.NoError(t, err)
+	_, err = http.Post(testServer.URL+"/auth/user/password/reset/token", "application/json", bytes.NewBuffer(postBody))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	resetPassword := func(token string, password string) map[string]interface{} {
+		postBody, err := json.Marshal(map[string]interface{}{
+			"formFields": []map[string]string{{"id": "password", "value": password}},
+			"token":      token,
+		})
+		assert.NoError(t, err)
+		res, err := http.Post(testServer.URL+"/auth/user/password/reset", "application/json", bytes.NewBuffer(postBody))
+		assert.NoError(t, err)
+		var result map[string]interface{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		res.Body.Close()
+		return result
+	}
+
+	// the password the user signed up with is in their history
+	result := resetPassword(token, "validpass123")
+	assert.Equal(t, "FIELD_ERROR", result["status"])
+	formField := result["formFields"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "password", formField["id"])
+	assert.Equal(t, "Password must not be one of your last 3 passwords", formField["error"])
+
+	// the token created by the core is not accepted without the signed user ID
+	coreToken := token[:strings.Index(token, ".")]
+	assert.Equal(t, "RESET_PASSWORD_INVALID_TOKEN_ERROR", resetPassword(coreToken, "validpass12345")["status"])
+
+	// the rejected reset did not consume the token
+	assert.Equal(t, "OK", resetPassword(token, "validpass12345")["status"])
+	res, err = unittesting.SignInRequest("random@gmail.com", "validpass12345", testServer.URL)
+	assert.NoError(t, err)
+	var signInResult map[string]interface{}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&signInResult))
+	res.Body.Close()
+	assert.Equal(t, "OK", signInResult["status"])
+	for _, hashes := range passwordHashes {
+		assert.Len(t, hashes, 2)
+	}
+}
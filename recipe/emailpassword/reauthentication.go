@@ -0,0 +1,57 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/api"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// VerifyPasswordForReauthentication is a ReauthenticationVerifier for the session recipe's AccountDeletion config.
+// It expects the current password of the user in the "password" field of the request body. With BruteForceProtection,
+// wrong passwords count towards locking the account, and a locked account cannot reauthenticate.
+func VerifyPasswordForReauthentication() sessmodels.ReauthenticationVerifier {
+	return func(userID string, reauthentication map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
+		password, ok := reauthentication["password"].(string)
+		if !ok || password == "" {
+			return false, nil
+		}
+		instance, err := getRecipeInstanceOrThrowError()
+		if err != nil {
+			return false, err
+		}
+		user, err := (*instance.RecipeImpl.GetUserByID)(userID, userContext)
+		if err != nil {
+			return false, err
+		}
+		if user == nil {
+			return false, nil
+		}
+		// there is no request here, so failed attempts only count towards the account
+		options := epmodels.APIOptions{
+			Config:               instance.Config,
+			RecipeID:             instance.RecipeModule.GetRecipeID(),
+			RecipeImplementation: instance.RecipeImpl,
+		}
+		response, locked, err := api.SignInWithBruteForceProtection(options, user.Email, password, userContext)
+		if err != nil || locked {
+			return false, err
+		}
+		return response.OK != nil && response.OK.User.ID == userID, nil
+	}
+}
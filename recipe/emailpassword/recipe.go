@@ -47,10 +47,26 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	if err != nil {
 		return Recipe{}, err
 	}
-	verifiedConfig := validateAndNormaliseUserInput(r, appInfo, config)
+	verifiedConfig, err := validateAndNormaliseUserInput(r, appInfo, config)
+	if err != nil {
+		return Recipe{}, err
+	}
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
-	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance))
+	recipeImplementation := MakeRecipeImplementation(*querierInstance)
+	if verifiedConfig.LegacyPasswordMigration != nil {
+		recipeImplementation = makeLegacyPasswordMigrationRecipeImplementation(recipeImplementation, verifiedConfig.LegacyPasswordMigration)
+	}
+	if verifiedConfig.EmailPolicy.Enabled {
+		recipeImplementation = MakeEmailPolicyRecipeImplementation(recipeImplementation, verifiedConfig.EmailPolicy)
+	}
+	if verifiedConfig.UsernameFeature.Enabled {
+		recipeImplementation = MakeUsernameRecipeImplementation(recipeImplementation, verifiedConfig.UsernameFeature)
+	}
+	if verifiedConfig.PasswordPolicy.Enabled {
+		recipeImplementation = MakePasswordPolicyRecipeImplementation(recipeImplementation, verifiedConfig.PasswordPolicy)
+	}
+	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 
 	if emailVerificationInstance == nil {
 		emailVerificationRecipe, err := emailverification.MakeRecipe(recipeId, appInfo, verifiedConfig.EmailVerificationFeature, onGeneralError)
@@ -110,6 +126,30 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	signupUsernameExistsAPI, err := supertokens.NewNormalisedURLPath(constants.SignupUsernameExistsAPI)
+	if err != nil {
+		return nil, err
+	}
+	changePasswordAPI, err := supertokens.NewNormalisedURLPath(constants.ChangePasswordAPI)
+	if err != nil {
+		return nil, err
+	}
+	emailChangeAPI, err := supertokens.NewNormalisedURLPath(constants.EmailChangeAPI)
+	if err != nil {
+		return nil, err
+	}
+	emailChangeVerifyAPI, err := supertokens.NewNormalisedURLPath(constants.EmailChangeVerifyAPI)
+	if err != nil {
+		return nil, err
+	}
+	emailChangeRevertAPI, err := supertokens.NewNormalisedURLPath(constants.EmailChangeRevertAPI)
+	if err != nil {
+		return nil, err
+	}
+	unlockAccountAPI, err := supertokens.NewNormalisedURLPath(constants.UnlockAccountAPI)
+	if err != nil {
+		return nil, err
+	}
 	emailverificationAPIhandled, err := r.EmailVerificationRecipe.RecipeModule.GetAPIsHandled()
 	if err != nil {
 		return nil, err
@@ -139,6 +179,36 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: signupEmailExistsAPI,
 		ID:                     constants.SignupEmailExistsAPI,
 		Disabled:               r.APIImpl.EmailExistsGET == nil,
+	}, {
+		Method:                 http.MethodGet,
+		PathWithoutAPIBasePath: signupUsernameExistsAPI,
+		ID:                     constants.SignupUsernameExistsAPI,
+		Disabled:               r.APIImpl.UsernameExistsGET == nil || !r.Config.UsernameFeature.Enabled,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: changePasswordAPI,
+		ID:                     constants.ChangePasswordAPI,
+		Disabled:               r.APIImpl.ChangePasswordPOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: emailChangeAPI,
+		ID:                     constants.EmailChangeAPI,
+		Disabled:               r.APIImpl.EmailChangePOST == nil || !r.Config.EmailChangeFeature.Enabled,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: emailChangeVerifyAPI,
+		ID:                     constants.EmailChangeVerifyAPI,
+		Disabled:               r.APIImpl.EmailChangeVerifyPOST == nil || !r.Config.EmailChangeFeature.Enabled,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: emailChangeRevertAPI,
+		ID:                     constants.EmailChangeRevertAPI,
+		Disabled:               r.APIImpl.EmailChangeRevertPOST == nil || !r.Config.EmailChangeFeature.Enabled,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: unlockAccountAPI,
+		ID:                     constants.UnlockAccountAPI,
+		Disabled:               r.APIImpl.UnlockAccountPOST == nil || !r.Config.BruteForceProtection.Enabled,
 	}}, emailverificationAPIhandled...), nil
 }
 
@@ -162,6 +232,18 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.PasswordReset(r.APIImpl, options)
 	} else if id == constants.SignupEmailExistsAPI {
 		return api.EmailExists(r.APIImpl, options)
+	} else if id == constants.SignupUsernameExistsAPI {
+		return api.UsernameExists(r.APIImpl, options)
+	} else if id == constants.ChangePasswordAPI {
+		return api.ChangePassword(r.APIImpl, options)
+	} else if id == constants.EmailChangeAPI {
+		return api.EmailChange(r.APIImpl, options)
+	} else if id == constants.EmailChangeVerifyAPI {
+		return api.EmailChangeVerify(r.APIImpl, options)
+	} else if id == constants.EmailChangeRevertAPI {
+		return api.EmailChangeRevert(r.APIImpl, options)
+	} else if id == constants.UnlockAccountAPI {
+		return api.UnlockAccountAPI(r.APIImpl, options)
 	}
 	return r.EmailVerificationRecipe.RecipeModule.HandleAPIRequest(id, req, res, theirHandler, path, method)
 }
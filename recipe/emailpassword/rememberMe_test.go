@@ -0,0 +1,102 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func postFormFieldsWithRememberMe(testUrl string, path string, email string, password string, rememberMe bool) (*http.Response, error) {
+	postBody, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "email", "value": email},
+			{"id": "password", "value": password},
+		},
+		"rememberMe": rememberMe,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return http.Post(testUrl+path, "application/json", bytes.NewBuffer(postBody))
+}
+
+func getAccessTokenCookie(resp *http.Response) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "sAccessToken" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestRememberMeIsUsedBySignUpAndSignIn(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(&sessmodels.TypeInput{
+				RememberMe: &sessmodels.RememberMeInputConfig{},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	resp, err := postFormFieldsWithRememberMe(testServer.URL, "/auth/signup", "johndoe@supertokens.io", "validpass123", false)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	accessTokenCookie := getAccessTokenCookie(resp)
+	assert.NotNil(t, accessTokenCookie)
+	assert.Equal(t, "", accessTokenCookie.RawExpires)
+
+	resp, err = postFormFieldsWithRememberMe(testServer.URL, "/auth/signin", "johndoe@supertokens.io", "validpass123", false)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	accessTokenCookie = getAccessTokenCookie(resp)
+	assert.NotNil(t, accessTokenCookie)
+	assert.Equal(t, "", accessTokenCookie.RawExpires)
+
+	resp, err = postFormFieldsWithRememberMe(testServer.URL, "/auth/signin", "johndoe@supertokens.io", "validpass123", true)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	accessTokenCookie = getAccessTokenCookie(resp)
+	assert.NotNil(t, accessTokenCookie)
+	assert.NotEqual(t, "", accessTokenCookie.RawExpires)
+}
@@ -0,0 +1,43 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+)
+
+func validateSignUpFormFieldsStorageConfig(config *epmodels.TypeInputSignUp) error {
+	for _, formField := range config.FormFields {
+		if formField.Storage == nil {
+			continue
+		}
+		if formField.ID == "password" {
+			return errors.New("the password form field cannot be stored")
+		}
+		switch *formField.Storage {
+		case epmodels.FormFieldStorageUserMetadata:
+			if config.UpdateUserMetadata == nil {
+				return errors.New("updateUserMetadata must be provided to store the " + formField.ID + " form field in the user metadata")
+			}
+		case epmodels.FormFieldStorageSessionData, epmodels.FormFieldStorageAccessTokenPayload:
+		default:
+			return errors.New("storage of the " + formField.ID + " form field must be one of USER_METADATA, SESSION_DATA or ACCESS_TOKEN_PAYLOAD")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSignUpFormFieldsStorageConfig(t *testing.T) {
+	userMetadata := epmodels.FormFieldStorageUserMetadata
+	accessTokenPayload := epmodels.FormFieldStorageAccessTokenPayload
+	unknown := "DATABASE"
+	updateUserMetadata := func(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) error {
+		return nil
+	}
+
+	assert.NoError(t, validateSignUpFormFieldsStorageConfig(&epmodels.TypeInputSignUp{
+		FormFields: []epmodels.TypeInputFormField{
+			{ID: "name", Storage: &userMetadata},
+			{ID: "plan", Storage: &accessTokenPayload},
+			{ID: "company"},
+		},
+		UpdateUserMetadata: updateUserMetadata,
+	}))
+	assert.Error(t, validateSignUpFormFieldsStorageConfig(&epmodels.TypeInputSignUp{
+		FormFields: []epmodels.TypeInputFormField{{ID: "name", Storage: &userMetadata}},
+	}))
+	assert.Error(t, validateSignUpFormFieldsStorageConfig(&epmodels.TypeInputSignUp{
+		FormFields: []epmodels.TypeInputFormField{{ID: "name", Storage: &unknown}},
+	}))
+	assert.Error(t, validateSignUpFormFieldsStorageConfig(&epmodels.TypeInputSignUp{
+		FormFields: []epmodels.TypeInputFormField{{ID: "password", Storage: &accessTokenPayload}},
+	}))
+}
+
+func TestSignUpFormFieldsStorageIsNormalised(t *testing.T) {
+	accessTokenPayload := epmodels.FormFieldStorageAccessTokenPayload
+	acceptsJSONValuesTrue := true
+	acceptsJSONValuesFalse := false
+	signUpConfig := validateAndNormaliseSignupConfig(&epmodels.TypeInputSignUp{
+		FormFields: []epmodels.TypeInputFormField{
+			{ID: "plan", Storage: &accessTokenPayload},
+			{ID: "seats", Storage: &accessTokenPayload, AcceptsJSONValues: &acceptsJSONValuesFalse},
+			{ID: "company"},
+			{ID: "age", AcceptsJSONValues: &acceptsJSONValuesTrue},
+			{ID: "email", AcceptsJSONValues: &acceptsJSONValuesTrue},
+		},
+	}, epmodels.TypeNormalisedInputPasswordPolicy{})
+
+	storage := map[string]string{}
+	acceptsJSONValues := map[string]bool{}
+	for _, formField := range signUpConfig.FormFields {
+		storage[formField.ID] = formField.Storage
+		acceptsJSONValues[formField.ID] = formField.AcceptsJSONValues
+	}
+	assert.Equal(t, map[string]bool{
+		"plan":     true,
+		"seats":    false,
+		"company":  false,
+		"age":      true,
+		"password": false,
+		"email":    false,
+	}, acceptsJSONValues)
+	assert.Equal(t, map[string]string{
+		"plan":     epmodels.FormFieldStorageAccessTokenPayload,
+		"seats":    epmodels.FormFieldStorageAccessTokenPayload,
+		"company":  "",
+		"age":      "",
+		"password": "",
+		"email":    "",
+	}, storage)
+}
+
+func TestSignUpAPIOnlyAcceptsJSONValuesForFieldsThatOptIn(t *testing.T) {
+	accessTokenPayload := epmodels.FormFieldStorageAccessTokenPayload
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				SignUpFeature: &epmodels.TypeInputSignUp{
+					FormFields: []epmodels.TypeInputFormField{
+						{ID: "marketingOptIn", Storage: &accessTokenPayload},
+						{ID: "company"},
+					},
+				},
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	signUp := func(email string, company interface{}) map[string]interface{} {
+		response, err := postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+			"formFields": []map[string]interface{}{
+				{"id": "email", "value": email},
+				{"id": "password", "value": "validpass123"},
+				{"id": "marketingOptIn", "value": true},
+				{"id": "company", "value": company},
+			},
+		}, nil)
+		assert.NoError(t, err)
+		return response
+	}
+
+	response := signUp("random@gmail.com", map[string]interface{}{"name": "SuperTokens"})
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "company", "error": "Field must be a string"},
+	}, response["formFields"])
+
+	response = signUp("random@gmail.com", nil)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "company", "error": "Field is not optional"},
+	}, response["formFields"])
+
+	response = signUp("random@gmail.com", "SuperTokens")
+	assert.Equal(t, "OK", response["status"])
+	userID := response["user"].(map[string]interface{})["id"].(string)
+
+	sessionHandles, err := session.GetAllSessionHandlesForUser(userID)
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 1)
+	sessionInformation, err := session.GetSessionInformation(sessionHandles[0])
+	assert.NoError(t, err)
+	assert.Equal(t, true, sessionInformation.AccessTokenPayload["marketingOptIn"])
+}
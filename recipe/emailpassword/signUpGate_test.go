@@ -0,0 +1,177 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSignUpAPIConsumesTheInvitationBeforeCreatingTheUser(t *testing.T) {
+	invitations := map[string]supertokens.Invitation{}
+	redeemedBy := []string{}
+	invitationsLeftOnSignUp := []int{}
+	redeemedOnSessionCreation := []int{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		SignUpGate: &supertokens.SignUpGate{
+			Mode: supertokens.SignUpModeInviteOnly,
+			Invitations: &supertokens.InvitationConfig{
+				CreateAndSendCustomEmail: func(invitation supertokens.Invitation, invitationURLWithToken string, userContext supertokens.UserContext) {
+				},
+				SaveInvitation: func(tokenHash string, invitation supertokens.Invitation, userContext supertokens.UserContext) error {
+					invitations[tokenHash] = invitation
+					return nil
+				},
+				GetInvitation: func(tokenHash string, userContext supertokens.UserContext) (*supertokens.Invitation, error) {
+					invitation, ok := invitations[tokenHash]
+					if !ok {
+						return nil, nil
+					}
+					return &invitation, nil
+				},
+				ConsumeInvitation: func(tokenHash string, userContext supertokens.UserContext) (*supertokens.Invitation, error) {
+					invitation, ok := invitations[tokenHash]
+					if !ok {
+						return nil, nil
+					}
+					delete(invitations, tokenHash)
+					return &invitation, nil
+				},
+				RemoveInvitation: func(tokenHash string, userContext supertokens.UserContext) error {
+					delete(invitations, tokenHash)
+					return nil
+				},
+				OnInvitationRedeemed: func(userID string, invitation supertokens.Invitation, userContext supertokens.UserContext) error {
+					redeemedBy = append(redeemedBy, invitation.Email)
+					return nil
+				},
+			},
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				Override: &epmodels.OverrideStruct{
+					Functions: func(originalImplementation epmodels.RecipeInterface) epmodels.RecipeInterface {
+						originalSignUp := *originalImplementation.SignUp
+						*originalImplementation.SignUp = func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+							invitationsLeftOnSignUp = append(invitationsLeftOnSignUp, len(invitations))
+							if email == "error@example.com" {
+								return epmodels.SignUpResponse{}, errors.New("the core is unavailable")
+							}
+							return originalSignUp(email, password, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+			session.Init(&sessmodels.TypeInput{
+				Override: &sessmodels.OverrideStruct{
+					Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+						originalCreateNewSession := *originalImplementation.CreateNewSession
+						*originalImplementation.CreateNewSession = func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+							redeemedOnSessionCreation = append(redeemedOnSessionCreation, len(redeemedBy))
+							return originalCreateNewSession(res, userID, accessTokenPayload, sessionData, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	signUp := func(email string, inviteToken string) map[string]interface{} {
+		response, err := postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+			"formFields": []map[string]interface{}{
+				{"id": "email", "value": email},
+				{"id": "password", "value": "validPass123"},
+			},
+			"inviteToken": inviteToken,
+		}, nil)
+		assert.NoError(t, err)
+		return response
+	}
+
+	// users can still be created from the backend
+	_, err = SignUp("john@example.com", "validPass123")
+	assert.NoError(t, err)
+	invitationsLeftOnSignUp = []int{}
+
+	// the sign up fails because the user exists, so the invitation is put back
+	johnInviteToken, err := supertokens.CreateInvitation("john@example.com", nil, nil)
+	assert.NoError(t, err)
+	response := signUp("john@example.com", johnInviteToken)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Equal(t, []int{0}, invitationsLeftOnSignUp)
+	assert.Len(t, invitations, 1)
+	assert.Empty(t, redeemedBy)
+	assert.NoError(t, supertokens.RevokeInvitation(johnInviteToken))
+
+	janeInviteToken, err := supertokens.CreateInvitation("jane@example.com", nil, nil)
+	assert.NoError(t, err)
+	response = signUp("jane@example.com", janeInviteToken)
+	assert.Equal(t, "OK", response["status"])
+	// the invitation was already used up when the user was created
+	assert.Equal(t, []int{0, 0}, invitationsLeftOnSignUp)
+	assert.Empty(t, invitations)
+	assert.Equal(t, []string{"jane@example.com"}, redeemedBy)
+	// the invitation was redeemed before the session of the new user was created
+	assert.Equal(t, []int{1}, redeemedOnSessionCreation)
+
+	response = signUp("jane2@example.com", janeInviteToken)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Len(t, invitationsLeftOnSignUp, 2)
+
+	// the invitation is put back when creating the user fails as well
+	errorInviteToken, err := supertokens.CreateInvitation("error@example.com", nil, nil)
+	assert.NoError(t, err)
+	// the request fails with a general error
+	postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "email", "value": "error@example.com"},
+			{"id": "password", "value": "validPass123"},
+		},
+		"inviteToken": errorInviteToken,
+	}, nil)
+	assert.Equal(t, []int{0, 0, 0}, invitationsLeftOnSignUp)
+	assert.Len(t, invitations, 1)
+	assert.Equal(t, []string{"jane@example.com"}, redeemedBy)
+}
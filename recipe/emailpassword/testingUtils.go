@@ -16,6 +16,9 @@
 package emailpassword
 
 import (
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 	"github.com/supertokens/supertokens-golang/test/unittesting"
@@ -38,3 +41,81 @@ func AfterEach() {
 	resetAll()
 	unittesting.CleanST()
 }
+
+type inMemoryEmailPasswordUser struct {
+	user     epmodels.User
+	password string
+}
+
+// makeInMemoryEmailPasswordRecipeImplementation is a recipe implementation that
+// keeps users in memory, used to test the recipe implementation wrappers
+// without a core. Password reset tokens are "token-" followed by the user ID.
+func makeInMemoryEmailPasswordRecipeImplementation(users map[string]*inMemoryEmailPasswordUser) epmodels.RecipeInterface {
+	signUp := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignUpResponse, error) {
+		if _, ok := users[email]; ok {
+			return epmodels.SignUpResponse{EmailAlreadyExistsError: &struct{}{}}, nil
+		}
+		user := epmodels.User{ID: "user" + strconv.Itoa(len(users)+1), Email: email}
+		users[email] = &inMemoryEmailPasswordUser{user: user, password: password}
+		return epmodels.SignUpResponse{OK: &struct{ User epmodels.User }{User: user}}, nil
+	}
+	signIn := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
+		user, ok := users[email]
+		if !ok || user.password != password {
+			return epmodels.SignInResponse{WrongCredentialsError: &struct{}{}}, nil
+		}
+		return epmodels.SignInResponse{OK: &struct{ User epmodels.User }{User: user.user}}, nil
+	}
+	getUserByID := func(userID string, userContext supertokens.UserContext) (*epmodels.User, error) {
+		for _, user := range users {
+			if user.user.ID == userID {
+				return &user.user, nil
+			}
+		}
+		return nil, nil
+	}
+	getUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, error) {
+		user, ok := users[email]
+		if !ok {
+			return nil, nil
+		}
+		return &user.user, nil
+	}
+	createResetPasswordToken := func(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
+		return epmodels.CreateResetPasswordTokenResponse{OK: &struct{ Token string }{Token: "token-" + userID}}, nil
+	}
+	resetPasswordUsingToken := func(token string, newPassword string, userContext supertokens.UserContext) (epmodels.ResetPasswordUsingTokenResponse, error) {
+		for _, user := range users {
+			if token == "token-"+user.user.ID {
+				user.password = newPassword
+				return epmodels.ResetPasswordUsingTokenResponse{OK: &struct{ UserId *string }{UserId: &user.user.ID}}, nil
+			}
+		}
+		return epmodels.ResetPasswordUsingTokenResponse{ResetPasswordInvalidTokenError: &struct{}{}}, nil
+	}
+	updateEmailOrPassword := func(userId string, email *string, password *string, userContext supertokens.UserContext) (epmodels.UpdateEmailOrPasswordResponse, error) {
+		for userEmail, user := range users {
+			if user.user.ID == userId {
+				if password != nil {
+					user.password = *password
+				}
+				if email != nil {
+					delete(users, userEmail)
+					user.user.Email = *email
+					users[*email] = user
+				}
+				return epmodels.UpdateEmailOrPasswordResponse{OK: &struct{}{}}, nil
+			}
+		}
+		return epmodels.UpdateEmailOrPasswordResponse{UnknownUserIdError: &struct{}{}}, nil
+	}
+	return epmodels.RecipeInterface{
+		SignUp:                   &signUp,
+		SignIn:                   &signIn,
+		GetUserByID:              &getUserByID,
+		GetUserByEmail:           &getUserByEmail,
+		CreateResetPasswordToken: &createResetPasswordToken,
+		ResetPasswordUsingToken:  &resetPasswordUsingToken,
+		UpdateEmailOrPassword:    &updateEmailOrPassword,
+	}
+}
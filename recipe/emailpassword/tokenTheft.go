@@ -0,0 +1,97 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// ForcePasswordResetOnTokenTheft is an action for the session recipe's TokenTheftPolicy. It replaces the password
+// of the user with a random one, so that a stolen password can no longer be used, and sends the user a password
+// reset email using the ResetPasswordUsingTokenFeature config. Users that did not sign up with a password are skipped.
+func ForcePasswordResetOnTokenTheft() sessmodels.TokenTheftAction {
+	return func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		instance, err := getRecipeInstanceOrThrowError()
+		if err != nil {
+			return err
+		}
+		user, err := (*instance.RecipeImpl.GetUserByID)(event.UserID, userContext)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return nil
+		}
+
+		randomPassword, err := generateRandomPassword()
+		if err != nil {
+			return err
+		}
+		_, err = (*instance.RecipeImpl.UpdateEmailOrPassword)(user.ID, nil, &randomPassword, userContext)
+		if err != nil {
+			return err
+		}
+
+		response, err := (*instance.RecipeImpl.CreateResetPasswordToken)(user.ID, userContext)
+		if err != nil {
+			return err
+		}
+		if response.UnknownUserIdError != nil {
+			return nil
+		}
+		passwordResetLink, err := instance.Config.ResetPasswordUsingTokenFeature.GetResetPasswordURL(*user, userContext)
+		if err != nil {
+			return err
+		}
+		passwordResetLink = passwordResetLink + "?token=" + response.OK.Token + "&rid=" + instance.RecipeModule.GetRecipeID()
+		instance.Config.ResetPasswordUsingTokenFeature.CreateAndSendCustomEmail(*user, passwordResetLink, userContext)
+		return nil
+	}
+}
+
+// NotifyUserOnTokenTheft is an action for the session recipe's TokenTheftPolicy that calls createAndSendCustomEmail
+// so that the user can be told about the incident. Users that did not sign up with a password are skipped.
+func NotifyUserOnTokenTheft(createAndSendCustomEmail func(user epmodels.User, event sessmodels.TokenTheftEvent, userContext supertokens.UserContext)) sessmodels.TokenTheftAction {
+	return func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		instance, err := getRecipeInstanceOrThrowError()
+		if err != nil {
+			return err
+		}
+		user, err := (*instance.RecipeImpl.GetUserByID)(event.UserID, userContext)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return nil
+		}
+		createAndSendCustomEmail(*user, event, userContext)
+		return nil
+	}
+}
+
+func generateRandomPassword() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
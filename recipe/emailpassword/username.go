@@ -0,0 +1,181 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+var defaultUsernameRegex = regexp.MustCompile(`^[a-z0-9._-]{3,32}$`)
+
+// ValidateAndNormaliseUsernameFeature is exported so that recipes built on top of this one can
+// look up usernames the same way
+func ValidateAndNormaliseUsernameFeature(config *epmodels.TypeInputUsernameFeature) (epmodels.TypeNormalisedInputUsernameFeature, error) {
+	if config == nil {
+		return epmodels.TypeNormalisedInputUsernameFeature{}, nil
+	}
+	if config.GetUserIDByUsername == nil || config.ReserveUsername == nil || config.SetUsername == nil || config.ReleaseUsername == nil {
+		return epmodels.TypeNormalisedInputUsernameFeature{}, errors.New("please provide GetUserIDByUsername, ReserveUsername, SetUsername and ReleaseUsername in usernameFeature")
+	}
+
+	validate := defaultUsernameValidator
+	if config.Validate != nil {
+		validate = config.Validate
+	}
+	return epmodels.TypeNormalisedInputUsernameFeature{
+		Enabled:       true,
+		EmailOptional: config.EmailOptional,
+		Validate: func(value interface{}) *string {
+			username, ok := value.(string)
+			if !ok {
+				msg := "Username must be a string"
+				return &msg
+			}
+			if strings.Contains(username, "@") {
+				msg := "Username must not contain an @"
+				return &msg
+			}
+			return validate(username)
+		},
+		GetUserIDByUsername: func(username string, userContext supertokens.UserContext) (*string, error) {
+			return config.GetUserIDByUsername(normaliseUsername(username), userContext)
+		},
+		ReserveUsername: func(username string, userContext supertokens.UserContext) (bool, error) {
+			return config.ReserveUsername(normaliseUsername(username), userContext)
+		},
+		SetUsername: func(userID string, username string, userContext supertokens.UserContext) error {
+			return config.SetUsername(userID, normaliseUsername(username), userContext)
+		},
+		ReleaseUsername: func(username string, userContext supertokens.UserContext) error {
+			return config.ReleaseUsername(normaliseUsername(username), userContext)
+		},
+	}, nil
+}
+
+func normaliseUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func defaultUsernameValidator(value interface{}) *string {
+	if !defaultUsernameRegex.MatchString(normaliseUsername(value.(string))) {
+		msg := "Username must be 3 to 32 characters long and can only contain letters, numbers, dots, underscores and hyphens"
+		return &msg
+	}
+	return nil
+}
+
+// normaliseUsernameFormFields adds the username to the sign up form, and makes the email optional
+// if users can sign up without one. This must be called before the sign in form is normalised,
+// since the sign in form accepts a username in place of the email
+func normaliseUsernameFormFields(signUpConfig epmodels.TypeNormalisedInputSignUp, config epmodels.TypeNormalisedInputUsernameFeature) epmodels.TypeNormalisedInputSignUp {
+	var (
+		formFields    []epmodels.NormalisedFormField
+		foundUsername = false
+	)
+	for _, formField := range signUpConfig.FormFields {
+		if formField.ID == "username" {
+			foundUsername = true
+			formField.Validate = config.Validate
+			formField.Optional = false
+		} else if formField.ID == "email" && config.EmailOptional {
+			emailValidate := formField.Validate
+			formField.Validate = func(value interface{}) *string {
+				if value == "" {
+					return nil
+				}
+				return emailValidate(value)
+			}
+			formField.Optional = true
+		}
+		formFields = append(formFields, formField)
+	}
+	if !foundUsername {
+		formFields = append(formFields, epmodels.NormalisedFormField{
+			ID:       "username",
+			Validate: config.Validate,
+			Optional: false,
+		})
+	}
+	signUpConfig.FormFields = formFields
+	return signUpConfig
+}
+
+// normaliseUsernameSignInFormFields lets the email field of the sign in form contain a username.
+// Anything with an @ is validated as an email, and anything else as a username
+func normaliseUsernameSignInFormFields(signInConfig epmodels.TypeNormalisedInputSignIn, config epmodels.TypeNormalisedInputUsernameFeature) epmodels.TypeNormalisedInputSignIn {
+	var formFields []epmodels.NormalisedFormField
+	for _, formField := range signInConfig.FormFields {
+		if formField.ID == "email" {
+			emailValidate := formField.Validate
+			formField.Validate = func(value interface{}) *string {
+				if identifier, ok := value.(string); ok && !strings.Contains(identifier, "@") {
+					return config.Validate(identifier)
+				}
+				return emailValidate(value)
+			}
+			formField.Optional = false
+		}
+		formFields = append(formFields, formField)
+	}
+	signInConfig.FormFields = formFields
+	return signInConfig
+}
+
+// MakeUsernameRecipeImplementation lets users sign in with their username in place of their email.
+// thirdpartyemailpassword applies it to its email password users
+func MakeUsernameRecipeImplementation(originalImplementation epmodels.RecipeInterface, config epmodels.TypeNormalisedInputUsernameFeature) epmodels.RecipeInterface {
+	originalSignIn := *originalImplementation.SignIn
+
+	signIn := func(email string, password string, userContext supertokens.UserContext) (epmodels.SignInResponse, error) {
+		if strings.Contains(email, "@") {
+			return originalSignIn(email, password, userContext)
+		}
+		user, err := getUserByUsername(originalImplementation, config, email, userContext)
+		if err != nil {
+			return epmodels.SignInResponse{}, err
+		}
+		if user == nil {
+			return epmodels.SignInResponse{
+				WrongCredentialsError: &struct{}{},
+			}, nil
+		}
+		return originalSignIn(user.Email, password, userContext)
+	}
+
+	*originalImplementation.SignIn = signIn
+	return originalImplementation
+}
+
+func getUserByUsername(recipeImplementation epmodels.RecipeInterface, config epmodels.TypeNormalisedInputUsernameFeature, username string, userContext supertokens.UserContext) (*epmodels.User, error) {
+	userID, err := config.GetUserIDByUsername(username, userContext)
+	if err != nil || userID == nil {
+		return nil, err
+	}
+	return (*recipeImplementation.GetUserByID)(*userID, userContext)
+}
+
+// GetUserByUsername returns the user who signed up with the username, or nil if there is none
+func (r *Recipe) GetUserByUsername(username string, userContext supertokens.UserContext) (*epmodels.User, error) {
+	if !r.Config.UsernameFeature.Enabled {
+		return nil, errors.New("please configure usernameFeature in the emailpassword recipe to get users by username")
+	}
+	return getUserByUsername(r.RecipeImpl, r.Config.UsernameFeature, username, userContext)
+}
@@ -0,0 +1,234 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func makeInMemoryUsernameFeatureConfig(usernames map[string]string) *epmodels.TypeInputUsernameFeature {
+	return &epmodels.TypeInputUsernameFeature{
+		GetUserIDByUsername: func(username string, userContext supertokens.UserContext) (*string, error) {
+			userID, ok := usernames[username]
+			if !ok || userID == "" {
+				return nil, nil
+			}
+			return &userID, nil
+		},
+		// reserved usernames are kept without a user ID
+		ReserveUsername: func(username string, userContext supertokens.UserContext) (bool, error) {
+			if _, ok := usernames[username]; ok {
+				return false, nil
+			}
+			usernames[username] = ""
+			return true, nil
+		},
+		SetUsername: func(userID string, username string, userContext supertokens.UserContext) error {
+			usernames[username] = userID
+			return nil
+		},
+		ReleaseUsername: func(username string, userContext supertokens.UserContext) error {
+			delete(usernames, username)
+			return nil
+		},
+	}
+}
+
+func getFormField(formFields []epmodels.NormalisedFormField, id string) *epmodels.NormalisedFormField {
+	for _, formField := range formFields {
+		if formField.ID == id {
+			return &formField
+		}
+	}
+	return nil
+}
+
+func TestUsernameFeatureConfigValidation(t *testing.T) {
+	_, err := ValidateAndNormaliseUsernameFeature(&epmodels.TypeInputUsernameFeature{})
+	assert.Error(t, err)
+
+	config, err := ValidateAndNormaliseUsernameFeature(nil)
+	assert.NoError(t, err)
+	assert.False(t, config.Enabled)
+}
+
+func TestUsernameValidation(t *testing.T) {
+	config, err := ValidateAndNormaliseUsernameFeature(makeInMemoryUsernameFeatureConfig(map[string]string{}))
+	assert.NoError(t, err)
+	assert.Nil(t, config.Validate("Player_One"))
+	assert.Nil(t, config.Validate("player.one-2"))
+	assert.NotNil(t, config.Validate("ab"))
+	assert.NotNil(t, config.Validate("player one"))
+	assert.NotNil(t, config.Validate(42.0))
+
+	customConfig := makeInMemoryUsernameFeatureConfig(map[string]string{})
+	customConfig.Validate = func(value interface{}) *string {
+		return nil
+	}
+	config, err = ValidateAndNormaliseUsernameFeature(customConfig)
+	assert.NoError(t, err)
+	assert.Nil(t, config.Validate("x"))
+	assert.Equal(t, "Username must not contain an @", *config.Validate("player@one"))
+}
+
+func TestUsernameFormFields(t *testing.T) {
+	usernameConfig := makeInMemoryUsernameFeatureConfig(map[string]string{})
+	usernameConfig.EmailOptional = true
+	config, err := ValidateAndNormaliseUsernameFeature(usernameConfig)
+	assert.NoError(t, err)
+
+	signUpConfig := normaliseUsernameFormFields(validateAndNormaliseSignupConfig(nil, epmodels.TypeNormalisedInputPasswordPolicy{}), config)
+	username := getFormField(signUpConfig.FormFields, "username")
+	assert.NotNil(t, username)
+	assert.False(t, username.Optional)
+	email := getFormField(signUpConfig.FormFields, "email")
+	assert.True(t, email.Optional)
+	assert.Nil(t, email.Validate(""))
+	assert.NotNil(t, email.Validate("invalid"))
+
+	signInConfig := normaliseUsernameSignInFormFields(validateAndNormaliseSignInConfig(signUpConfig), config)
+	email = getFormField(signInConfig.FormFields, "email")
+	assert.False(t, email.Optional)
+	assert.Nil(t, email.Validate("player_one"))
+	assert.Nil(t, email.Validate("test@example.com"))
+	assert.Equal(t, "Email is invalid", *email.Validate("player@"))
+	assert.NotNil(t, email.Validate("p"))
+	assert.Nil(t, getFormField(signInConfig.FormFields, "username"))
+}
+
+func TestSignInWithUsername(t *testing.T) {
+	users := map[string]*inMemoryEmailPasswordUser{
+		"test@example.com": {user: epmodels.User{ID: "user1", Email: "test@example.com"}, password: "password1"},
+	}
+	config, err := ValidateAndNormaliseUsernameFeature(makeInMemoryUsernameFeatureConfig(map[string]string{"player_one": "user1"}))
+	assert.NoError(t, err)
+	recipeImplementation := MakeUsernameRecipeImplementation(makeInMemoryEmailPasswordRecipeImplementation(users), config)
+	userContext := &map[string]interface{}{}
+
+	response, err := (*recipeImplementation.SignIn)(" Player_One", "password1", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", response.OK.User.ID)
+
+	response, err = (*recipeImplementation.SignIn)("test@example.com", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.OK)
+
+	response, err = (*recipeImplementation.SignIn)("player_one", "wrong", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.WrongCredentialsError)
+
+	response, err = (*recipeImplementation.SignIn)("player_two", "password1", userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, response.WrongCredentialsError)
+
+	user, err := getUserByUsername(recipeImplementation, config, "PLAYER_ONE", userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+}
+
+func TestSignUpAPIWithOnlyAUsernameReservesTheUsernameBeforeCreatingTheUser(t *testing.T) {
+	usernames := map[string]string{}
+	usernameConfig := makeInMemoryUsernameFeatureConfig(usernames)
+	usernameConfig.EmailOptional = true
+	reserveUsername := usernameConfig.ReserveUsername
+	usernameConfig.ReserveUsername = func(username string, userContext supertokens.UserContext) (bool, error) {
+		if username == "player_two" {
+			// another user takes the username between the check and the sign up
+			usernames[username] = "otherUser"
+		}
+		return reserveUsername(username, userContext)
+	}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&epmodels.TypeInput{
+				UsernameFeature: usernameConfig,
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	// the email field is left out altogether
+	response, err := postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "username", "value": "player_one"},
+			{"id": "password", "value": "validPass123"},
+		},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+	userID := response["user"].(map[string]interface{})["id"].(string)
+	assert.True(t, strings.HasSuffix(response["user"].(map[string]interface{})["email"].(string), "@"+epmodels.UsernamePlaceholderEmailDomain))
+	assert.Equal(t, userID, usernames["player_one"])
+
+	response, err = postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "username", "value": "player_two"},
+			{"id": "password", "value": "validPass123"},
+		},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Equal(t, "username", response["formFields"].([]interface{})[0].(map[string]interface{})["id"])
+
+	// no user was created without a username
+	userCount, err := supertokens.GetUserCount(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), userCount)
+
+	_, err = SignUp("test@example.com", "validPass123")
+	assert.NoError(t, err)
+	response, err = postJSONRequest(testServer.URL, "/auth/signup", map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "username", "value": "player_three"},
+			{"id": "email", "value": "test@example.com"},
+			{"id": "password", "value": "validPass123"},
+		},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "FIELD_ERROR", response["status"])
+	assert.Equal(t, "email", response["formFields"].([]interface{})[0].(map[string]interface{})["id"])
+
+	// the reservation is released when the user could not be created
+	_, ok := usernames["player_three"]
+	assert.False(t, ok)
+}
@@ -26,17 +26,45 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) epmodels.TypeNormalisedInput {
+func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.NormalisedAppinfo, config *epmodels.TypeInput) (epmodels.TypeNormalisedInput, error) {
 
 	typeNormalisedInput := makeTypeNormalisedInput(recipeInstance)
 
+	if config != nil && config.PasswordPolicy != nil {
+		passwordPolicy, err := ValidateAndNormalisePasswordPolicy(config.PasswordPolicy)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.PasswordPolicy = passwordPolicy
+		// the password policy replaces the default password validator, so we
+		// normalise the sign up form fields again without it.
+		typeNormalisedInput.SignUpFeature = validateAndNormaliseSignupConfig(nil, passwordPolicy)
+		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(appInfo, typeNormalisedInput.SignUpFeature, nil)
+	}
+
 	if config != nil && config.SignUpFeature != nil {
-		typeNormalisedInput.SignUpFeature = validateAndNormaliseSignupConfig(config.SignUpFeature)
+		err := validateSignUpFormFieldsStorageConfig(config.SignUpFeature)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.SignUpFeature = validateAndNormaliseSignupConfig(config.SignUpFeature, typeNormalisedInput.PasswordPolicy)
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(appInfo, typeNormalisedInput.SignUpFeature, nil)
 	}
 
+	if config != nil && config.UsernameFeature != nil {
+		usernameFeature, err := ValidateAndNormaliseUsernameFeature(config.UsernameFeature)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.UsernameFeature = usernameFeature
+		typeNormalisedInput.SignUpFeature = normaliseUsernameFormFields(typeNormalisedInput.SignUpFeature, usernameFeature)
+	}
+
 	// we must call this after validateAndNormaliseSignupConfig
 	typeNormalisedInput.SignInFeature = validateAndNormaliseSignInConfig(typeNormalisedInput.SignUpFeature)
+	if typeNormalisedInput.UsernameFeature.Enabled {
+		typeNormalisedInput.SignInFeature = normaliseUsernameSignInFormFields(typeNormalisedInput.SignInFeature, typeNormalisedInput.UsernameFeature)
+	}
 
 	if config != nil && config.ResetPasswordUsingTokenFeature != nil {
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = validateAndNormaliseResetPasswordUsingTokenConfig(appInfo, typeNormalisedInput.SignUpFeature, config.ResetPasswordUsingTokenFeature)
@@ -44,6 +72,42 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 
 	typeNormalisedInput.EmailVerificationFeature = validateAndNormaliseEmailVerificationConfig(recipeInstance, config)
 
+	if config != nil && config.ChangePasswordFeature != nil {
+		typeNormalisedInput.ChangePasswordFeature.RevokeOtherSessions = config.ChangePasswordFeature.RevokeOtherSessions
+	}
+
+	if config != nil && config.EmailChangeFeature != nil {
+		emailChangeFeature, err := validateAndNormaliseEmailChangeConfig(appInfo, config.EmailChangeFeature)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.EmailChangeFeature = emailChangeFeature
+	}
+
+	if config != nil && config.EmailPolicy != nil {
+		emailPolicy, err := supertokens.NormaliseEmailPolicyOrThrowError(config.EmailPolicy)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.EmailPolicy = emailPolicy
+	}
+
+	if config != nil && config.BruteForceProtection != nil {
+		bruteForceProtection, err := validateAndNormaliseBruteForceProtectionConfig(appInfo, config.BruteForceProtection)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.BruteForceProtection = bruteForceProtection
+	}
+
+	if config != nil && config.LegacyPasswordMigration != nil {
+		legacyPasswordMigration, err := validateAndNormaliseLegacyPasswordMigrationConfig(config.LegacyPasswordMigration)
+		if err != nil {
+			return epmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.LegacyPasswordMigration = legacyPasswordMigration
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -56,11 +120,11 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		}
 	}
 
-	return typeNormalisedInput
+	return typeNormalisedInput, nil
 }
 
 func makeTypeNormalisedInput(recipeInstance *Recipe) epmodels.TypeNormalisedInput {
-	signUpConfig := validateAndNormaliseSignupConfig(nil)
+	signUpConfig := validateAndNormaliseSignupConfig(nil, epmodels.TypeNormalisedInputPasswordPolicy{})
 	return epmodels.TypeNormalisedInput{
 		SignUpFeature:                  signUpConfig,
 		SignInFeature:                  validateAndNormaliseSignInConfig(signUpConfig),
@@ -138,6 +202,8 @@ func validateAndNormaliseResetPasswordUsingTokenConfig(appInfo supertokens.Norma
 				formFieldsForPasswordResetForm = append(formFieldsForPasswordResetForm, FormField)
 			}
 			if FormField.ID == "email" {
+				// the email can be optional on sign up, but is needed to reset the password
+				FormField.Optional = false
 				formFieldsForGenerateTokenForm = append(formFieldsForGenerateTokenForm, FormField)
 			}
 		}
@@ -183,18 +249,27 @@ func normaliseSignInFormFields(formFields []epmodels.NormalisedFormField) []epmo
 	return normalisedFormFields
 }
 
-func validateAndNormaliseSignupConfig(config *epmodels.TypeInputSignUp) epmodels.TypeNormalisedInputSignUp {
+func validateAndNormaliseSignupConfig(config *epmodels.TypeInputSignUp, passwordPolicy epmodels.TypeNormalisedInputPasswordPolicy) epmodels.TypeNormalisedInputSignUp {
+	passwordValidator := defaultPasswordValidator
+	if passwordPolicy.Enabled {
+		passwordValidator = passwordPolicyFormFieldValidator
+	}
 	if config == nil {
 		return epmodels.TypeNormalisedInputSignUp{
-			FormFields: NormaliseSignUpFormFields(nil),
+			FormFields: normaliseSignUpFormFields(nil, passwordValidator),
 		}
 	}
 	return epmodels.TypeNormalisedInputSignUp{
-		FormFields: NormaliseSignUpFormFields(config.FormFields),
+		FormFields:         normaliseSignUpFormFields(config.FormFields, passwordValidator),
+		UpdateUserMetadata: config.UpdateUserMetadata,
 	}
 }
 
 func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmodels.NormalisedFormField {
+	return normaliseSignUpFormFields(formFields, defaultPasswordValidator)
+}
+
+func normaliseSignUpFormFields(formFields []epmodels.TypeInputFormField, defaultPasswordValidate func(value interface{}) *string) []epmodels.NormalisedFormField {
 	var (
 		normalisedFormFields     []epmodels.NormalisedFormField
 		formFieldPasswordIDCount = 0
@@ -206,10 +281,11 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 			var (
 				validate func(value interface{}) *string
 				optional bool = false
+				storage  string
 			)
 			if formField.ID == "password" {
 				formFieldPasswordIDCount++
-				validate = defaultPasswordValidator
+				validate = defaultPasswordValidate
 				if formField.Validate != nil {
 					validate = formField.Validate
 				}
@@ -228,17 +304,30 @@ func NormaliseSignUpFormFields(formFields []epmodels.TypeInputFormField) []epmod
 					optional = *formField.Optional
 				}
 			}
+			acceptsJSONValues := false
+			if formField.ID != "email" && formField.ID != "password" {
+				if formField.AcceptsJSONValues != nil {
+					acceptsJSONValues = *formField.AcceptsJSONValues
+				} else {
+					acceptsJSONValues = formField.Storage != nil
+				}
+			}
+			if formField.Storage != nil {
+				storage = *formField.Storage
+			}
 			normalisedFormFields = append(normalisedFormFields, epmodels.NormalisedFormField{
-				ID:       formField.ID,
-				Validate: validate,
-				Optional: optional,
+				ID:                formField.ID,
+				Validate:          validate,
+				Optional:          optional,
+				Storage:           storage,
+				AcceptsJSONValues: acceptsJSONValues,
 			})
 		}
 	}
 	if formFieldPasswordIDCount == 0 {
 		normalisedFormFields = append(normalisedFormFields, epmodels.NormalisedFormField{
 			ID:       "password",
-			Validate: defaultPasswordValidator,
+			Validate: defaultPasswordValidate,
 			Optional: false,
 		})
 	}
@@ -21,6 +21,7 @@ import (
 	"reflect"
 
 	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -90,7 +91,44 @@ func ConsumeCode(apiImplementation plessmodels.APIInterface, options plessmodels
 		linkCodePointer = &t
 	}
 
-	response, err := (*apiImplementation.ConsumeCodePOST)(userInput, linkCodePointer, preAuthSessionID.(string), options, &map[string]interface{}{})
+	userContext := &map[string]interface{}{}
+	if rememberMe, ok := readBody["rememberMe"].(bool); ok {
+		session.SetRememberMe(userContext, rememberMe)
+	}
+	if inviteToken, ok := readBody["inviteToken"].(string); ok {
+		supertokens.SetInviteToken(userContext, inviteToken)
+	}
+
+	// consuming the code creates the user, so the invitation is used up here in case it was used or has
+	// expired since the code was created
+	device, err := (*options.RecipeImplementation.ListCodesByPreAuthSessionID)(preAuthSessionID.(string), userContext)
+	if err != nil {
+		return err
+	}
+	var invitation *supertokens.Invitation
+	if device != nil {
+		var msg *string
+		invitation, msg, err = consumeInvitation(options, device.Email, device.PhoneNumber, userContext)
+		if err != nil {
+			return err
+		}
+		if msg != nil {
+			return supertokens.Send200Response(options.Res, map[string]interface{}{
+				"status":  "GENERAL_ERROR",
+				"message": *msg,
+			})
+		}
+	}
+
+	userCreated := false
+	response, err := (*apiImplementation.ConsumeCodePOST)(userInput, linkCodePointer, preAuthSessionID.(string), withInvitationRedeemedOnConsumeCode(options, invitation, &userCreated), userContext)
+	if !userCreated {
+		// no user was created, so the invitation can still be used
+		restoreErr := supertokens.RestoreInvitation(invitation, supertokens.GetInviteToken(userContext), userContext)
+		if restoreErr != nil {
+			return restoreErr
+		}
+	}
 	if err != nil {
 		return err
 	}
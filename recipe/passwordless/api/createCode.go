@@ -65,6 +65,11 @@ func CreateCode(apiImplementation plessmodels.APIInterface, options plessmodels.
 		return supertokens.BadInputError{Msg: "Please provide a phoneNumber since you have enabled ContactMethodPhone"}
 	}
 
+	userContext := &map[string]interface{}{}
+	if inviteToken, ok := readBody["inviteToken"].(string); ok {
+		supertokens.SetInviteToken(userContext, inviteToken)
+	}
+
 	if okEmail {
 		// normalize and validate email
 		email = strings.TrimSpace(email.(string))
@@ -74,6 +79,19 @@ func CreateCode(apiImplementation plessmodels.APIInterface, options plessmodels.
 		} else {
 			validateErr = options.Config.ContactMethodEmailOrPhone.ValidateEmailAddress(email)
 		}
+		if validateErr == nil {
+			validateErr = supertokens.ValidateEmailDomain(options.Config.EmailPolicy, email.(string))
+			if validateErr != nil {
+				// users who signed up before their domain was blocked can still sign in
+				existingUser, err := (*options.RecipeImplementation.GetUserByEmail)(email.(string), userContext)
+				if err != nil {
+					return err
+				}
+				if existingUser != nil {
+					validateErr = nil
+				}
+			}
+		}
 		if validateErr != nil {
 			return supertokens.Send200Response(options.Res, map[string]interface{}{
 				"status":  "GENERAL_ERROR",
@@ -117,7 +135,18 @@ func CreateCode(apiImplementation plessmodels.APIInterface, options plessmodels.
 		phoneNumberStrPointer = &t
 	}
 
-	response, err := (*apiImplementation.CreateCodePOST)(emailStrPointer, phoneNumberStrPointer, options, &map[string]interface{}{})
+	msg, err := checkSignUpAllowed(options, emailStrPointer, phoneNumberStrPointer, userContext)
+	if err != nil {
+		return err
+	}
+	if msg != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":  "GENERAL_ERROR",
+			"message": *msg,
+		})
+	}
+
+	response, err := (*apiImplementation.CreateCodePOST)(emailStrPointer, phoneNumberStrPointer, options, userContext)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,79 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// checkSignUpAllowed returns an error message if the user does not exist yet, and the sign up gate does not let them sign up
+func checkSignUpAllowed(options plessmodels.APIOptions, email *string, phoneNumber *string, userContext supertokens.UserContext) (*string, error) {
+	msg, err := supertokens.CheckSignUpAllowed(email, phoneNumber, supertokens.GetInviteToken(userContext), userContext)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	existingUser, err := getExistingUser(options, email, phoneNumber, userContext)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, nil
+	}
+	return msg, nil
+}
+
+// consumeInvitation uses up the invitation if the user does not exist yet, since consuming the code will
+// create them. It returns an error message instead if the sign up gate does not let them sign up
+func consumeInvitation(options plessmodels.APIOptions, email *string, phoneNumber *string, userContext supertokens.UserContext) (*supertokens.Invitation, *string, error) {
+	existingUser, err := getExistingUser(options, email, phoneNumber, userContext)
+	if err != nil || existingUser != nil {
+		return nil, nil, err
+	}
+	return supertokens.ConsumeInvitation(email, phoneNumber, supertokens.GetInviteToken(userContext), userContext)
+}
+
+func getExistingUser(options plessmodels.APIOptions, email *string, phoneNumber *string, userContext supertokens.UserContext) (*plessmodels.User, error) {
+	if email != nil {
+		return (*options.RecipeImplementation.GetUserByEmail)(*email, userContext)
+	} else if phoneNumber != nil {
+		return (*options.RecipeImplementation.GetUserByPhoneNumber)(*phoneNumber, userContext)
+	}
+	return nil, nil
+}
+
+// withInvitationRedeemedOnConsumeCode redeems the invitation as soon as consuming the code creates the user,
+// before their session is created. userCreated is set then, so that the invitation is restored otherwise
+func withInvitationRedeemedOnConsumeCode(options plessmodels.APIOptions, invitation *supertokens.Invitation, userCreated *bool) plessmodels.APIOptions {
+	originalConsumeCode := *options.RecipeImplementation.ConsumeCode
+	consumeCode := func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, userContext supertokens.UserContext) (plessmodels.ConsumeCodeResponse, error) {
+		response, err := originalConsumeCode(userInput, linkCode, preAuthSessionID, userContext)
+		if err != nil || response.OK == nil || !response.OK.CreatedNewUser {
+			return response, err
+		}
+		*userCreated = true
+		err = supertokens.RedeemInvitation(response.OK.User.ID, invitation, userContext)
+		if err != nil {
+			return plessmodels.ConsumeCodeResponse{}, err
+		}
+		return response, nil
+	}
+	// the recipe implementation is copied, so that only this request redeems the invitation
+	recipeImplementation := options.RecipeImplementation
+	recipeImplementation.ConsumeCode = &consumeCode
+	options.RecipeImplementation = recipeImplementation
+	return options
+}
@@ -0,0 +1,145 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// MakeEmailPolicyRecipeImplementation creates the codes for the email a user is stored under, so that
+// signing in with another form of the same email finds them instead of creating a second user. Emails
+// that are changed are normalised before they reach the core. The email domain is checked by the create
+// code API, since CreateCode has no response for rejecting an email. thirdpartypasswordless uses it for
+// its passwordless users
+func MakeEmailPolicyRecipeImplementation(originalImplementation plessmodels.RecipeInterface, policy supertokens.NormalisedEmailPolicy) plessmodels.RecipeInterface {
+	originalCreateCode := *originalImplementation.CreateCode
+	originalConsumeCode := *originalImplementation.ConsumeCode
+	originalGetUserByEmail := *originalImplementation.GetUserByEmail
+	originalUpdateUser := *originalImplementation.UpdateUser
+	originalRevokeAllCodes := *originalImplementation.RevokeAllCodes
+	originalListCodesByEmail := *originalImplementation.ListCodesByEmail
+
+	findUserByEmail := func(email string, userContext supertokens.UserContext) (*plessmodels.User, string, error) {
+		var user *plessmodels.User
+		storedEmail, _, err := supertokens.FindStoredEmail(policy, email, func(email string) (bool, error) {
+			var err error
+			user, err = originalGetUserByEmail(email, userContext)
+			return user != nil, err
+		}, userContext)
+		return user, storedEmail, err
+	}
+
+	createCode := func(email *string, phoneNumber *string, userInputCode *string, userContext supertokens.UserContext) (plessmodels.CreateCodeResponse, error) {
+		if email != nil {
+			// the code is created for the email the user is stored under, so that consuming it signs them in
+			_, storedEmail, err := findUserByEmail(*email, userContext)
+			if err != nil {
+				return plessmodels.CreateCodeResponse{}, err
+			}
+			email = &storedEmail
+		}
+		return originalCreateCode(email, phoneNumber, userInputCode, userContext)
+	}
+
+	consumeCode := func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, userContext supertokens.UserContext) (plessmodels.ConsumeCodeResponse, error) {
+		response, err := originalConsumeCode(userInput, linkCode, preAuthSessionID, userContext)
+		if err != nil {
+			return plessmodels.ConsumeCodeResponse{}, err
+		}
+		if response.OK != nil && response.OK.CreatedNewUser && response.OK.User.Email != nil {
+			err = supertokens.StoreCanonicalEmail(policy, *response.OK.User.Email, userContext)
+			if err != nil {
+				return plessmodels.ConsumeCodeResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	getUserByEmail := func(email string, userContext supertokens.UserContext) (*plessmodels.User, error) {
+		user, _, err := findUserByEmail(email, userContext)
+		return user, err
+	}
+
+	updateUser := func(userID string, email *string, phoneNumber *string, userContext supertokens.UserContext) (plessmodels.UpdateUserResponse, error) {
+		if email == nil {
+			return originalUpdateUser(userID, email, phoneNumber, userContext)
+		}
+		existingUser, _, err := findUserByEmail(*email, userContext)
+		if err != nil {
+			return plessmodels.UpdateUserResponse{}, err
+		}
+		if existingUser != nil && existingUser.ID != userID {
+			return plessmodels.UpdateUserResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		normalisedEmail := supertokens.NormaliseEmail(policy, *email)
+		response, err := originalUpdateUser(userID, &normalisedEmail, phoneNumber, userContext)
+		if err != nil {
+			return plessmodels.UpdateUserResponse{}, err
+		}
+		if response.OK != nil {
+			err = supertokens.StoreCanonicalEmail(policy, normalisedEmail, userContext)
+			if err != nil {
+				return plessmodels.UpdateUserResponse{}, err
+			}
+		}
+		return response, nil
+	}
+
+	revokeAllCodes := func(email *string, phoneNumber *string, userContext supertokens.UserContext) error {
+		if email == nil {
+			return originalRevokeAllCodes(email, phoneNumber, userContext)
+		}
+		emails, err := supertokens.GetEmailsToLookUp(policy, *email, userContext)
+		if err != nil {
+			return err
+		}
+		for _, emailToRevoke := range emails {
+			emailToRevoke := emailToRevoke
+			err = originalRevokeAllCodes(&emailToRevoke, nil, userContext)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	listCodesByEmail := func(email string, userContext supertokens.UserContext) ([]plessmodels.DeviceType, error) {
+		emails, err := supertokens.GetEmailsToLookUp(policy, email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		devices := []plessmodels.DeviceType{}
+		for _, emailToList := range emails {
+			emailDevices, err := originalListCodesByEmail(emailToList, userContext)
+			if err != nil {
+				return nil, err
+			}
+			devices = append(devices, emailDevices...)
+		}
+		return devices, nil
+	}
+
+	*originalImplementation.CreateCode = createCode
+	*originalImplementation.ConsumeCode = consumeCode
+	*originalImplementation.GetUserByEmail = getUserByEmail
+	*originalImplementation.UpdateUser = updateUser
+	*originalImplementation.RevokeAllCodes = revokeAllCodes
+	*originalImplementation.ListCodesByEmail = listCodesByEmail
+	return originalImplementation
+}
@@ -31,6 +31,7 @@ type TypeInput struct {
 	FlowType                  string
 	GetLinkDomainAndPath      func(email *string, phoneNumber *string, userContext supertokens.UserContext) (string, error)
 	GetCustomUserInputCode    func(userContext supertokens.UserContext) (string, error)
+	EmailPolicy               *supertokens.EmailPolicy
 	Override                  *OverrideStruct
 }
 
@@ -41,6 +42,7 @@ type TypeNormalisedInput struct {
 	FlowType                  string
 	GetLinkDomainAndPath      func(email *string, phoneNumber *string, userContext supertokens.UserContext) (string, error)
 	GetCustomUserInputCode    func(userContext supertokens.UserContext) (string, error)
+	EmailPolicy               supertokens.NormalisedEmailPolicy
 	Override                  OverrideStruct
 }
 
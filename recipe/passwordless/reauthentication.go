@@ -0,0 +1,95 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// VerifyCodeForReauthentication is a ReauthenticationVerifier for the session recipe's AccountDeletion config.
+// The frontend first creates a code for the email or phone number of the user, and then sends the
+// "preAuthSessionId" along with either the "userInputCode" and "deviceId", or the "linkCode".
+func VerifyCodeForReauthentication() sessmodels.ReauthenticationVerifier {
+	return func(userID string, reauthentication map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
+		preAuthSessionID, ok := reauthentication["preAuthSessionId"].(string)
+		if !ok || preAuthSessionID == "" {
+			return false, nil
+		}
+		var userInput *plessmodels.UserInputCodeWithDeviceID
+		var linkCode *string
+		if code, ok := reauthentication["linkCode"].(string); ok && code != "" {
+			linkCode = &code
+		} else {
+			userInputCode, codeOk := reauthentication["userInputCode"].(string)
+			deviceID, deviceOk := reauthentication["deviceId"].(string)
+			if !codeOk || !deviceOk || userInputCode == "" || deviceID == "" {
+				return false, nil
+			}
+			userInput = &plessmodels.UserInputCodeWithDeviceID{
+				Code:     userInputCode,
+				DeviceID: deviceID,
+			}
+		}
+
+		instance, err := getRecipeInstanceOrThrowError()
+		if err != nil {
+			return false, err
+		}
+		user, err := (*instance.RecipeImpl.GetUserByID)(userID, userContext)
+		if err != nil {
+			return false, err
+		}
+		if user == nil {
+			return false, nil
+		}
+
+		// the code must have been sent to the user, otherwise consuming it could sign up someone else.
+		// A user can have both an email and a phone number, and the code may have been sent to either
+		var devices []plessmodels.DeviceType
+		if user.Email != nil {
+			emailDevices, err := (*instance.RecipeImpl.ListCodesByEmail)(*user.Email, userContext)
+			if err != nil {
+				return false, err
+			}
+			devices = append(devices, emailDevices...)
+		}
+		if user.PhoneNumber != nil {
+			phoneNumberDevices, err := (*instance.RecipeImpl.ListCodesByPhoneNumber)(*user.PhoneNumber, userContext)
+			if err != nil {
+				return false, err
+			}
+			devices = append(devices, phoneNumberDevices...)
+		}
+		sentToUser := false
+		for _, device := range devices {
+			if device.PreAuthSessionID == preAuthSessionID {
+				sentToUser = true
+				break
+			}
+		}
+		if !sentToUser {
+			return false, nil
+		}
+
+		response, err := (*instance.RecipeImpl.ConsumeCode)(userInput, linkCode, preAuthSessionID, userContext)
+		if err != nil {
+			return false, err
+		}
+		return response.OK != nil && response.OK.User.ID == userID, nil
+	}
+}
@@ -0,0 +1,94 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestVerifyCodeForReauthenticationWithEmailAndPhoneNumber(t *testing.T) {
+	sendCode := func(_ string, userInputCode *string, urlWithLinkCode *string, codeLifetime uint64, preAuthSessionId string, userContext supertokens.UserContext) error {
+		return nil
+	}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(plessmodels.TypeInput{
+				FlowType: "USER_INPUT_CODE",
+				ContactMethodEmailOrPhone: plessmodels.ContactMethodEmailOrPhoneConfig{
+					Enabled:                        true,
+					CreateAndSendCustomEmail:       sendCode,
+					CreateAndSendCustomTextMessage: sendCode,
+				},
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	signInUp, err := SignInUpByEmail("test@example.com")
+	assert.NoError(t, err)
+	userID := signInUp.User.ID
+	phoneNumber := "+14155552671"
+	updateResponse, err := UpdateUser(userID, nil, &phoneNumber)
+	assert.NoError(t, err)
+	assert.NotNil(t, updateResponse.OK)
+
+	verifier := VerifyCodeForReauthentication()
+	reauthenticate := func(code plessmodels.NewCode) bool {
+		verified, err := verifier(userID, map[string]interface{}{
+			"preAuthSessionId": code.PreAuthSessionID,
+			"userInputCode":    code.UserInputCode,
+			"deviceId":         code.DeviceID,
+		}, &map[string]interface{}{})
+		assert.NoError(t, err)
+		return verified
+	}
+
+	emailCode, err := CreateCodeWithEmail("test@example.com", nil)
+	assert.NoError(t, err)
+	assert.True(t, reauthenticate(*emailCode.OK))
+
+	// the user also has an email, but the code can be sent to their phone number
+	phoneNumberCode, err := CreateCodeWithPhoneNumber(phoneNumber, nil)
+	assert.NoError(t, err)
+	assert.True(t, reauthenticate(*phoneNumberCode.OK))
+
+	// codes sent to anyone else are not accepted
+	otherCode, err := CreateCodeWithEmail("other@example.com", nil)
+	assert.NoError(t, err)
+	assert.False(t, reauthenticate(*otherCode.OK))
+}
@@ -47,6 +47,9 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config p
 		return Recipe{}, err
 	}
 	recipeImplementation := MakeRecipeImplementation(*querierInstance)
+	if verifiedConfig.EmailPolicy.Enabled {
+		recipeImplementation = MakeEmailPolicyRecipeImplementation(recipeImplementation, verifiedConfig.EmailPolicy)
+	}
 	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 
 	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, r.handleError, onGeneralError)
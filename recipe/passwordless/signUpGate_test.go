@@ -0,0 +1,189 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package passwordless
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func postJSONRequest(testUrl string, path string, body map[string]interface{}) (map[string]interface{}, error) {
+	postBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Post(testUrl+path, "application/json", bytes.NewBuffer(postBody))
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]interface{}
+	err = json.Unmarshal(data, &response)
+	return response, err
+}
+
+func TestPhoneNumberInvitationsAreConsumedWithTheCode(t *testing.T) {
+	invitations := map[string]supertokens.Invitation{}
+	redeemedBy := []string{}
+	redeemedOnSessionCreation := []int{}
+	var lastUserInputCode string
+	sendCode := func(_ string, userInputCode *string, urlWithLinkCode *string, codeLifetime uint64, preAuthSessionId string, userContext supertokens.UserContext) error {
+		lastUserInputCode = *userInputCode
+		return nil
+	}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		SignUpGate: &supertokens.SignUpGate{
+			Mode: supertokens.SignUpModeInviteOnly,
+			Invitations: &supertokens.InvitationConfig{
+				CreateAndSendCustomSms: func(invitation supertokens.Invitation, invitationURLWithToken string, userContext supertokens.UserContext) {
+				},
+				SaveInvitation: func(tokenHash string, invitation supertokens.Invitation, userContext supertokens.UserContext) error {
+					invitations[tokenHash] = invitation
+					return nil
+				},
+				GetInvitation: func(tokenHash string, userContext supertokens.UserContext) (*supertokens.Invitation, error) {
+					invitation, ok := invitations[tokenHash]
+					if !ok {
+						return nil, nil
+					}
+					return &invitation, nil
+				},
+				ConsumeInvitation: func(tokenHash string, userContext supertokens.UserContext) (*supertokens.Invitation, error) {
+					invitation, ok := invitations[tokenHash]
+					if !ok {
+						return nil, nil
+					}
+					delete(invitations, tokenHash)
+					return &invitation, nil
+				},
+				RemoveInvitation: func(tokenHash string, userContext supertokens.UserContext) error {
+					delete(invitations, tokenHash)
+					return nil
+				},
+				OnInvitationRedeemed: func(userID string, invitation supertokens.Invitation, userContext supertokens.UserContext) error {
+					redeemedBy = append(redeemedBy, invitation.PhoneNumber)
+					return nil
+				},
+			},
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(plessmodels.TypeInput{
+				FlowType: "USER_INPUT_CODE",
+				ContactMethodPhone: plessmodels.ContactMethodPhoneConfig{
+					Enabled:                        true,
+					CreateAndSendCustomTextMessage: sendCode,
+				},
+			}),
+			session.Init(&sessmodels.TypeInput{
+				Override: &sessmodels.OverrideStruct{
+					Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+						originalCreateNewSession := *originalImplementation.CreateNewSession
+						*originalImplementation.CreateNewSession = func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+							redeemedOnSessionCreation = append(redeemedOnSessionCreation, len(redeemedBy))
+							return originalCreateNewSession(res, userID, accessTokenPayload, sessionData, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	createCode := func(phoneNumber string, inviteToken string) map[string]interface{} {
+		response, err := postJSONRequest(testServer.URL, "/auth/signinup/code", map[string]interface{}{
+			"phoneNumber": phoneNumber,
+			"inviteToken": inviteToken,
+		})
+		assert.NoError(t, err)
+		return response
+	}
+	consumeCode := func(code map[string]interface{}, userInputCode string, inviteToken string) map[string]interface{} {
+		response, err := postJSONRequest(testServer.URL, "/auth/signinup/code/consume", map[string]interface{}{
+			"preAuthSessionId": code["preAuthSessionId"],
+			"deviceId":         code["deviceId"],
+			"userInputCode":    userInputCode,
+			"inviteToken":      inviteToken,
+		})
+		assert.NoError(t, err)
+		return response
+	}
+
+	phoneNumber := "+14155552671"
+	inviteToken, err := supertokens.CreatePhoneNumberInvitation(phoneNumber, nil, nil)
+	assert.NoError(t, err)
+
+	// the invitation is tied to the phone number
+	response := createCode("+14155552672", inviteToken)
+	assert.Equal(t, "GENERAL_ERROR", response["status"])
+
+	code := createCode(phoneNumber, inviteToken)
+	assert.Equal(t, "OK", code["status"])
+	assert.Len(t, invitations, 1)
+
+	// a wrong code does not create the user, so the invitation can still be used
+	wrongUserInputCode := "000000"
+	if lastUserInputCode == wrongUserInputCode {
+		wrongUserInputCode = "111111"
+	}
+	response = consumeCode(code, wrongUserInputCode, inviteToken)
+	assert.Equal(t, "INCORRECT_USER_INPUT_CODE_ERROR", response["status"])
+	assert.Len(t, invitations, 1)
+
+	response = consumeCode(code, lastUserInputCode, inviteToken)
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, true, response["createdNewUser"])
+	assert.Empty(t, invitations)
+	assert.Equal(t, []string{phoneNumber}, redeemedBy)
+	// the invitation was redeemed before the session of the new user was created
+	assert.Equal(t, []int{1}, redeemedOnSessionCreation)
+
+	// the invitation cannot be used again
+	response = createCode("+14155552673", inviteToken)
+	assert.Equal(t, "GENERAL_ERROR", response["status"])
+}
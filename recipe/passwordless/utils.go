@@ -106,6 +106,14 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	// GetCustomUserInputCode is initialized correctly in makeTypeNormalisedInput
 
+	if config.EmailPolicy != nil {
+		emailPolicy, err := supertokens.NormaliseEmailPolicyOrThrowError(config.EmailPolicy)
+		if err != nil {
+			panic(err.Error())
+		}
+		typeNormalisedInput.EmailPolicy = emailPolicy
+	}
+
 	if config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
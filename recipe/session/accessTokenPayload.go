@@ -0,0 +1,135 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// buildAccessTokenPayload runs the BuildAccessTokenPayload hook from the config, if there is one. The keys added by
+// the SDK are not passed to the hook, and are kept in the result unless the hook sets them. The second return value
+// is true if the hook changed the payload.
+func buildAccessTokenPayload(config sessmodels.TypeNormalisedInput, userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, bool, error) {
+	if config.BuildAccessTokenPayload == nil {
+		return accessTokenPayload, false, nil
+	}
+	currentAccessTokenPayload := removeSDKKeysFromAccessTokenPayload(accessTokenPayload)
+	newAccessTokenPayload, err := config.BuildAccessTokenPayload(userID, currentAccessTokenPayload, userContext)
+	if err != nil {
+		return nil, false, err
+	}
+	if newAccessTokenPayload == nil {
+		newAccessTokenPayload = map[string]interface{}{}
+	}
+	changed, err := isAccessTokenPayloadChanged(currentAccessTokenPayload, newAccessTokenPayload)
+	if err != nil {
+		return nil, false, err
+	}
+	if !changed {
+		return accessTokenPayload, false, nil
+	}
+	return copyReservedKeysToAccessTokenPayload(accessTokenPayload, newAccessTokenPayload), true, nil
+}
+
+// buildAccessTokenPayloadOfNewSession runs the BuildAccessTokenPayload hook for a session that is about to be
+// created, and marks the user context so that CreateNewSession does not run the hook again. It is used by
+// sessionwithjwt, which needs the built payload to create the JWT before the session is created.
+func buildAccessTokenPayloadOfNewSession(config sessmodels.TypeNormalisedInput) func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+	return func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+		accessTokenPayload, _, err := buildAccessTokenPayload(config, userID, accessTokenPayload, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if userContext != nil {
+			(*userContext)[accessTokenPayloadBuiltUserContextKey] = true
+		}
+		return accessTokenPayload, nil
+	}
+}
+
+// isAccessTokenPayloadBuilt returns true once for a user context marked by buildAccessTokenPayloadOfNewSession
+func isAccessTokenPayloadBuilt(userContext supertokens.UserContext) bool {
+	if userContext == nil {
+		return false
+	}
+	built, _ := (*userContext)[accessTokenPayloadBuiltUserContextKey].(bool)
+	delete(*userContext, accessTokenPayloadBuiltUserContextKey)
+	return built
+}
+
+// isAccessTokenPayloadChanged compares the JSON encoding of the payloads, since numbers read back from the core are
+// always float64 while the hook may return other number types
+func isAccessTokenPayloadChanged(currentAccessTokenPayload map[string]interface{}, newAccessTokenPayload map[string]interface{}) (bool, error) {
+	currentBytes, err := json.Marshal(currentAccessTokenPayload)
+	if err != nil {
+		return false, err
+	}
+	newBytes, err := json.Marshal(newAccessTokenPayload)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(currentBytes, newBytes), nil
+}
+
+// validateAccessTokenPayloadSize must be called with the payload that is sent to the core, after it is encrypted
+func validateAccessTokenPayloadSize(config sessmodels.TypeNormalisedInput, accessTokenPayload map[string]interface{}) error {
+	if config.MaxAccessTokenPayloadSizeBytes == 0 {
+		return nil
+	}
+	payloadBytes, err := json.Marshal(accessTokenPayload)
+	if err != nil {
+		return err
+	}
+	size := uint64(len(payloadBytes))
+	if size > config.MaxAccessTokenPayloadSizeBytes {
+		return errors.AccessTokenPayloadTooLargeError{
+			Msg:     "the access token payload is " + strconv.FormatUint(size, 10) + " bytes, which is more than the maximum of " + strconv.FormatUint(config.MaxAccessTokenPayloadSizeBytes, 10) + " bytes. Please store large values in the session data instead",
+			Size:    size,
+			MaxSize: config.MaxAccessTokenPayloadSizeBytes,
+		}
+	}
+	return nil
+}
+
+// validateReservedAccessTokenPayloadKeys makes sure that the user does not set or change the keys the SDK
+// relies on, like the sign in time of the session or the impersonator of the user. Keys that have the same value
+// as in the existing payload are allowed, so that the result of GetAccessTokenPayload can be passed back.
+func validateReservedAccessTokenPayloadKeys(existingAccessTokenPayload map[string]interface{}, newAccessTokenPayload map[string]interface{}) error {
+	for _, key := range reservedAccessTokenPayloadKeys {
+		newValue, ok := newAccessTokenPayload[key]
+		if !ok {
+			continue
+		}
+		existingValue, ok := existingAccessTokenPayload[key]
+		if ok {
+			changed, err := isAccessTokenPayloadChanged(map[string]interface{}{key: existingValue}, map[string]interface{}{key: newValue})
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+		}
+		return errors.SchemaValidationError{Msg: "the access token payload key '" + key + "' is used by the SDK and cannot be set"}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/base64"
+	defaultErrors "errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestBuildAccessTokenPayload(t *testing.T) {
+	var receivedUserID string
+	var receivedAccessTokenPayload map[string]interface{}
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		BuildAccessTokenPayload: func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+			receivedUserID = userID
+			receivedAccessTokenPayload = currentAccessTokenPayload
+			return map[string]interface{}{
+				"plan":  currentAccessTokenPayload["plan"],
+				"roles": []string{"admin"},
+			}, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	accessTokenPayload, changed, err := buildAccessTokenPayload(config, "userId", map[string]interface{}{
+		"plan":                   "pro",
+		"roles":                  []interface{}{"editor"},
+		impersonatedByPayloadKey: "admin",
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "userId", receivedUserID)
+	// the keys added by the SDK are not passed to the hook, but are kept
+	assert.Equal(t, map[string]interface{}{"plan": "pro", "roles": []interface{}{"editor"}}, receivedAccessTokenPayload)
+	assert.Equal(t, map[string]interface{}{
+		"plan":                   "pro",
+		"roles":                  []string{"admin"},
+		impersonatedByPayloadKey: "admin",
+	}, accessTokenPayload)
+
+	// the payload read back from the core has float64 numbers, which must not count as a change
+	_, changed, err = buildAccessTokenPayload(config, "userId", map[string]interface{}{
+		"plan":  "pro",
+		"roles": []interface{}{"admin"},
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestBuildAccessTokenPayloadWithoutHook(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", nil)
+	assert.NoError(t, err)
+
+	accessTokenPayload := map[string]interface{}{"plan": "pro"}
+	result, changed, err := buildAccessTokenPayload(config, "userId", accessTokenPayload, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, accessTokenPayload, result)
+}
+
+func TestBuildAccessTokenPayloadError(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		BuildAccessTokenPayload: func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+			return nil, defaultErrors.New("could not load roles")
+		},
+	})
+	assert.NoError(t, err)
+
+	_, _, err = buildAccessTokenPayload(config, "userId", nil, &map[string]interface{}{})
+	assert.EqualError(t, err, "could not load roles")
+}
+
+func TestBuildAccessTokenPayloadOfNewSession(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		BuildAccessTokenPayload: func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+			return map[string]interface{}{"plan": "pro"}, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	userContext := &map[string]interface{}{}
+	assert.False(t, isAccessTokenPayloadBuilt(userContext))
+	accessTokenPayload, err := buildAccessTokenPayloadOfNewSession(config)("userId", map[string]interface{}{}, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"plan": "pro"}, accessTokenPayload)
+	assert.True(t, isAccessTokenPayloadBuilt(userContext))
+	// the mark only applies to the next session that is created
+	assert.False(t, isAccessTokenPayloadBuilt(userContext))
+}
+
+func TestValidateAccessTokenPayloadSize(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), config.MaxAccessTokenPayloadSizeBytes)
+	assert.NoError(t, validateAccessTokenPayloadSize(config, map[string]interface{}{"value": strings.Repeat("a", 2048)}))
+
+	maxAccessTokenPayloadSizeBytes := uint64(2048)
+	config, err = getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		MaxAccessTokenPayloadSizeBytes: &maxAccessTokenPayloadSizeBytes,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, validateAccessTokenPayloadSize(config, map[string]interface{}{"roles": []string{"admin"}}))
+
+	err = validateAccessTokenPayloadSize(config, map[string]interface{}{"value": strings.Repeat("a", 2048)})
+	assert.True(t, defaultErrors.As(err, &errors.AccessTokenPayloadTooLargeError{}))
+	assert.Equal(t, uint64(2060), err.(errors.AccessTokenPayloadTooLargeError).Size)
+	assert.Equal(t, uint64(2048), err.(errors.AccessTokenPayloadTooLargeError).MaxSize)
+
+}
+
+func TestAccessTokenPayloadSizeIsCheckedBeforeCreatingSession(t *testing.T) {
+	maxAccessTokenPayloadSizeBytes := uint64(1500)
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				Jwt: &sessmodels.JWTInputConfig{
+					Enable: true,
+				},
+				BuildAccessTokenPayload: func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+					if currentAccessTokenPayload["large"] == true {
+						return map[string]interface{}{"value": strings.Repeat("a", 1000)}, nil
+					}
+					return map[string]interface{}{"plan": "pro"}, nil
+				},
+				MaxAccessTokenPayloadSizeBytes: &maxAccessTokenPayloadSizeBytes,
+				SessionLimit: &sessmodels.SessionLimitInputConfig{
+					MaxSessionsPerUser: 1,
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	session, err := CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	// the JWT is created from the built payload
+	accessTokenPayload := session.GetAccessTokenPayload()
+	assert.Equal(t, "pro", accessTokenPayload["plan"])
+	jwt, ok := accessTokenPayload["jwt"].(string)
+	assert.True(t, ok)
+	jwtPayload, err := base64.RawURLEncoding.DecodeString(strings.Split(jwt, ".")[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(jwtPayload), `"plan":"pro"`)
+
+	// with the JWT, the payload is larger than the limit. This must neither revoke the existing session nor create
+	// a new one
+	_, err = CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{"large": true}, map[string]interface{}{})
+	assert.True(t, defaultErrors.As(err, &errors.AccessTokenPayloadTooLargeError{}))
+
+	sessionHandles, err := GetAllSessionHandlesForUser("user")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{session.GetHandle()}, sessionHandles)
+}
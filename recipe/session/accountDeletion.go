@@ -0,0 +1,100 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func validateAndNormaliseAccountDeletionConfig(config sessmodels.AccountDeletionInputConfig) (sessmodels.AccountDeletionNormalisedConfig, error) {
+	if config.VerifyReauthentication == nil {
+		return sessmodels.AccountDeletionNormalisedConfig{}, errors.New("accountDeletion verifyReauthentication must be provided")
+	}
+	if config.SetPendingAccountDeletion == nil || config.GetPendingAccountDeletion == nil || config.RemovePendingAccountDeletion == nil || config.GetDueAccountDeletions == nil {
+		return sessmodels.AccountDeletionNormalisedConfig{}, errors.New("accountDeletion setPendingAccountDeletion, getPendingAccountDeletion, removePendingAccountDeletion and getDueAccountDeletions must be provided")
+	}
+	gracePeriodMS := defaultAccountDeletionGracePeriodMS
+	if config.GracePeriodMS != nil {
+		gracePeriodMS = *config.GracePeriodMS
+	}
+	return sessmodels.AccountDeletionNormalisedConfig{
+		Enable:                       true,
+		GracePeriodMS:                gracePeriodMS,
+		VerifyReauthentication:       config.VerifyReauthentication,
+		SetPendingAccountDeletion:    config.SetPendingAccountDeletion,
+		GetPendingAccountDeletion:    config.GetPendingAccountDeletion,
+		RemovePendingAccountDeletion: config.RemovePendingAccountDeletion,
+		GetDueAccountDeletions:       config.GetDueAccountDeletions,
+		OnAccountDeletionScheduled:   config.OnAccountDeletionScheduled,
+		OnAccountDeletionCancelled:   config.OnAccountDeletionCancelled,
+		OnBeforeAccountDeletion:      config.OnBeforeAccountDeletion,
+	}, nil
+}
+
+// purgeDueAccountDeletions deletes the users whose grace period is over and returns their IDs. A user whose
+// deletion fails is skipped, so that one failure does not block the others, and the first error is returned.
+func purgeDueAccountDeletions(config sessmodels.TypeNormalisedInput, now uint64, deleteUser func(userID string) error, userContext supertokens.UserContext) ([]string, error) {
+	if !config.AccountDeletion.Enable {
+		return nil, errors.New("account deletion is not enabled. Please provide the AccountDeletion config when initialising the session recipe")
+	}
+	userIDs, err := config.AccountDeletion.GetDueAccountDeletions(now, userContext)
+	if err != nil {
+		return nil, err
+	}
+	deletedUserIDs := []string{}
+	var firstErr error
+	for _, userID := range userIDs {
+		err := purgeAccountDeletion(config, now, deleteUser, userID, userContext)
+		if err == errAccountDeletionNotDue {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		deletedUserIDs = append(deletedUserIDs, userID)
+	}
+	return deletedUserIDs, firstErr
+}
+
+var errAccountDeletionNotDue = errors.New("account deletion is not due")
+
+func purgeAccountDeletion(config sessmodels.TypeNormalisedInput, now uint64, deleteUser func(userID string) error, userID string, userContext supertokens.UserContext) error {
+	// the user may have signed in again since the due deletions were listed
+	deletionTime, err := config.AccountDeletion.GetPendingAccountDeletion(userID, userContext)
+	if err != nil {
+		return err
+	}
+	if deletionTime == nil || *deletionTime > now {
+		return errAccountDeletionNotDue
+	}
+	if config.AccountDeletion.OnBeforeAccountDeletion != nil {
+		err = config.AccountDeletion.OnBeforeAccountDeletion(userID, userContext)
+		if err != nil {
+			return err
+		}
+	}
+	err = deleteUser(userID)
+	if err != nil {
+		return err
+	}
+	return config.AccountDeletion.RemovePendingAccountDeletion(userID, userContext)
+}
@@ -0,0 +1,250 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func getAccountDeletionConfigForTests(pending map[string]uint64) *sessmodels.AccountDeletionInputConfig {
+	return &sessmodels.AccountDeletionInputConfig{
+		VerifyReauthentication: func(userID string, reauthentication map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
+			return reauthentication["password"] == "password", nil
+		},
+		SetPendingAccountDeletion: func(userID string, deletionTime uint64, userContext supertokens.UserContext) error {
+			pending[userID] = deletionTime
+			return nil
+		},
+		GetPendingAccountDeletion: func(userID string, userContext supertokens.UserContext) (*uint64, error) {
+			deletionTime, ok := pending[userID]
+			if !ok {
+				return nil, nil
+			}
+			return &deletionTime, nil
+		},
+		RemovePendingAccountDeletion: func(userID string, userContext supertokens.UserContext) error {
+			delete(pending, userID)
+			return nil
+		},
+		GetDueAccountDeletions: func(now uint64, userContext supertokens.UserContext) ([]string, error) {
+			userIDs := []string{}
+			for userID, deletionTime := range pending {
+				if deletionTime <= now {
+					userIDs = append(userIDs, userID)
+				}
+			}
+			return userIDs, nil
+		},
+	}
+}
+
+func TestAccountDeletionIsDisabledByDefault(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", nil)
+	assert.NoError(t, err)
+	assert.False(t, config.AccountDeletion.Enable)
+
+	_, err = purgeDueAccountDeletions(config, 1000, func(userID string) error { return nil }, &map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestAccountDeletionConfigDefaultsAndValidation(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: getAccountDeletionConfigForTests(map[string]uint64{}),
+	})
+	assert.NoError(t, err)
+	assert.True(t, config.AccountDeletion.Enable)
+	assert.Equal(t, uint64(30*24*60*60*1000), config.AccountDeletion.GracePeriodMS)
+
+	gracePeriod := uint64(1000)
+	accountDeletion := getAccountDeletionConfigForTests(map[string]uint64{})
+	accountDeletion.GracePeriodMS = &gracePeriod
+	config, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: accountDeletion,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), config.AccountDeletion.GracePeriodMS)
+
+	accountDeletion = getAccountDeletionConfigForTests(map[string]uint64{})
+	accountDeletion.VerifyReauthentication = nil
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: accountDeletion,
+	})
+	assert.Error(t, err)
+
+	accountDeletion = getAccountDeletionConfigForTests(map[string]uint64{})
+	accountDeletion.GetDueAccountDeletions = nil
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: accountDeletion,
+	})
+	assert.Error(t, err)
+}
+
+func TestPurgeDueAccountDeletions(t *testing.T) {
+	pending := map[string]uint64{
+		"due":     500,
+		"not-due": 2000,
+	}
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: getAccountDeletionConfigForTests(pending),
+	})
+	assert.NoError(t, err)
+
+	deleted := []string{}
+	userIDs, err := purgeDueAccountDeletions(config, 1000, func(userID string) error {
+		deleted = append(deleted, userID)
+		return nil
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"due"}, userIDs)
+	assert.Equal(t, []string{"due"}, deleted)
+	_, ok := pending["due"]
+	assert.False(t, ok)
+	assert.Equal(t, uint64(2000), pending["not-due"])
+}
+
+func TestPurgeDueAccountDeletionsSkipsUsersThatFailToDelete(t *testing.T) {
+	pending := map[string]uint64{
+		"app-data-fails": 500,
+		"deleted":        500,
+	}
+	accountDeletion := getAccountDeletionConfigForTests(pending)
+	accountDeletion.OnBeforeAccountDeletion = func(userID string, userContext supertokens.UserContext) error {
+		if userID == "app-data-fails" {
+			return errors.New("could not delete app data")
+		}
+		return nil
+	}
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: accountDeletion,
+	})
+	assert.NoError(t, err)
+
+	deleted := []string{}
+	userIDs, err := purgeDueAccountDeletions(config, 1000, func(userID string) error {
+		deleted = append(deleted, userID)
+		return nil
+	}, &map[string]interface{}{})
+	assert.EqualError(t, err, "could not delete app data")
+	assert.Equal(t, []string{"deleted"}, userIDs)
+	assert.Equal(t, []string{"deleted"}, deleted)
+	// the failed user stays pending so that the next purge tries again
+	assert.Equal(t, uint64(500), pending["app-data-fails"])
+}
+
+func TestPurgeDueAccountDeletionsSkipsCancelledDeletions(t *testing.T) {
+	pending := map[string]uint64{}
+	accountDeletion := getAccountDeletionConfigForTests(pending)
+	accountDeletion.GetDueAccountDeletions = func(now uint64, userContext supertokens.UserContext) ([]string, error) {
+		// listed as due, but the user signed in again before the purge reached them
+		return []string{"cancelled"}, nil
+	}
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		AccountDeletion: accountDeletion,
+	})
+	assert.NoError(t, err)
+
+	userIDs, err := purgeDueAccountDeletions(config, 1000, func(userID string) error {
+		t.Fatal("deleteUser should not be called")
+		return nil
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, userIDs)
+}
+
+func TestAccountDeletionAPIKeepsTheSessionIfSchedulingFails(t *testing.T) {
+	pending := map[string]uint64{}
+	failScheduling := true
+	accountDeletion := getAccountDeletionConfigForTests(pending)
+	setPendingAccountDeletion := accountDeletion.SetPendingAccountDeletion
+	accountDeletion.SetPendingAccountDeletion = func(userID string, deletionTime uint64, userContext supertokens.UserContext) error {
+		if failScheduling {
+			return errors.New("storage is down")
+		}
+		return setPendingAccountDeletion(userID, deletionTime, userContext)
+	}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				AccountDeletion: accountDeletion,
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSession(rw, "rope", map[string]interface{}{}, map[string]interface{}{})
+		if err != nil {
+			rw.WriteHeader(500)
+		}
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	deleteAccount := func() *http.Response {
+		req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/user/delete", bytes.NewBufferString(`{"password":"password"}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+		req.Header.Add("anti-csrf", cookieData["antiCsrf"])
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res = deleteAccount()
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Empty(t, pending)
+	sessionHandles, err := GetAllSessionHandlesForUser("rope")
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 1)
+
+	failScheduling = false
+	res = deleteAccount()
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Contains(t, pending, "rope")
+	sessionHandles, err = GetAllSessionHandlesForUser("rope")
+	assert.NoError(t, err)
+	assert.Empty(t, sessionHandles)
+}
@@ -0,0 +1,119 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func isValidAntiCsrfMode(antiCsrf string) bool {
+	return antiCsrf == antiCSRF_NONE || antiCsrf == antiCSRF_VIA_CUSTOM_HEADER || antiCsrf == antiCSRF_VIA_TOKEN ||
+		antiCsrf == antiCSRF_VIA_ORIGIN || antiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE
+}
+
+// getAntiCsrfModeForRoute returns the anti-csrf mode set in the verify session options, falling back to the one
+// in the recipe config. VIA_TOKEN and VIA_DOUBLE_SUBMIT_COOKIE need a token to be issued with the session, so a
+// route can only use them if they are also used in the recipe config.
+func getAntiCsrfModeForRoute(config sessmodels.TypeNormalisedInput, options *sessmodels.VerifySessionOptions) (string, error) {
+	if options == nil || options.AntiCsrf == nil {
+		return config.AntiCsrf, nil
+	}
+	antiCsrf := *options.AntiCsrf
+	if !isValidAntiCsrfMode(antiCsrf) {
+		return "", errors.New("antiCsrf in verify session options must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN' or 'VIA_ORIGIN' or 'VIA_DOUBLE_SUBMIT_COOKIE'")
+	}
+	if (antiCsrf == antiCSRF_VIA_TOKEN || antiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE) && antiCsrf != config.AntiCsrf {
+		return "", errors.New("antiCsrf in verify session options can be set to '" + antiCsrf + "' only if it is also set in the session recipe config")
+	}
+	return antiCsrf, nil
+}
+
+// normaliseOrigin returns the scheme and host of the input in lower case, without the default port of the scheme
+func normaliseOrigin(input string) (string, error) {
+	urlObj, err := url.Parse(strings.TrimSpace(input))
+	if err != nil {
+		return "", err
+	}
+	scheme := strings.ToLower(urlObj.Scheme)
+	host := strings.ToLower(urlObj.Host)
+	if (scheme != "http" && scheme != "https") || host == "" {
+		return "", errors.New("please provide a valid origin")
+	}
+	if (scheme == "https" && strings.HasSuffix(host, ":443")) || (scheme == "http" && strings.HasSuffix(host, ":80")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	return scheme + "://" + host, nil
+}
+
+// getOriginOfRequest uses the Origin header, or the Referer header if the browser did not send the Origin
+func getOriginOfRequest(req *http.Request) *string {
+	for _, header := range []string{"Origin", "Referer"} {
+		value := getHeader(req, header)
+		if value == nil || *value == "null" {
+			continue
+		}
+		origin, err := normaliseOrigin(*value)
+		if err == nil {
+			return &origin
+		}
+	}
+	return nil
+}
+
+// doAntiCsrfCheckForRequest runs the anti-csrf checks that are done by the SDK instead of the core.
+// The VIA_TOKEN and VIA_CUSTOM_HEADER modes are checked in getSessionHelper and refreshSessionHelper.
+func doAntiCsrfCheckForRequest(config sessmodels.TypeNormalisedInput, antiCsrf string, req *http.Request) error {
+	if antiCsrf == antiCSRF_VIA_ORIGIN {
+		origin := getOriginOfRequest(req)
+		if origin == nil {
+			return errors.New("anti-csrf check failed. The request has no Origin or Referer header")
+		}
+		if !containsString(config.AntiCsrfAllowedOrigins, *origin) {
+			return errors.New("anti-csrf check failed. The origin " + *origin + " is not allowed")
+		}
+	} else if antiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE {
+		tokenFromHeader := getAntiCsrfTokenFromHeaders(req)
+		tokenFromCookie := getAntiCsrfTokenFromCookie(config, req)
+		if tokenFromHeader == nil || tokenFromCookie == nil || *tokenFromCookie == "" {
+			return errors.New("anti-csrf check failed. Please pass the anti-csrf token in the request header")
+		}
+		if subtle.ConstantTimeCompare([]byte(*tokenFromHeader), []byte(*tokenFromCookie)) != 1 {
+			return errors.New("anti-csrf check failed")
+		}
+	}
+	return nil
+}
+
+// generateDoubleSubmitAntiCsrfToken creates the token that is set in the anti-csrf cookie and header when a session
+// is created or refreshed. The cookie is http only, the frontend SDK saves the token from the header and sends it
+// back in the anti-csrf header just like it does for VIA_TOKEN.
+func generateDoubleSubmitAntiCsrfToken() (*string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	antiCsrfToken := hex.EncodeToString(b)
+	return &antiCsrfToken, nil
+}
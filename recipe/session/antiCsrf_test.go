@@ -0,0 +1,168 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func TestOriginAntiCsrfCheck(t *testing.T) {
+	antiCsrf := antiCSRF_VIA_ORIGIN
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		AntiCsrf:               &antiCsrf,
+		AntiCsrfAllowedOrigins: []string{"https://app.supertokens.io:443", "http://localhost:3000"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		allowed bool
+	}{
+		{"same origin", map[string]string{"Origin": "https://supertokens.io"}, true},
+		{"origin is case insensitive", map[string]string{"Origin": "HTTPS://SuperTokens.io"}, true},
+		{"allowlisted origin", map[string]string{"Origin": "https://app.supertokens.io"}, true},
+		{"allowlisted origin with port", map[string]string{"Origin": "http://localhost:3000"}, true},
+		{"cross site origin", map[string]string{"Origin": "https://evil.com"}, false},
+		{"sibling subdomain", map[string]string{"Origin": "https://evil.supertokens.io"}, false},
+		{"lookalike domain", map[string]string{"Origin": "https://supertokens.io.evil.com"}, false},
+		{"different scheme", map[string]string{"Origin": "http://supertokens.io"}, false},
+		{"different port", map[string]string{"Origin": "http://localhost:3001"}, false},
+		{"referer fallback", map[string]string{"Referer": "https://supertokens.io/dashboard?a=b"}, true},
+		{"cross site referer", map[string]string{"Referer": "https://evil.com/https://supertokens.io"}, false},
+		{"null origin with referer", map[string]string{"Origin": "null", "Referer": "https://supertokens.io/"}, true},
+		{"null origin", map[string]string{"Origin": "null"}, false},
+		{"origin takes precedence over referer", map[string]string{"Origin": "https://evil.com", "Referer": "https://supertokens.io/"}, false},
+		{"no origin or referer", map[string]string{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for key, value := range test.headers {
+				req.Header.Set(key, value)
+			}
+			err := doAntiCsrfCheckForRequest(config, antiCSRF_VIA_ORIGIN, req)
+			assert.Equal(t, test.allowed, err == nil)
+		})
+	}
+}
+
+func TestDoubleSubmitCookieAntiCsrfCheck(t *testing.T) {
+	antiCsrf := antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		AntiCsrf: &antiCsrf,
+	})
+	assert.NoError(t, err)
+
+	antiCsrfToken, err := generateDoubleSubmitAntiCsrfToken()
+	assert.NoError(t, err)
+	res := httptest.NewRecorder()
+	attachCreateOrRefreshSessionResponseToRes(config, res, sessmodels.CreateOrRefreshAPIResponse{
+		AccessToken:    sessmodels.CreateOrRefreshAPIResponseToken{Token: "accessToken", Expiry: 1000},
+		RefreshToken:   sessmodels.CreateOrRefreshAPIResponseToken{Token: "refreshToken", Expiry: 1000},
+		IDRefreshToken: sessmodels.CreateOrRefreshAPIResponseToken{Token: "idRefreshToken", Expiry: 1000},
+		AntiCsrfToken:  antiCsrfToken,
+	})
+	token := res.Header().Get(antiCsrfHeaderKey)
+	assert.Equal(t, 64, len(token))
+	assert.Equal(t, *antiCsrfToken, token)
+	var antiCsrfCookie *http.Cookie
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == antiCsrfCookieKey {
+			antiCsrfCookie = cookie
+		}
+	}
+	assert.NotNil(t, antiCsrfCookie)
+	assert.Equal(t, token, antiCsrfCookie.Value)
+	assert.True(t, antiCsrfCookie.HttpOnly)
+
+	tests := []struct {
+		name        string
+		cookieValue *string
+		headerValue *string
+		allowed     bool
+	}{
+		{"matching cookie and header", &token, &token, true},
+		{"cross site request without header", &token, nil, false},
+		{"header without cookie", nil, &token, false},
+		{"mismatched header", &token, stringPointer("attacker-token"), false},
+		{"empty cookie and header", stringPointer(""), stringPointer(""), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if test.cookieValue != nil {
+				req.AddCookie(&http.Cookie{Name: antiCsrfCookieKey, Value: *test.cookieValue})
+			}
+			if test.headerValue != nil {
+				req.Header.Set(antiCsrfHeaderKey, *test.headerValue)
+			}
+			err := doAntiCsrfCheckForRequest(config, antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE, req)
+			assert.Equal(t, test.allowed, err == nil)
+		})
+	}
+}
+
+func TestAntiCsrfModeForRoute(t *testing.T) {
+	antiCsrf := antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		AntiCsrf: &antiCsrf,
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		options  *sessmodels.VerifySessionOptions
+		expected string
+		isError  bool
+	}{
+		{"no options", nil, antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE, false},
+		{"no override", &sessmodels.VerifySessionOptions{}, antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE, false},
+		{"origin override", &sessmodels.VerifySessionOptions{AntiCsrf: stringPointer(antiCSRF_VIA_ORIGIN)}, antiCSRF_VIA_ORIGIN, false},
+		{"custom header override", &sessmodels.VerifySessionOptions{AntiCsrf: stringPointer(antiCSRF_VIA_CUSTOM_HEADER)}, antiCSRF_VIA_CUSTOM_HEADER, false},
+		{"none override", &sessmodels.VerifySessionOptions{AntiCsrf: stringPointer(antiCSRF_NONE)}, antiCSRF_NONE, false},
+		{"token override without token in config", &sessmodels.VerifySessionOptions{AntiCsrf: stringPointer(antiCSRF_VIA_TOKEN)}, "", true},
+		{"invalid override", &sessmodels.VerifySessionOptions{AntiCsrf: stringPointer("VIA_MAGIC")}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := getAntiCsrfModeForRoute(config, test.options)
+			assert.Equal(t, test.isError, err != nil)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestInvalidAntiCsrfAllowedOrigin(t *testing.T) {
+	antiCsrf := antiCSRF_VIA_ORIGIN
+	_, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		AntiCsrf:               &antiCsrf,
+		AntiCsrfAllowedOrigins: []string{"supertokens.io"},
+	})
+	assert.Error(t, err)
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
@@ -0,0 +1,61 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func AccountDeletionAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.AccountDeletionPOST == nil || (*apiImplementation.AccountDeletionPOST) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	// the body holds the credentials used to reauthenticate, for example the password of the user
+	var reauthentication map[string]interface{}
+	err = json.Unmarshal(body, &reauthentication)
+	if err != nil || reauthentication == nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON input"}
+	}
+
+	response, err := (*apiImplementation.AccountDeletionPOST)(reauthentication, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":       "OK",
+			"deletionTime": response.OK.DeletionTime,
+		})
+	} else if response.ReauthenticationFailedError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "REAUTHENTICATION_FAILED_ERROR",
+		})
+	} else {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "ACCOUNT_DELETION_NOT_ALLOWED_ERROR",
+		})
+	}
+}
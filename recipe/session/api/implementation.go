@@ -30,6 +30,21 @@ func MakeAPIImplementation() sessmodels.APIInterface {
 		return err
 	}
 
+	refreshGET := func(redirectToPath string, options sessmodels.APIOptions, userContext supertokens.UserContext) error {
+		redirectToPath = NormaliseRedirectToPath(redirectToPath)
+		options.Res.Header().Set("Cache-Control", "no-store")
+		_, err := (*options.RecipeImplementation.RefreshSession)(options.Req, options.Res, userContext)
+		if err != nil {
+			if defaultErrors.As(err, &errors.UnauthorizedError{}) {
+				http.Redirect(options.Res, options.Req, GetLoginPageURL(options.Config, redirectToPath), http.StatusFound)
+				return nil
+			}
+			return err
+		}
+		http.Redirect(options.Res, options.Req, redirectToPath, http.StatusFound)
+		return nil
+	}
+
 	verifySession := func(verifySessionOptions *sessmodels.VerifySessionOptions, options sessmodels.APIOptions, userContext supertokens.UserContext) (*sessmodels.SessionContainer, error) {
 		method := options.Req.Method
 		if method == http.MethodOptions || method == http.MethodTrace {
@@ -74,9 +89,112 @@ func MakeAPIImplementation() sessmodels.APIInterface {
 		}, nil
 	}
 
+	sessionTransferPOST := func(targetOrigin string, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionTransferPOSTResponse, error) {
+		session, err := (*options.RecipeImplementation.GetSession)(options.Req, options.Res, nil, userContext)
+		if err != nil {
+			return sessmodels.SessionTransferPOSTResponse{}, err
+		}
+		if session == nil {
+			return sessmodels.SessionTransferPOSTResponse{}, defaultErrors.New("session is nil. Should not come here")
+		}
+		if session.IsImpersonatedWithContext(userContext) || session.IsGuestWithContext(userContext) {
+			return sessmodels.SessionTransferPOSTResponse{
+				SessionNotTransferableError: &struct{}{},
+			}, nil
+		}
+
+		response, err := (*options.RecipeImplementation.CreateSessionTransferToken)(session.GetUserIDWithContext(userContext), targetOrigin, userContext)
+		if err != nil {
+			return sessmodels.SessionTransferPOSTResponse{}, err
+		}
+		if response.TargetOriginNotAllowedError != nil {
+			return sessmodels.SessionTransferPOSTResponse{
+				TargetOriginNotAllowedError: &struct{}{},
+			}, nil
+		}
+		return sessmodels.SessionTransferPOSTResponse{
+			OK: &struct{ TransferToken string }{
+				TransferToken: response.OK.TransferToken,
+			},
+		}, nil
+	}
+
+	sessionTransferExchangePOST := func(transferToken string, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.SessionTransferExchangePOSTResponse, error) {
+		// the transfer token is only accepted from the website it was created for, so that another site cannot
+		// sign a user in to an account of its choosing. Requests without an Origin header are rejected as well,
+		// since it cannot be known where they came from
+		if options.Req.Header.Get("Origin") != options.Config.SessionTransfer.Origin {
+			return sessmodels.SessionTransferExchangePOSTResponse{
+				InvalidTransferTokenError: &struct{}{},
+			}, nil
+		}
+
+		response, err := (*options.RecipeImplementation.ExchangeSessionTransferToken)(options.Res, transferToken, userContext)
+		if err != nil {
+			return sessmodels.SessionTransferExchangePOSTResponse{}, err
+		}
+		if response.InvalidTransferTokenError != nil {
+			return sessmodels.SessionTransferExchangePOSTResponse{
+				InvalidTransferTokenError: &struct{}{},
+			}, nil
+		}
+		return sessmodels.SessionTransferExchangePOSTResponse{
+			OK: &struct{ Session sessmodels.SessionContainer }{
+				Session: response.OK.Session,
+			},
+		}, nil
+	}
+
+	accountDeletionPOST := func(reauthentication map[string]interface{}, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.AccountDeletionPOSTResponse, error) {
+		session, err := (*options.RecipeImplementation.GetSession)(options.Req, options.Res, nil, userContext)
+		if err != nil {
+			return sessmodels.AccountDeletionPOSTResponse{}, err
+		}
+		if session == nil {
+			return sessmodels.AccountDeletionPOSTResponse{}, defaultErrors.New("session is nil. Should not come here")
+		}
+		if session.IsImpersonatedWithContext(userContext) || session.IsGuestWithContext(userContext) {
+			return sessmodels.AccountDeletionPOSTResponse{
+				AccountDeletionNotAllowedError: &struct{}{},
+			}, nil
+		}
+		userID := session.GetUserIDWithContext(userContext)
+
+		verified, err := options.Config.AccountDeletion.VerifyReauthentication(userID, reauthentication, userContext)
+		if err != nil {
+			return sessmodels.AccountDeletionPOSTResponse{}, err
+		}
+		if !verified {
+			return sessmodels.AccountDeletionPOSTResponse{
+				ReauthenticationFailedError: &struct{}{},
+			}, nil
+		}
+
+		// the deletion is scheduled first so that the user stays signed in if it fails.
+		// This revokes all their sessions, and revoking the current one again clears its
+		// cookies from the response
+		response, err := (*options.RecipeImplementation.ScheduleAccountDeletion)(userID, userContext)
+		if err != nil {
+			return sessmodels.AccountDeletionPOSTResponse{}, err
+		}
+		err = session.RevokeSessionWithContext(userContext)
+		if err != nil {
+			return sessmodels.AccountDeletionPOSTResponse{}, err
+		}
+		return sessmodels.AccountDeletionPOSTResponse{
+			OK: &struct{ DeletionTime uint64 }{
+				DeletionTime: response.OK.DeletionTime,
+			},
+		}, nil
+	}
+
 	return sessmodels.APIInterface{
-		RefreshPOST:   &refreshPOST,
-		VerifySession: &verifySession,
-		SignOutPOST:   &signOutPOST,
+		RefreshPOST:                 &refreshPOST,
+		RefreshGET:                  &refreshGET,
+		VerifySession:               &verifySession,
+		SignOutPOST:                 &signOutPOST,
+		SessionTransferPOST:         &sessionTransferPOST,
+		SessionTransferExchangePOST: &sessionTransferExchangePOST,
+		AccountDeletionPOST:         &accountDeletionPOST,
 	}
 }
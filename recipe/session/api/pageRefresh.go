@@ -0,0 +1,46 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+// NormaliseRedirectToPath returns redirectToPath if it is a path on this site, and "/" otherwise, so that the
+// refresh API can not be used to redirect users to other sites.
+func NormaliseRedirectToPath(redirectToPath string) string {
+	// browsers treat "\" like "/", so "/\evil.com" would be a protocol relative URL
+	if !strings.HasPrefix(redirectToPath, "/") || strings.HasPrefix(redirectToPath, "//") || strings.ContainsAny(redirectToPath, "\\\r\n\t") {
+		return "/"
+	}
+	urlObj, err := url.Parse(redirectToPath)
+	if err != nil || urlObj.Scheme != "" || urlObj.Host != "" || urlObj.User != nil {
+		return "/"
+	}
+	return redirectToPath
+}
+
+// GetLoginPageURL returns the login page URL with the page to go back to after logging in
+func GetLoginPageURL(config sessmodels.TypeNormalisedInput, redirectToPath string) string {
+	separator := "?"
+	if strings.Contains(config.PageRefresh.LoginURL, "?") {
+		separator = "&"
+	}
+	return config.PageRefresh.LoginURL + separator + "redirectToPath=" + url.QueryEscape(NormaliseRedirectToPath(redirectToPath))
+}
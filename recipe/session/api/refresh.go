@@ -31,3 +31,12 @@ func HandleRefreshAPI(apiImplementation sessmodels.APIInterface, options sessmod
 	}
 	return supertokens.Send200Response(options.Res, nil)
 }
+
+func HandleRefreshPageAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.RefreshGET == nil || (*apiImplementation.RefreshGET) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+	redirectToPath := options.Req.URL.Query().Get("redirectToPath")
+	return (*apiImplementation.RefreshGET)(redirectToPath, options, &map[string]interface{}{})
+}
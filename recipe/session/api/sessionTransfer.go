@@ -0,0 +1,99 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func SessionTransferAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.SessionTransferPOST == nil || (*apiImplementation.SessionTransferPOST) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON input"}
+	}
+	targetOrigin, ok := readBody["targetOrigin"].(string)
+	if !ok || targetOrigin == "" {
+		return supertokens.BadInputError{Msg: "Please provide the targetOrigin in request body"}
+	}
+
+	response, err := (*apiImplementation.SessionTransferPOST)(targetOrigin, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":        "OK",
+			"transferToken": response.OK.TransferToken,
+		})
+	} else if response.TargetOriginNotAllowedError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "TARGET_ORIGIN_NOT_ALLOWED_ERROR",
+		})
+	} else {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "SESSION_NOT_TRANSFERABLE_ERROR",
+		})
+	}
+}
+
+func SessionTransferExchangeAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.SessionTransferExchangePOST == nil || (*apiImplementation.SessionTransferExchangePOST) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(options.Req.Body)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return supertokens.BadInputError{Msg: "Please provide a JSON input"}
+	}
+	transferToken, ok := readBody["transferToken"].(string)
+	if !ok || transferToken == "" {
+		return supertokens.BadInputError{Msg: "Please provide the transferToken in request body"}
+	}
+
+	response, err := (*apiImplementation.SessionTransferExchangePOST)(transferToken, options, &map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if response.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"userId": response.OK.Session.GetUserID(),
+		})
+	}
+	return supertokens.Send200Response(options.Res, map[string]interface{}{
+		"status": "INVALID_TRANSFER_TOKEN_ERROR",
+	})
+}
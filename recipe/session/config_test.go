@@ -652,7 +652,7 @@ func TestSuperTokensWithAntiCSRFRandom(t *testing.T) {
 	defer AfterEach()
 	err := supertokens.Init(configValue)
 	if err != nil {
-		assert.Equal(t, err.Error(), "antiCsrf config must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN'")
+		assert.Equal(t, err.Error(), "antiCsrf config must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN' or 'VIA_ORIGIN' or 'VIA_DOUBLE_SUBMIT_COOKIE'")
 	} else {
 		t.Fail()
 	}
@@ -15,6 +15,8 @@
 
 package session
 
+import "math"
+
 const (
 	refreshAPIPath = "/session/refresh"
 	signoutAPIPath = "/signout"
@@ -22,8 +24,78 @@ const (
 	antiCSRF_VIA_TOKEN         = "VIA_TOKEN"
 	antiCSRF_VIA_CUSTOM_HEADER = "VIA_CUSTOM_HEADER"
 	antiCSRF_NONE              = "NONE"
+	antiCSRF_VIA_ORIGIN        = "VIA_ORIGIN"
+
+	antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE = "VIA_DOUBLE_SUBMIT_COOKIE"
 
 	cookieSameSite_NONE   = "none"
 	cookieSameSite_LAX    = "lax"
 	cookieSameSite_STRICT = "strict"
+
+	cookieNamePrefix_HOST   = "__Host-"
+	cookieNamePrefix_SECURE = "__Secure-"
+
+	sessionLimit_REVOKE_OLDEST = "REVOKE_OLDEST"
+	sessionLimit_REJECT        = "REJECT"
+
+	signInTimePayloadKey       = "_signInTime"
+	lastActivityTimePayloadKey = "_lastActivityTime"
+
+	impersonatedByPayloadKey      = "_impersonatedBy"
+	impersonationExpiryPayloadKey = "_impersonationExpiry"
+
+	encryptedSessionDataKey = "_encrypted"
+	encryptedValuePrefix    = "st-enc:v1:"
+
+	isGuestPayloadKey = "_isGuest"
+	guestUserIDPrefix = "guest-"
+
+	rememberMePayloadKey       = "_rememberMe"
+	rememberMeExpiryPayloadKey = "_rememberMeExpiry"
+	rememberMeUserContextKey   = "_supertokensRememberMe"
+
+	// session cookies set with this expiry have no Expires attribute
+	browserSessionCookieExpiry uint64 = math.MaxUint64
+
+	sessionTransferAPIPath         = "/session/transfer"
+	sessionTransferExchangeAPIPath = "/session/transfer/exchange"
+
+	defaultSessionTransferTokenValidityMS uint64 = 30000
+	maxSessionTransferTokenValidityMS     uint64 = 300000
+	minSessionTransferSigningKeyLength           = 32
+
+	accountDeletionAPIPath = "/user/delete"
+
+	defaultAccountDeletionGracePeriodMS uint64 = 30 * 24 * 60 * 60 * 1000
+
+	defaultImpersonationMaxLifetimeMS uint64 = 3600000
+
+	impersonationEnd_REVOKED = "REVOKED"
+	impersonationEnd_EXPIRED = "EXPIRED"
+
+	sessionEndReason_ACCESS_TOKEN_EXPIRED = "ACCESS_TOKEN_EXPIRED"
+	sessionEndReason_SESSION_EXPIRED      = "SESSION_EXPIRED"
+	sessionEndReason_SESSION_REVOKED      = "SESSION_REVOKED"
+
+	defaultSessionMonitorRecheckIntervalMS uint64 = 60000
+
+	sessionEndedSSEEventName = "sessionEnded"
+
+	maxRefreshTokenReuseGraceWindowMS uint64 = 60000
+
+	defaultMaxAccessTokenPayloadSizeBytes uint64 = 0
+
+	accessTokenPayloadBuiltUserContextKey = "_accessTokenPayloadBuilt"
+
+	// the last activity time in the access token payload is refreshed at most once in this interval
+	lastActivityUpdateIntervalMS uint64 = 60000
+)
+
+// Close codes used when a WebSocket connection is closed because its session ended. They are in the range
+// reserved for applications by RFC 6455.
+const (
+	// the frontend should refresh the session and reconnect
+	WebSocketCloseCodeTryRefreshToken = 4001
+	// the frontend should ask the user to sign in again
+	WebSocketCloseCodeUnauthorised = 4003
 )
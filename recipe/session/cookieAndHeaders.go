@@ -31,6 +31,7 @@ import (
 const (
 	accessTokenCookieKey  = "sAccessToken"
 	refreshTokenCookieKey = "sRefreshToken"
+	antiCsrfCookieKey     = "sAntiCsrf"
 
 	// there are two of them because one is used by the server to check if the user is logged in and the other is checked by the frontend to see if the user is logged in.
 	idRefreshTokenCookieKey = "sIdRefreshToken"
@@ -55,36 +56,82 @@ func clearSessionFromCookie(config sessmodels.TypeNormalisedInput, res http.Resp
 	setCookie(config, res, accessTokenCookieKey, "", 0, "accessTokenPath")
 	setCookie(config, res, refreshTokenCookieKey, "", 0, "refreshTokenPath")
 	setCookie(config, res, idRefreshTokenCookieKey, "", 0, "accessTokenPath")
+	if config.AntiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE {
+		setCookie(config, res, antiCsrfCookieKey, "", 0, "accessTokenPath")
+	}
+	clearLegacySessionCookies(config, res)
 	setHeader(res, idRefreshTokenHeaderKey, "remove", false)
 	setHeader(res, "Access-Control-Expose-Headers", idRefreshTokenHeaderKey, true)
 }
 
-func attachAccessTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64) {
-	setCookie(config, res, accessTokenCookieKey, token, expiry, "accessTokenPath")
+// clearLegacySessionCookies removes the cookies set with a previous cookie name prefix. Browsers only remove a
+// cookie when the domain and path match, so they are cleared using the legacy cookie domains and paths.
+func clearLegacySessionCookies(config sessmodels.TypeNormalisedInput, res http.ResponseWriter) {
+	for _, prefix := range config.LegacyCookieNamePrefixes {
+		setCookieWithAttributes(config, res, prefix, accessTokenCookieKey, "", 0, config.LegacyCookieDomains, config.LegacyAccessTokenCookiePath)
+		setCookieWithAttributes(config, res, prefix, refreshTokenCookieKey, "", 0, config.LegacyCookieDomains, config.LegacyRefreshTokenCookiePath)
+		setCookieWithAttributes(config, res, prefix, idRefreshTokenCookieKey, "", 0, config.LegacyCookieDomains, config.LegacyAccessTokenCookiePath)
+	}
+}
+
+func attachAccessTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64, persistent bool) {
+	setCookie(config, res, accessTokenCookieKey, token, getCookieExpiry(expiry, persistent), "accessTokenPath")
+}
+
+func attachRefreshTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64, persistent bool) {
+	setCookie(config, res, refreshTokenCookieKey, token, getCookieExpiry(expiry, persistent), "refreshTokenPath")
 }
 
-func attachRefreshTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64) {
-	setCookie(config, res, refreshTokenCookieKey, token, expiry, "refreshTokenPath")
+func attachAntiCsrfTokenToCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, token string, expiry uint64, persistent bool) {
+	setCookie(config, res, antiCsrfCookieKey, token, getCookieExpiry(expiry, persistent), "accessTokenPath")
 }
 
-func getAccessTokenFromCookie(req *http.Request) *string {
-	return getCookieValue(req, accessTokenCookieKey)
+// getCookieExpiry returns the expiry to set on a session cookie. Cookies of sessions that are not persistent are set
+// without an expiry, so that the browser removes them when it is closed.
+func getCookieExpiry(expiry uint64, persistent bool) uint64 {
+	if !persistent {
+		return browserSessionCookieExpiry
+	}
+	return expiry
+}
+
+func getAccessTokenFromCookie(config sessmodels.TypeNormalisedInput, req *http.Request) *string {
+	return getSessionCookieValue(config, req, accessTokenCookieKey)
 }
 
-func getRefreshTokenFromCookie(req *http.Request) *string {
-	return getCookieValue(req, refreshTokenCookieKey)
+func getRefreshTokenFromCookie(config sessmodels.TypeNormalisedInput, req *http.Request) *string {
+	return getSessionCookieValue(config, req, refreshTokenCookieKey)
 }
 
 func getAntiCsrfTokenFromHeaders(req *http.Request) *string {
 	return getHeader(req, antiCsrfHeaderKey)
 }
 
+func getAntiCsrfTokenFromCookie(config sessmodels.TypeNormalisedInput, req *http.Request) *string {
+	return getSessionCookieValue(config, req, antiCsrfCookieKey)
+}
+
 func getRidFromHeader(req *http.Request) *string {
 	return getHeader(req, ridHeaderKey)
 }
 
-func getIDRefreshTokenFromCookie(req *http.Request) *string {
-	return getCookieValue(req, idRefreshTokenCookieKey)
+func getIDRefreshTokenFromCookie(config sessmodels.TypeNormalisedInput, req *http.Request) *string {
+	return getSessionCookieValue(config, req, idRefreshTokenCookieKey)
+}
+
+// getSessionCookieValue reads the cookie with the configured name prefix, falling back to the legacy name prefixes
+func getSessionCookieValue(config sessmodels.TypeNormalisedInput, req *http.Request, key string) *string {
+	value := getCookieValue(req, config.CookieNamePrefix+key)
+	if value != nil {
+		return value
+	}
+	for _, prefix := range config.LegacyCookieNamePrefixes {
+		value = getCookieValue(req, prefix+key)
+		if value != nil {
+			return value
+		}
+	}
+	return nil
 }
 
 func setAntiCsrfTokenInHeaders(res http.ResponseWriter, antiCsrfToken string) {
@@ -92,11 +139,11 @@ func setAntiCsrfTokenInHeaders(res http.ResponseWriter, antiCsrfToken string) {
 	setHeader(res, "Access-Control-Expose-Headers", antiCsrfHeaderKey, true)
 }
 
-func setIDRefreshTokenInHeaderAndCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, idRefreshToken string, expiry uint64) {
+func setIDRefreshTokenInHeaderAndCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, idRefreshToken string, expiry uint64, persistent bool) {
 	setHeader(res, idRefreshTokenHeaderKey, idRefreshToken+";"+fmt.Sprint(expiry), false)
 	setHeader(res, "Access-Control-Expose-Headers", idRefreshTokenHeaderKey, true)
 
-	setCookie(config, res, idRefreshTokenCookieKey, idRefreshToken, expiry, "accessTokenPath")
+	setCookie(config, res, idRefreshTokenCookieKey, idRefreshToken, getCookieExpiry(expiry, persistent), "accessTokenPath")
 }
 
 func setFrontTokenInHeaders(res http.ResponseWriter, userId string, atExpiry uint64, jwtPayload interface{}) {
@@ -129,18 +176,30 @@ func setHeader(res http.ResponseWriter, key, value string, allowDuplicateKey boo
 }
 
 func setCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, name string, value string, expires uint64, pathType string) {
-	var domain string
-	if config.CookieDomain != nil {
-		domain = *config.CookieDomain
-	}
-	secure := config.CookieSecure
-	sameSite := config.CookieSameSite
+	setCookieWithNamePrefix(config, res, config.CookieNamePrefix, name, value, expires, pathType)
+}
 
+func setCookieWithNamePrefix(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, namePrefix string, name string, value string, expires uint64, pathType string) {
 	path := ""
 	if pathType == "refreshTokenPath" {
-		path = config.RefreshTokenPath.GetAsStringDangerous()
+		path = config.RefreshTokenCookiePath
 	} else if pathType == "accessTokenPath" {
+		path = config.AccessTokenCookiePath
+	}
+	setCookieWithAttributes(config, res, namePrefix, name, value, expires, config.CookieDomains, path)
+}
+
+func setCookieWithAttributes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, namePrefix string, name string, value string, expires uint64, domains []string, path string) {
+	secure := config.CookieSecure
+	sameSite := config.CookieSameSite
+
+	// browsers reject cookies with these prefixes unless they have the attributes below
+	if strings.HasPrefix(namePrefix, cookieNamePrefix_HOST) {
+		domains = []string{}
 		path = "/"
+		secure = true
+	} else if strings.HasPrefix(namePrefix, cookieNamePrefix_SECURE) {
+		secure = true
 	}
 
 	var sameSiteField = http.SameSiteNoneMode
@@ -152,29 +211,27 @@ func setCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, n
 
 	httpOnly := true
 
-	if domain != "" {
+	if len(domains) == 0 {
+		domains = []string{""}
+	}
+	expiresTime := time.Unix(int64(expires/1000), 0)
+	if expires == browserSessionCookieExpiry {
+		// a zero time leaves out the Expires attribute
+		expiresTime = time.Time{}
+	}
+
+	for _, domain := range domains {
 		cookie := &http.Cookie{
-			Name:     name,
+			Name:     namePrefix + name,
 			Value:    url.QueryEscape(value),
 			Domain:   domain,
 			Secure:   secure,
 			HttpOnly: httpOnly,
-			Expires:  time.Unix(int64(expires/1000), 0),
+			Expires:  expiresTime,
 			Path:     path,
 			SameSite: sameSiteField,
 		}
-		setCookieValue(res, cookie)
-	} else {
-		cookie := &http.Cookie{
-			Name:     name,
-			Value:    url.QueryEscape(value),
-			Secure:   secure,
-			HttpOnly: httpOnly,
-			Expires:  time.Unix(int64(expires/1000), 0),
-			Path:     path,
-			SameSite: sameSiteField,
-		}
-		setCookieValue(res, cookie)
+		setCookieValue(res, cookie, config.CookiePartitioned)
 	}
 }
 
@@ -205,25 +262,56 @@ func getCookieValue(request *http.Request, key string) *string {
 }
 
 // setCookieValue replaces cookie.go SetCookie, it replaces the cookie values instead of appending them
-func setCookieValue(w http.ResponseWriter, cookie *http.Cookie) {
+func setCookieValue(w http.ResponseWriter, cookie *http.Cookie, partitioned bool) {
+	cookieString := cookie.String()
+	if partitioned {
+		// http.Cookie does not support the Partitioned attribute in the go versions we support
+		cookieString += "; Partitioned"
+	}
 	cookieHeader := w.Header().Values("Set-Cookie")
 	if len(cookieHeader) == 0 {
-		w.Header().Set("Set-Cookie", cookie.String())
+		w.Header().Set("Set-Cookie", cookieString)
 		return
 	}
 	existingCookies := make(map[string]string, len(cookieHeader))
-	// map existing cookies by cookie name
+	existingCookieKeys := []string{}
+	// map existing cookies by cookie name, domain and path
 	for _, ch := range cookieHeader {
-		existingCookies[getCookieName(ch)] = ch
+		key := getCookieKey(ch)
+		if _, ok := existingCookies[key]; !ok {
+			existingCookieKeys = append(existingCookieKeys, key)
+		}
+		existingCookies[key] = ch
 	}
 	// replace if already existing
-	existingCookies[getCookieName(cookie.String())] = cookie.String()
+	key := getCookieKey(cookieString)
+	if _, ok := existingCookies[key]; !ok {
+		existingCookieKeys = append(existingCookieKeys, key)
+	}
+	existingCookies[key] = cookieString
 	// clear previous cookies from the headers
 	w.Header().Del("Set-Cookie")
 	// and add them back
-	for _, ck := range existingCookies {
-		w.Header().Add("Set-Cookie", ck)
+	for _, k := range existingCookieKeys {
+		w.Header().Add("Set-Cookie", existingCookies[k])
+	}
+}
+
+// getCookieKey identifies a cookie the way browsers do, by its name, domain and path
+func getCookieKey(cookie string) string {
+	name := getCookieName(cookie)
+	domain := ""
+	path := ""
+	for _, part := range strings.Split(cookie, ";")[1:] {
+		part = textproto.TrimString(part)
+		lowerCasePart := strings.ToLower(part)
+		if strings.HasPrefix(lowerCasePart, "domain=") {
+			domain = strings.ToLower(part[len("domain="):])
+		} else if strings.HasPrefix(lowerCasePart, "path=") {
+			path = part[len("path="):]
+		}
 	}
+	return name + ";" + domain + ";" + path
 }
 
 func getCookieName(cookie string) string {
@@ -0,0 +1,224 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func getNormalisedConfigForCookieTests(t *testing.T, apiDomain string, config *sessmodels.TypeInput) (sessmodels.TypeNormalisedInput, error) {
+	appInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     apiDomain,
+		WebsiteDomain: apiDomain,
+	})
+	assert.NoError(t, err)
+	return validateAndNormaliseUserInput(appInfo, config)
+}
+
+func TestDefaultSessionCookies(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "http://api.supertokens.io", nil)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	attachAccessTokenToCookie(config, res, "accessToken", 1000, true)
+	attachRefreshTokenToCookie(config, res, "refreshToken", 1000, true)
+	cookies := res.Header().Values("Set-Cookie")
+	assert.Equal(t, 2, len(cookies))
+	assert.True(t, strings.HasPrefix(cookies[0], "sAccessToken=accessToken; Path=/;"))
+	assert.True(t, strings.HasPrefix(cookies[1], "sRefreshToken=refreshToken; Path=/auth/session/refresh;"))
+	assert.NotContains(t, cookies[0], "Domain")
+	assert.NotContains(t, cookies[0], "Partitioned")
+}
+
+func TestHostPrefixedSessionCookies(t *testing.T) {
+	prefix := "__Host-"
+	rootPath := "/"
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix:         &prefix,
+		RefreshTokenCookiePath:   &rootPath,
+		LegacyCookieNamePrefixes: []string{""},
+	})
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	attachRefreshTokenToCookie(config, res, "refreshToken", 1000, true)
+	clearLegacySessionCookies(config, res)
+	cookies := res.Header().Values("Set-Cookie")
+	assert.True(t, strings.HasPrefix(cookies[0], "__Host-sRefreshToken=refreshToken; Path=/;"))
+	assert.Contains(t, cookies[0], "Secure")
+	assert.NotContains(t, cookies[0], "Domain")
+	assert.True(t, strings.HasPrefix(cookies[1], "sAccessToken=;"))
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.supertokens.io", nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: "sRefreshToken", Value: "legacy"})
+	assert.Equal(t, "legacy", *getRefreshTokenFromCookie(config, req))
+	req.AddCookie(&http.Cookie{Name: "__Host-sRefreshToken", Value: "current"})
+	assert.Equal(t, "current", *getRefreshTokenFromCookie(config, req))
+}
+
+func TestLegacyCookiesAreClearedWithLegacyDomainsAndPaths(t *testing.T) {
+	prefix := "__Host-"
+	rootPath := "/"
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix:         &prefix,
+		RefreshTokenCookiePath:   &rootPath,
+		LegacyCookieNamePrefixes: []string{""},
+		LegacyCookieDomains:      []string{".supertokens.io"},
+	})
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	clearLegacySessionCookies(config, res)
+	cookies := res.Header().Values("Set-Cookie")
+	assert.Equal(t, 6, len(cookies))
+	assert.True(t, strings.HasPrefix(cookies[0], "sAccessToken=; Path=/;"))
+	assert.NotContains(t, cookies[0], "Domain")
+	assert.True(t, strings.HasPrefix(cookies[1], "sAccessToken=; Path=/; Domain=supertokens.io;"))
+	assert.True(t, strings.HasPrefix(cookies[2], "sRefreshToken=; Path=/auth/session/refresh;"))
+	assert.True(t, strings.HasPrefix(cookies[3], "sRefreshToken=; Path=/auth/session/refresh; Domain=supertokens.io;"))
+}
+
+func TestCookiePrefixValidation(t *testing.T) {
+	hostPrefix := "__Host-"
+	securePrefix := "__Secure-"
+	rootPath := "/"
+	cookieDomain := "supertokens.io"
+	partitioned := true
+
+	_, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix: &hostPrefix,
+	})
+	assert.Error(t, err)
+
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix:       &hostPrefix,
+		RefreshTokenCookiePath: &rootPath,
+		CookieDomain:           &cookieDomain,
+	})
+	assert.Error(t, err)
+
+	_, err = getNormalisedConfigForCookieTests(t, "http://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix: &securePrefix,
+	})
+	assert.Error(t, err)
+
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookieNamePrefix: &securePrefix,
+	})
+	assert.NoError(t, err)
+
+	_, err = getNormalisedConfigForCookieTests(t, "http://api.supertokens.io", &sessmodels.TypeInput{
+		CookiePartitioned: &partitioned,
+	})
+	assert.Error(t, err)
+
+	otherPath := "/other"
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		RefreshTokenCookiePath: &otherPath,
+	})
+	assert.Error(t, err)
+
+	_, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		LegacyCookieNamePrefixes: []string{""},
+	})
+	assert.Error(t, err)
+}
+
+func TestPartitionedSessionCookiesForMultipleDomains(t *testing.T) {
+	partitioned := true
+	authPath := "/auth"
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		CookiePartitioned:      &partitioned,
+		CookieDomains:          []string{"supertokens.io", ".supertokens.com"},
+		RefreshTokenCookiePath: &authPath,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"supertokens.io", "supertokens.com"}, config.CookieDomains)
+
+	res := httptest.NewRecorder()
+	attachRefreshTokenToCookie(config, res, "refreshToken", 1000, true)
+	attachRefreshTokenToCookie(config, res, "newRefreshToken", 2000, true)
+	cookies := res.Header().Values("Set-Cookie")
+	assert.Equal(t, 2, len(cookies))
+	assert.True(t, strings.HasPrefix(cookies[0], "sRefreshToken=newRefreshToken; Path=/auth; Domain=supertokens.io;"))
+	assert.True(t, strings.HasPrefix(cookies[1], "sRefreshToken=newRefreshToken; Path=/auth; Domain=supertokens.com;"))
+	assert.True(t, strings.HasSuffix(cookies[0], "; Partitioned"))
+}
+
+func TestCookiePathMatches(t *testing.T) {
+	assert.True(t, cookiePathMatches("/", "/auth/session/refresh"))
+	assert.True(t, cookiePathMatches("/auth", "/auth/session/refresh"))
+	assert.True(t, cookiePathMatches("/auth/session/refresh", "/auth/session/refresh"))
+	assert.False(t, cookiePathMatches("/au", "/auth/session/refresh"))
+	assert.False(t, cookiePathMatches("/other", "/auth/session/refresh"))
+}
+
+func TestCreatingSessionClearsLegacyCookies(t *testing.T) {
+	prefix := "__Host-"
+	rootPath := "/"
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "https://supertokens.io",
+			APIDomain:     "https://api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				CookieNamePrefix:         &prefix,
+				RefreshTokenCookiePath:   &rootPath,
+				LegacyCookieNamePrefixes: []string{""},
+				LegacyCookieDomains:      []string{".supertokens.io"},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	res := httptest.NewRecorder()
+	_, err = CreateNewSession(res, "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+
+	var clearedLegacyRefreshTokens []string
+	for _, cookie := range res.Header().Values("Set-Cookie") {
+		if strings.HasPrefix(cookie, "sRefreshToken=;") {
+			clearedLegacyRefreshTokens = append(clearedLegacyRefreshTokens, cookie)
+		}
+	}
+	assert.Equal(t, 2, len(clearedLegacyRefreshTokens))
+	for _, cookie := range clearedLegacyRefreshTokens {
+		assert.Contains(t, cookie, "Path=/auth/session/refresh")
+		assert.Contains(t, cookie, "Expires=Thu, 01 Jan 1970")
+	}
+	assert.Contains(t, clearedLegacyRefreshTokens[1], "Domain=supertokens.io")
+}
@@ -0,0 +1,267 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessionwithjwt"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func validateAndNormaliseEncryptionConfig(config sessmodels.EncryptionInputConfig) (sessmodels.EncryptionNormalisedConfig, error) {
+	if len(config.Keys) == 0 {
+		return sessmodels.EncryptionNormalisedConfig{}, errors.New("encryption config must have at least one key")
+	}
+	keyIDs := []string{}
+	for _, key := range config.Keys {
+		if key.ID == "" || strings.Contains(key.ID, ":") {
+			return sessmodels.EncryptionNormalisedConfig{}, errors.New("encryption key IDs must not be empty or contain ':'")
+		}
+		if containsString(keyIDs, key.ID) {
+			return sessmodels.EncryptionNormalisedConfig{}, errors.New("encryption key IDs must be unique. Found '" + key.ID + "' more than once")
+		}
+		if len(key.Key) != 16 && len(key.Key) != 24 && len(key.Key) != 32 {
+			return sessmodels.EncryptionNormalisedConfig{}, errors.New("encryption key '" + key.ID + "' must be 16, 24 or 32 bytes long")
+		}
+		keyIDs = append(keyIDs, key.ID)
+	}
+	for _, payloadKey := range config.AccessTokenPayloadKeys {
+		if containsString(reservedAccessTokenPayloadKeys, payloadKey) || payloadKey == sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY {
+			return sessmodels.EncryptionNormalisedConfig{}, errors.New("the access token payload key '" + payloadKey + "' is used by the SDK and cannot be encrypted")
+		}
+	}
+	if !config.EncryptSessionData && len(config.AccessTokenPayloadKeys) == 0 {
+		return sessmodels.EncryptionNormalisedConfig{}, errors.New("encryption config must either set encryptSessionData to true or have accessTokenPayloadKeys")
+	}
+	return sessmodels.EncryptionNormalisedConfig{
+		Enable:                 true,
+		Keys:                   config.Keys,
+		EncryptSessionData:     config.EncryptSessionData,
+		AccessTokenPayloadKeys: config.AccessTokenPayloadKeys,
+	}, nil
+}
+
+type encryptedSessionData struct {
+	Version int    `json:"v"`
+	KeyID   string `json:"kid"`
+	DataKey string `json:"key"`
+	Data    string `json:"data"`
+}
+
+func getEncryptionKey(config sessmodels.EncryptionNormalisedConfig, keyID string) ([]byte, error) {
+	for _, key := range config.Keys {
+		if key.ID == keyID {
+			return key.Key, nil
+		}
+	}
+	return nil, errors.New("no encryption key with ID '" + keyID + "' found in the key ring")
+}
+
+// sealWithAESGCM returns the nonce followed by the ciphertext
+func sealWithAESGCM(key []byte, plaintext []byte, additionalData string) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(additionalData)), nil
+}
+
+func openWithAESGCM(key []byte, sealed []byte, additionalData string) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(additionalData))
+}
+
+// encryptSessionData encrypts the session data with a new data key, and the data key with the current key of the key ring
+func encryptSessionData(config sessmodels.TypeNormalisedInput, sessionData map[string]interface{}) (map[string]interface{}, error) {
+	if !config.Encryption.EncryptSessionData || sessionData == nil {
+		return sessionData, nil
+	}
+	plaintext, err := json.Marshal(sessionData)
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, 32)
+	_, err = rand.Read(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := sealWithAESGCM(dataKey, plaintext, encryptedSessionDataKey)
+	if err != nil {
+		return nil, err
+	}
+	currentKey := config.Encryption.Keys[0]
+	wrappedDataKey, err := sealWithAESGCM(currentKey.Key, dataKey, currentKey.ID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		encryptedSessionDataKey: encryptedSessionData{
+			Version: 1,
+			KeyID:   currentKey.ID,
+			DataKey: base64.StdEncoding.EncodeToString(wrappedDataKey),
+			Data:    base64.StdEncoding.EncodeToString(data),
+		},
+	}, nil
+}
+
+// decryptSessionData returns the session data as is if it is not encrypted, for example if it was created before
+// encryption was enabled
+func decryptSessionData(config sessmodels.TypeNormalisedInput, sessionData map[string]interface{}) (map[string]interface{}, error) {
+	envelopeValue, ok := sessionData[encryptedSessionDataKey]
+	if !ok || len(sessionData) != 1 || !config.Encryption.Enable {
+		return sessionData, nil
+	}
+	envelopeJSON, err := json.Marshal(envelopeValue)
+	if err != nil {
+		return nil, err
+	}
+	var envelope encryptedSessionData
+	err = json.Unmarshal(envelopeJSON, &envelope)
+	if err != nil || envelope.Version != 1 {
+		return sessionData, nil
+	}
+	key, err := getEncryptionKey(config.Encryption, envelope.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	wrappedDataKey, err := base64.StdEncoding.DecodeString(envelope.DataKey)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := openWithAESGCM(key, wrappedDataKey, envelope.KeyID)
+	if err != nil {
+		return nil, errors.New("could not decrypt the session data key: " + err.Error())
+	}
+	data, err := base64.StdEncoding.DecodeString(envelope.Data)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openWithAESGCM(dataKey, data, encryptedSessionDataKey)
+	if err != nil {
+		return nil, errors.New("could not decrypt the session data: " + err.Error())
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(plaintext, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// encryptAccessTokenPayload replaces the values of the configured keys with "st-enc:v1:<key ID>:<encrypted value>".
+// The key of the payload is used as additional data so that encrypted values can not be swapped between keys.
+// Values that are already encrypted are only kept as they are if they decrypt with the key ring, so that a value
+// that just looks encrypted is encrypted like any other.
+func encryptAccessTokenPayload(config sessmodels.TypeNormalisedInput, accessTokenPayload map[string]interface{}) (map[string]interface{}, error) {
+	if len(config.Encryption.AccessTokenPayloadKeys) == 0 || accessTokenPayload == nil {
+		return accessTokenPayload, nil
+	}
+	currentKey := config.Encryption.Keys[0]
+	result := map[string]interface{}{}
+	for key, value := range accessTokenPayload {
+		result[key] = value
+		if !containsString(config.Encryption.AccessTokenPayloadKeys, key) {
+			continue
+		}
+		if stringValue, ok := value.(string); ok && strings.HasPrefix(stringValue, encryptedValuePrefix) {
+			_, err := decryptAccessTokenPayloadValue(config, key, stringValue)
+			if err == nil {
+				continue
+			}
+		}
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := sealWithAESGCM(currentKey.Key, plaintext, key)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = encryptedValuePrefix + currentKey.ID + ":" + base64.RawURLEncoding.EncodeToString(sealed)
+	}
+	return result, nil
+}
+
+func decryptAccessTokenPayload(config sessmodels.TypeNormalisedInput, accessTokenPayload map[string]interface{}) (map[string]interface{}, error) {
+	if !config.Encryption.Enable || accessTokenPayload == nil {
+		return accessTokenPayload, nil
+	}
+	result := map[string]interface{}{}
+	for key, value := range accessTokenPayload {
+		result[key] = value
+		stringValue, ok := value.(string)
+		if !ok || !strings.HasPrefix(stringValue, encryptedValuePrefix) {
+			continue
+		}
+		decryptedValue, err := decryptAccessTokenPayloadValue(config, key, stringValue)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = decryptedValue
+	}
+	return result, nil
+}
+
+func decryptAccessTokenPayloadValue(config sessmodels.TypeNormalisedInput, key string, encryptedValue string) (interface{}, error) {
+	parts := strings.SplitN(strings.TrimPrefix(encryptedValue, encryptedValuePrefix), ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("the access token payload key '" + key + "' has an invalid encrypted value")
+	}
+	encryptionKey, err := getEncryptionKey(config.Encryption, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openWithAESGCM(encryptionKey, sealed, key)
+	if err != nil {
+		return nil, errors.New("could not decrypt the access token payload key '" + key + "': " + err.Error())
+	}
+	var decryptedValue interface{}
+	err = json.Unmarshal(plaintext, &decryptedValue)
+	if err != nil {
+		return nil, err
+	}
+	return decryptedValue, nil
+}
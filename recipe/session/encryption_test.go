@@ -0,0 +1,213 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func getEncryptionConfigForTests(t *testing.T, keys []sessmodels.EncryptionKey) sessmodels.TypeNormalisedInput {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		Encryption: &sessmodels.EncryptionInputConfig{
+			Keys:                   keys,
+			EncryptSessionData:     true,
+			AccessTokenPayloadKeys: []string{"email", "roles"},
+		},
+	})
+	assert.NoError(t, err)
+	return config
+}
+
+// toJSONMap simulates the data being sent to and read back from the core
+func toJSONMap(t *testing.T, input map[string]interface{}) map[string]interface{} {
+	jsonBytes, err := json.Marshal(input)
+	assert.NoError(t, err)
+	result := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(jsonBytes, &result))
+	return result
+}
+
+func TestSessionDataEncryption(t *testing.T) {
+	key1 := sessmodels.EncryptionKey{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}
+	config := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key1})
+
+	sessionData := map[string]interface{}{"cart": []interface{}{"item1"}, "secret": "value"}
+	encrypted, err := encryptSessionData(config, sessionData)
+	assert.NoError(t, err)
+	stored := toJSONMap(t, encrypted)
+	assert.Equal(t, 1, len(stored))
+	storedJSON, _ := json.Marshal(stored)
+	assert.NotContains(t, string(storedJSON), "secret")
+	assert.NotContains(t, string(storedJSON), "item1")
+
+	decrypted, err := decryptSessionData(config, stored)
+	assert.NoError(t, err)
+	assert.Equal(t, sessionData, decrypted)
+
+	// data created before encryption was enabled is returned as is
+	plain := map[string]interface{}{"cart": "value"}
+	decrypted, err = decryptSessionData(config, plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	key1 := sessmodels.EncryptionKey{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}
+	key2 := sessmodels.EncryptionKey{ID: "key2", Key: bytes.Repeat([]byte{2}, 16)}
+	oldConfig := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key1})
+	rotatedConfig := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key2, key1})
+	newOnlyConfig := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key2})
+
+	sessionData := map[string]interface{}{"secret": "value"}
+	encryptedWithOldKey, err := encryptSessionData(oldConfig, sessionData)
+	assert.NoError(t, err)
+	decrypted, err := decryptSessionData(rotatedConfig, toJSONMap(t, encryptedWithOldKey))
+	assert.NoError(t, err)
+	assert.Equal(t, sessionData, decrypted)
+
+	encryptedWithNewKey, err := encryptSessionData(rotatedConfig, sessionData)
+	assert.NoError(t, err)
+	assert.Equal(t, "key2", toJSONMap(t, encryptedWithNewKey)[encryptedSessionDataKey].(map[string]interface{})["kid"])
+
+	_, err = decryptSessionData(newOnlyConfig, toJSONMap(t, encryptedWithOldKey))
+	assert.EqualError(t, err, "no encryption key with ID 'key1' found in the key ring")
+}
+
+func TestTamperedSessionDataIsNotDecrypted(t *testing.T) {
+	key1 := sessmodels.EncryptionKey{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}
+	config := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key1})
+
+	encrypted, err := encryptSessionData(config, map[string]interface{}{"secret": "value"})
+	assert.NoError(t, err)
+	stored := toJSONMap(t, encrypted)
+	envelope := stored[encryptedSessionDataKey].(map[string]interface{})
+	data := []byte(envelope["data"].(string))
+	data[len(data)-3] ^= 1
+	envelope["data"] = string(data)
+
+	_, err = decryptSessionData(config, stored)
+	assert.Error(t, err)
+}
+
+func TestAccessTokenPayloadFieldEncryption(t *testing.T) {
+	key1 := sessmodels.EncryptionKey{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}
+	config := getEncryptionConfigForTests(t, []sessmodels.EncryptionKey{key1})
+
+	payload := map[string]interface{}{"email": "johndoe@supertokens.io", "roles": []interface{}{"admin"}, "tenant": "public"}
+	encrypted, err := encryptAccessTokenPayload(config, payload)
+	assert.NoError(t, err)
+	stored := toJSONMap(t, encrypted)
+	assert.Equal(t, "public", stored["tenant"])
+	assert.True(t, strings.HasPrefix(stored["email"].(string), "st-enc:v1:key1:"))
+	assert.True(t, strings.HasPrefix(stored["roles"].(string), "st-enc:v1:key1:"))
+	assert.Equal(t, "johndoe@supertokens.io", payload["email"])
+
+	// encrypting again does not encrypt the encrypted values twice
+	encryptedAgain, err := encryptAccessTokenPayload(config, stored)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, encryptedAgain)
+
+	decrypted, err := decryptAccessTokenPayload(config, stored)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, decrypted)
+
+	// values that only look encrypted are encrypted like any other value
+	fakeValue := "st-enc:v1:key1:" + base64.RawURLEncoding.EncodeToString([]byte("not encrypted"))
+	encrypted, err = encryptAccessTokenPayload(config, map[string]interface{}{"email": fakeValue})
+	assert.NoError(t, err)
+	assert.NotEqual(t, fakeValue, encrypted["email"])
+	decrypted, err = decryptAccessTokenPayload(config, toJSONMap(t, encrypted))
+	assert.NoError(t, err)
+	assert.Equal(t, fakeValue, decrypted["email"])
+
+	// encrypted values can not be moved to another key
+	stored["roles"] = stored["email"]
+	_, err = decryptAccessTokenPayload(config, stored)
+	assert.Error(t, err)
+}
+
+func TestInvalidEncryptionConfig(t *testing.T) {
+	validKey := sessmodels.EncryptionKey{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}
+	tests := []struct {
+		name   string
+		config sessmodels.EncryptionInputConfig
+		err    string
+	}{
+		{"no keys", sessmodels.EncryptionInputConfig{EncryptSessionData: true}, "encryption config must have at least one key"},
+		{"invalid key length", sessmodels.EncryptionInputConfig{Keys: []sessmodels.EncryptionKey{{ID: "key1", Key: []byte("short")}}, EncryptSessionData: true}, "encryption key 'key1' must be 16, 24 or 32 bytes long"},
+		{"duplicate key IDs", sessmodels.EncryptionInputConfig{Keys: []sessmodels.EncryptionKey{validKey, validKey}, EncryptSessionData: true}, "encryption key IDs must be unique. Found 'key1' more than once"},
+		{"invalid key ID", sessmodels.EncryptionInputConfig{Keys: []sessmodels.EncryptionKey{{ID: "key:1", Key: validKey.Key}}, EncryptSessionData: true}, "encryption key IDs must not be empty or contain ':'"},
+		{"reserved payload key", sessmodels.EncryptionInputConfig{Keys: []sessmodels.EncryptionKey{validKey}, AccessTokenPayloadKeys: []string{"_impersonatedBy"}}, "the access token payload key '_impersonatedBy' is used by the SDK and cannot be encrypted"},
+		{"nothing to encrypt", sessmodels.EncryptionInputConfig{Keys: []sessmodels.EncryptionKey{validKey}}, "encryption config must either set encryptSessionData to true or have accessTokenPayloadKeys"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := test.config
+			_, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+				Encryption: &config,
+			})
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestAccessTokenPayloadValuesThatLookEncryptedAreEncryptedInTheCore(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				Encryption: &sessmodels.EncryptionInputConfig{
+					Keys:                   []sessmodels.EncryptionKey{{ID: "key1", Key: bytes.Repeat([]byte{1}, 32)}},
+					AccessTokenPayloadKeys: []string{"email"},
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	fakeValue := "st-enc:v1:key1:" + base64.RawURLEncoding.EncodeToString([]byte("not encrypted"))
+	sess, err := CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{"email": fakeValue}, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, fakeValue, sess.GetAccessTokenPayload()["email"])
+
+	sessionInformation, err := GetSessionInformation(sess.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, fakeValue, sessionInformation.AccessTokenPayload["email"])
+}
@@ -16,9 +16,10 @@
 package errors
 
 const (
-	UnauthorizedErrorStr       = "UNAUTHORISED"
-	TryRefreshTokenErrorStr    = "TRY_REFRESH_TOKEN"
-	TokenTheftDetectedErrorStr = "TOKEN_THEFT_DETECTED"
+	UnauthorizedErrorStr        = "UNAUTHORISED"
+	TryRefreshTokenErrorStr     = "TRY_REFRESH_TOKEN"
+	TokenTheftDetectedErrorStr  = "TOKEN_THEFT_DETECTED"
+	SessionLimitReachedErrorStr = "SESSION_LIMIT_REACHED"
 )
 
 // TryRefreshTokenError used for when the refresh API needs to be called
@@ -54,3 +55,34 @@ type UnauthorizedError struct {
 func (err UnauthorizedError) Error() string {
 	return err.Msg
 }
+
+// SessionLimitReachedError used for when a new session is rejected because the user already has the maximum number of sessions
+type SessionLimitReachedError struct {
+	Msg         string
+	UserID      string
+	MaxSessions int
+}
+
+func (err SessionLimitReachedError) Error() string {
+	return err.Msg
+}
+
+// SchemaValidationError used for when an access token payload or session data is rejected by the schema in the session recipe config
+type SchemaValidationError struct {
+	Msg string
+}
+
+func (err SchemaValidationError) Error() string {
+	return err.Msg
+}
+
+// AccessTokenPayloadTooLargeError used for when an access token payload is too large to be sent in a cookie or header
+type AccessTokenPayloadTooLargeError struct {
+	Msg     string
+	Size    uint64
+	MaxSize uint64
+}
+
+func (err AccessTokenPayloadTooLargeError) Error() string {
+	return err.Msg
+}
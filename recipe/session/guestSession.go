@@ -0,0 +1,133 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/rand"
+	defaultErrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func generateGuestUserID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	// uuid v4
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%s%x-%x-%x-%x-%x", guestUserIDPrefix, b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+func isGuestAccessTokenPayload(accessTokenPayload map[string]interface{}) bool {
+	isGuest, ok := accessTokenPayload[isGuestPayloadKey].(bool)
+	return ok && isGuest
+}
+
+// isGuestAccessToken reads the payload of an access token without verifying it. It is only used to tell the
+// frontend to refresh an expired guest session before signing in, so that its state is not lost.
+func isGuestAccessToken(accessToken string) bool {
+	payload, err := getPayloadWithoutVerifying(accessToken)
+	if err != nil {
+		return false
+	}
+	userData, ok := payload["userData"].(map[string]interface{})
+	return ok && isGuestAccessTokenPayload(userData)
+}
+
+func defaultMergeGuestSession(input sessmodels.GuestSessionMergeInput, userContext supertokens.UserContext) (map[string]interface{}, map[string]interface{}, error) {
+	accessTokenPayload := removeSDKKeysFromAccessTokenPayload(input.GuestAccessTokenPayload)
+	for k, v := range input.AccessTokenPayload {
+		accessTokenPayload[k] = v
+	}
+
+	sessionData := map[string]interface{}{}
+	for k, v := range input.GuestSessionData {
+		sessionData[k] = v
+	}
+	for k, v := range input.SessionData {
+		sessionData[k] = v
+	}
+	return accessTokenPayload, sessionData, nil
+}
+
+// createNewSessionUpgradingGuestSession creates a session for a user who just signed in. If the request
+// has a guest session, its state is merged into the new session and the guest session is revoked. If the access
+// token of the guest session has expired, a TryRefreshTokenError is returned so that the frontend refreshes the
+// guest session and retries, since the refresh token is not sent to the sign in APIs.
+func createNewSessionUpgradingGuestSession(recipeInstance Recipe, req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	if !recipeInstance.Config.GuestSession.Enable {
+		return (*recipeInstance.RecipeImpl.CreateNewSession)(res, userID, accessTokenPayload, sessionData, userContext)
+	}
+
+	sessionRequired := false
+	antiCsrfCheck := false
+	existingSession, err := (*recipeInstance.RecipeImpl.GetSession)(req, res, &sessmodels.VerifySessionOptions{
+		SessionRequired: &sessionRequired,
+		AntiCsrfCheck:   &antiCsrfCheck,
+	}, userContext)
+	if err != nil {
+		if defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
+			accessToken := getAccessTokenFromCookie(recipeInstance.Config, req)
+			if accessToken != nil && isGuestAccessToken(*accessToken) {
+				return sessmodels.SessionContainer{}, err
+			}
+		} else if !defaultErrors.As(err, &errors.UnauthorizedError{}) {
+			return sessmodels.SessionContainer{}, err
+		}
+		existingSession = nil
+	}
+
+	var guestSession *sessmodels.SessionContainer = nil
+	if existingSession != nil && existingSession.IsGuestWithContext(userContext) {
+		guestSession = existingSession
+		guestSessionData, err := guestSession.GetSessionDataWithContext(userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		accessTokenPayload, sessionData, err = recipeInstance.Config.GuestSession.MergeGuestSession(sessmodels.GuestSessionMergeInput{
+			GuestUserID:             guestSession.GetUserIDWithContext(userContext),
+			GuestAccessTokenPayload: guestSession.GetAccessTokenPayloadWithContext(userContext),
+			GuestSessionData:        guestSessionData,
+			UserID:                  userID,
+			AccessTokenPayload:      accessTokenPayload,
+			SessionData:             sessionData,
+		}, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+	}
+
+	newSession, err := (*recipeInstance.RecipeImpl.CreateNewSession)(res, userID, accessTokenPayload, sessionData, userContext)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+
+	if guestSession != nil {
+		// we do not use guestSession.RevokeSession since that would clear the cookies of the new session
+		_, err = (*recipeInstance.RecipeImpl.RevokeSession)(guestSession.GetHandleWithContext(userContext), userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+	}
+	return newSession, nil
+}
@@ -0,0 +1,170 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	b64 "encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessionwithjwt"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestGenerateGuestUserID(t *testing.T) {
+	guestUserID, err := generateGuestUserID()
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^guest-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), guestUserID)
+
+	otherGuestUserID, err := generateGuestUserID()
+	assert.NoError(t, err)
+	assert.NotEqual(t, guestUserID, otherGuestUserID)
+}
+
+func TestDefaultMergeGuestSession(t *testing.T) {
+	accessTokenPayload, sessionData, err := defaultMergeGuestSession(sessmodels.GuestSessionMergeInput{
+		GuestUserID: "guest-1",
+		GuestAccessTokenPayload: map[string]interface{}{
+			isGuestPayloadKey: true,
+			"currency":        "EUR",
+			"theme":           "dark",
+			"jwt":             "someJWT",
+			sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY: "jwt",
+		},
+		GuestSessionData: map[string]interface{}{
+			"cart": []interface{}{"item1"},
+		},
+		UserID: "user",
+		AccessTokenPayload: map[string]interface{}{
+			"theme": "light",
+		},
+		SessionData: map[string]interface{}{},
+	}, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"currency": "EUR",
+		"theme":    "light",
+	}, accessTokenPayload)
+	assert.Equal(t, map[string]interface{}{
+		"cart": []interface{}{"item1"},
+	}, sessionData)
+}
+
+func TestIsGuestAccessToken(t *testing.T) {
+	makeAccessToken := func(userData map[string]interface{}) string {
+		payload, err := json.Marshal(map[string]interface{}{"userId": "guest-1", "userData": userData})
+		assert.NoError(t, err)
+		return "header." + b64.StdEncoding.EncodeToString(payload) + ".signature"
+	}
+	assert.True(t, isGuestAccessToken(makeAccessToken(map[string]interface{}{isGuestPayloadKey: true})))
+	assert.False(t, isGuestAccessToken(makeAccessToken(map[string]interface{}{})))
+	assert.False(t, isGuestAccessToken("not an access token"))
+}
+
+func TestSignInWithExpiredGuestSessionAsksForRefresh(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				GuestSession: &sessmodels.GuestSessionInputConfig{},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.SetKeyValueInConfig("access_token_validity", strconv.Itoa(2))
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/guest", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewGuestSession(rw, map[string]interface{}{}, map[string]interface{}{"cart": "item1"})
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+	mux.HandleFunc("/signin", func(rw http.ResponseWriter, r *http.Request) {
+		session, err := CreateNewSessionUpgradingGuestSession(r, rw, "user", map[string]interface{}{}, map[string]interface{}{})
+		if err != nil {
+			err = supertokens.ErrorHandler(err, r, rw)
+			if err != nil {
+				t.Error(err.Error())
+			}
+			return
+		}
+		sessionData, err := session.GetSessionData()
+		if err != nil {
+			t.Error(err.Error())
+		}
+		err = json.NewEncoder(rw).Encode(sessionData)
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	signIn := func(cookieData map[string]string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, testServer.URL+"/signin", nil)
+		assert.NoError(t, err)
+		req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+		req.Header.Add("anti-csrf", cookieData["antiCsrf"])
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res, err := http.Post(testServer.URL+"/guest", "application/json", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	guestCookieData := unittesting.ExtractInfoFromResponse(res)
+
+	time.Sleep(3 * time.Second)
+
+	res = signIn(guestCookieData)
+	assert.Equal(t, 401, res.StatusCode)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, guestCookieData["sRefreshToken"], guestCookieData["sIdRefreshToken"], guestCookieData["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	refreshedCookieData := unittesting.ExtractInfoFromResponse(res)
+
+	res = signIn(refreshedCookieData)
+	assert.Equal(t, 200, res.StatusCode)
+	var sessionData map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&sessionData)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "item1", sessionData["cart"])
+}
@@ -0,0 +1,85 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func addImpersonationInfoToAccessTokenPayload(accessTokenPayload map[string]interface{}, impersonatorUserID string, expiry uint64) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range accessTokenPayload {
+		result[k] = v
+	}
+	result[impersonatedByPayloadKey] = impersonatorUserID
+	result[impersonationExpiryPayloadKey] = expiry
+	return result
+}
+
+func getImpersonatorUserID(accessTokenPayload map[string]interface{}) (string, bool) {
+	impersonatorUserID, ok := accessTokenPayload[impersonatedByPayloadKey].(string)
+	if !ok || impersonatorUserID == "" {
+		return "", false
+	}
+	return impersonatorUserID, true
+}
+
+func isImpersonationExpired(accessTokenPayload map[string]interface{}, now uint64) bool {
+	if _, ok := getImpersonatorUserID(accessTokenPayload); !ok {
+		return false
+	}
+	expiry, ok := getTimeFromAccessTokenPayload(accessTokenPayload, impersonationExpiryPayloadKey)
+	// an impersonated session without an expiry is treated as expired
+	return !ok || now > expiry
+}
+
+func emitImpersonationEnd(config sessmodels.TypeNormalisedInput, sessionHandle string, userID string, accessTokenPayload map[string]interface{}, reason string, userContext supertokens.UserContext) {
+	if config.Impersonation.OnImpersonationEnd == nil {
+		return
+	}
+	impersonatorUserID, ok := getImpersonatorUserID(accessTokenPayload)
+	if !ok {
+		return
+	}
+	config.Impersonation.OnImpersonationEnd(sessmodels.ImpersonationEvent{
+		SessionHandle:      sessionHandle,
+		UserID:             userID,
+		ImpersonatorUserID: impersonatorUserID,
+	}, reason, userContext)
+}
+
+// enforceImpersonationExpiry revokes an impersonated session and returns an UnauthorizedError once the
+// impersonation has gone past config.Impersonation.MaxLifetimeMS
+func enforceImpersonationExpiry(recipeImpl sessmodels.RecipeInterface, config sessmodels.TypeNormalisedInput, res http.ResponseWriter, session sessmodels.SessionContainer, userContext supertokens.UserContext) error {
+	accessTokenPayload := session.GetAccessTokenPayloadWithContext(userContext)
+	if !isImpersonationExpired(accessTokenPayload, getCurrTimeInMS()) {
+		return nil
+	}
+	sessionHandle := session.GetHandleWithContext(userContext)
+	revoked, err := (*recipeImpl.RevokeSession)(sessionHandle, userContext)
+	if err != nil {
+		return err
+	}
+	clearSessionFromCookie(config, res)
+	if revoked {
+		emitImpersonationEnd(config, sessionHandle, session.GetUserIDWithContext(userContext), accessTokenPayload, impersonationEnd_EXPIRED, userContext)
+	}
+	return errors.UnauthorizedError{Msg: "Impersonation session has expired"}
+}
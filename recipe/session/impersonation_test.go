@@ -0,0 +1,123 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestImpersonationInfoInAccessTokenPayload(t *testing.T) {
+	now := uint64(10000000)
+	accessTokenPayload := addImpersonationInfoToAccessTokenPayload(map[string]interface{}{
+		"role": "customer",
+	}, "admin", now+1000)
+
+	impersonatorUserID, ok := getImpersonatorUserID(accessTokenPayload)
+	assert.True(t, ok)
+	assert.Equal(t, "admin", impersonatorUserID)
+	assert.Equal(t, "customer", accessTokenPayload["role"])
+	assert.False(t, isImpersonationExpired(accessTokenPayload, now))
+	assert.True(t, isImpersonationExpired(accessTokenPayload, now+1001))
+
+	_, ok = getImpersonatorUserID(map[string]interface{}{})
+	assert.False(t, ok)
+	assert.False(t, isImpersonationExpired(map[string]interface{}{}, now))
+	assert.True(t, isImpersonationExpired(map[string]interface{}{impersonatedByPayloadKey: "admin"}, now))
+}
+
+func TestUpdatingAccessTokenPayloadKeepsImpersonationInfo(t *testing.T) {
+	existingAccessTokenPayload := addImpersonationInfoToAccessTokenPayload(map[string]interface{}{}, "admin", 1000)
+	newAccessTokenPayload := copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload, map[string]interface{}{
+		"cart": "abc",
+	})
+	impersonatorUserID, ok := getImpersonatorUserID(newAccessTokenPayload)
+	assert.True(t, ok)
+	assert.Equal(t, "admin", impersonatorUserID)
+	assert.Equal(t, uint64(1000), newAccessTokenPayload[impersonationExpiryPayloadKey])
+}
+
+func TestReservedAccessTokenPayloadKeysCannotBeChanged(t *testing.T) {
+	existingAccessTokenPayload := addImpersonationInfoToAccessTokenPayload(map[string]interface{}{}, "admin", 1000)
+
+	err := validateReservedAccessTokenPayloadKeys(existingAccessTokenPayload, map[string]interface{}{impersonatedByPayloadKey: "attacker"})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+	err = validateReservedAccessTokenPayloadKeys(map[string]interface{}{}, map[string]interface{}{isGuestPayloadKey: true})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+
+	// passing back the payload as it was read is allowed, even if numbers were decoded as float64
+	err = validateReservedAccessTokenPayloadKeys(existingAccessTokenPayload, map[string]interface{}{
+		impersonatedByPayloadKey:      "admin",
+		impersonationExpiryPayloadKey: float64(1000),
+		"cart":                        "abc",
+	})
+	assert.NoError(t, err)
+
+	// reserved keys missing from the new payload are kept
+	newAccessTokenPayload := copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload, map[string]interface{}{})
+	assert.Equal(t, "admin", newAccessTokenPayload[impersonatedByPayloadKey])
+}
+
+func TestImpersonationSessionCannotBeTamperedWith(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				Impersonation: &sessmodels.ImpersonationInputConfig{},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{impersonatedByPayloadKey: "admin"}, map[string]interface{}{})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+
+	session, err := CreateNewImpersonationSession(httptest.NewRecorder(), "user", "admin", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, session.IsImpersonated())
+
+	err = session.UpdateAccessTokenPayload(map[string]interface{}{impersonatedByPayloadKey: "attacker"})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+	err = UpdateAccessTokenPayload(session.GetHandle(), map[string]interface{}{impersonatedByPayloadKey: nil})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+
+	err = UpdateAccessTokenPayload(session.GetHandle(), map[string]interface{}{"cart": "abc"})
+	assert.NoError(t, err)
+	sessionInformation, err := GetSessionInformation(session.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", sessionInformation.AccessTokenPayload["cart"])
+	assert.Equal(t, "admin", sessionInformation.AccessTokenPayload[impersonatedByPayloadKey])
+}
@@ -35,9 +35,93 @@ func CreateNewSessionWithContext(res http.ResponseWriter, userID string, accessT
 	if err != nil {
 		return sessmodels.SessionContainer{}, err
 	}
+	err = validateReservedAccessTokenPayloadKeys(map[string]interface{}{}, accessTokenPayload)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
 	return (*instance.RecipeImpl.CreateNewSession)(res, userID, accessTokenPayload, sessionData, userContext)
 }
 
+func CreateNewImpersonationSessionWithContext(res http.ResponseWriter, targetUserID string, impersonatorUserID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	err = validateReservedAccessTokenPayloadKeys(map[string]interface{}{}, accessTokenPayload)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	return (*instance.RecipeImpl.CreateNewImpersonationSession)(res, targetUserID, impersonatorUserID, accessTokenPayload, sessionData, userContext)
+}
+
+func CreateNewGuestSessionWithContext(res http.ResponseWriter, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	err = validateReservedAccessTokenPayloadKeys(map[string]interface{}{}, accessTokenPayload)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	return (*instance.RecipeImpl.CreateNewGuestSession)(res, accessTokenPayload, sessionData, userContext)
+}
+
+func ScheduleAccountDeletionWithContext(userID string, userContext supertokens.UserContext) (sessmodels.ScheduleAccountDeletionResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.ScheduleAccountDeletionResponse{}, err
+	}
+	return (*instance.RecipeImpl.ScheduleAccountDeletion)(userID, userContext)
+}
+
+func CancelAccountDeletionWithContext(userID string, userContext supertokens.UserContext) (sessmodels.CancelAccountDeletionResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.CancelAccountDeletionResponse{}, err
+	}
+	return (*instance.RecipeImpl.CancelAccountDeletion)(userID, userContext)
+}
+
+// PurgeDueAccountDeletionsWithContext deletes the users whose account deletion grace period is over, and returns
+// their IDs. It should be called periodically, for example from a cron job.
+func PurgeDueAccountDeletionsWithContext(userContext supertokens.UserContext) ([]string, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return purgeDueAccountDeletions(instance.Config, getCurrTimeInMS(), supertokens.DeleteUser, userContext)
+}
+
+func CreateSessionTransferTokenWithContext(userID string, targetOrigin string, userContext supertokens.UserContext) (sessmodels.CreateSessionTransferTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.CreateSessionTransferTokenResponse{}, err
+	}
+	return (*instance.RecipeImpl.CreateSessionTransferToken)(userID, targetOrigin, userContext)
+}
+
+func ExchangeSessionTransferTokenWithContext(res http.ResponseWriter, transferToken string, userContext supertokens.UserContext) (sessmodels.ExchangeSessionTransferTokenResponse, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.ExchangeSessionTransferTokenResponse{}, err
+	}
+	return (*instance.RecipeImpl.ExchangeSessionTransferToken)(res, transferToken, userContext)
+}
+
+// CreateNewSessionUpgradingGuestSessionWithContext is used by the sign in APIs of the auth recipes. It behaves like
+// CreateNewSessionWithContext, except that a guest session on the request is merged into the new session and revoked.
+func CreateNewSessionUpgradingGuestSessionWithContext(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	err = validateReservedAccessTokenPayloadKeys(map[string]interface{}{}, accessTokenPayload)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	return createNewSessionUpgradingGuestSession(*instance, req, res, userID, accessTokenPayload, sessionData, userContext)
+}
+
 func GetSessionWithContext(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (*sessmodels.SessionContainer, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -127,6 +211,38 @@ func GetSessionFromRequestContext(ctx context.Context) *sessmodels.SessionContai
 	return temp
 }
 
+// MonitorSessionWithContext watches a session that was verified when a long lived connection (WebSocket or
+// Server-Sent-Events) was opened. The returned monitor's Ended channel receives an event once the session is revoked
+// or expires, after which the connection should be closed. Monitoring stops when ctx is done.
+func MonitorSessionWithContext(ctx context.Context, session sessmodels.SessionContainer, options *sessmodels.SessionMonitorOptions, userContext supertokens.UserContext) (sessmodels.SessionMonitor, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SessionMonitor{}, err
+	}
+	return startSessionMonitor(ctx, *instance, session, options, userContext)
+}
+
+// MonitorSessionOfRequestWithContext verifies the session of a request that opens a long lived connection, before
+// the connection is upgraded, and starts monitoring it like MonitorSessionWithContext. Since WebSocket upgrade requests
+// cannot carry the anti-csrf header, the request's Origin is checked against AntiCsrfAllowedOrigins instead. Errors
+// should be passed to supertokens.ErrorHandler, which responds with a 401 if the session could not be verified.
+func MonitorSessionOfRequestWithContext(ctx context.Context, req *http.Request, res http.ResponseWriter, options *sessmodels.SessionMonitorOptions, userContext supertokens.UserContext) (sessmodels.SessionMonitor, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SessionMonitor{}, err
+	}
+	session, err := getSessionForMonitoring(*instance, req, res, userContext)
+	if err != nil {
+		return sessmodels.SessionMonitor{}, err
+	}
+	return startSessionMonitor(ctx, *instance, session, options, userContext)
+}
+
+// SendSessionEndedSSEEvent writes a "sessionEnded" Server-Sent-Event with the reason of the event as JSON data
+func SendSessionEndedSSEEvent(res http.ResponseWriter, event sessmodels.SessionEndedEvent) error {
+	return sendSessionEndedSSEEvent(res, event)
+}
+
 func CreateJWTWithContext(payload map[string]interface{}, validitySecondsPointer *uint64, userContext supertokens.UserContext) (jwtmodels.CreateJWTResponse, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -172,6 +288,38 @@ func CreateNewSession(res http.ResponseWriter, userID string, accessTokenPayload
 	return CreateNewSessionWithContext(res, userID, accessTokenPayload, sessionData, &map[string]interface{}{})
 }
 
+func CreateNewImpersonationSession(res http.ResponseWriter, targetUserID string, impersonatorUserID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+	return CreateNewImpersonationSessionWithContext(res, targetUserID, impersonatorUserID, accessTokenPayload, sessionData, &map[string]interface{}{})
+}
+
+func CreateNewGuestSession(res http.ResponseWriter, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+	return CreateNewGuestSessionWithContext(res, accessTokenPayload, sessionData, &map[string]interface{}{})
+}
+
+func ScheduleAccountDeletion(userID string) (sessmodels.ScheduleAccountDeletionResponse, error) {
+	return ScheduleAccountDeletionWithContext(userID, &map[string]interface{}{})
+}
+
+func CancelAccountDeletion(userID string) (sessmodels.CancelAccountDeletionResponse, error) {
+	return CancelAccountDeletionWithContext(userID, &map[string]interface{}{})
+}
+
+func PurgeDueAccountDeletions() ([]string, error) {
+	return PurgeDueAccountDeletionsWithContext(&map[string]interface{}{})
+}
+
+func CreateSessionTransferToken(userID string, targetOrigin string) (sessmodels.CreateSessionTransferTokenResponse, error) {
+	return CreateSessionTransferTokenWithContext(userID, targetOrigin, &map[string]interface{}{})
+}
+
+func ExchangeSessionTransferToken(res http.ResponseWriter, transferToken string) (sessmodels.ExchangeSessionTransferTokenResponse, error) {
+	return ExchangeSessionTransferTokenWithContext(res, transferToken, &map[string]interface{}{})
+}
+
+func CreateNewSessionUpgradingGuestSession(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}) (sessmodels.SessionContainer, error) {
+	return CreateNewSessionUpgradingGuestSessionWithContext(req, res, userID, accessTokenPayload, sessionData, &map[string]interface{}{})
+}
+
 func GetSession(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*sessmodels.SessionContainer, error) {
 	return GetSessionWithContext(req, res, options, &map[string]interface{}{})
 }
@@ -208,6 +356,14 @@ func UpdateAccessTokenPayload(sessionHandle string, newAccessTokenPayload map[st
 	return UpdateAccessTokenPayloadWithContext(sessionHandle, newAccessTokenPayload, &map[string]interface{}{})
 }
 
+func MonitorSession(ctx context.Context, session sessmodels.SessionContainer, options *sessmodels.SessionMonitorOptions) (sessmodels.SessionMonitor, error) {
+	return MonitorSessionWithContext(ctx, session, options, &map[string]interface{}{})
+}
+
+func MonitorSessionOfRequest(ctx context.Context, req *http.Request, res http.ResponseWriter, options *sessmodels.SessionMonitorOptions) (sessmodels.SessionMonitor, error) {
+	return MonitorSessionOfRequestWithContext(ctx, req, res, options, &map[string]interface{}{})
+}
+
 func CreateJWT(payload map[string]interface{}, validitySecondsPointer *uint64) (jwtmodels.CreateJWTResponse, error) {
 	return CreateJWTWithContext(payload, validitySecondsPointer, &map[string]interface{}{})
 }
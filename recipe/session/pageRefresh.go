@@ -0,0 +1,63 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+// isPageRequest returns true for top level page loads by the browser, which cannot handle a try refresh token
+// response since they do not go through the frontend SDK's interceptor
+func isPageRequest(req *http.Request) bool {
+	if req == nil || (req.Method != http.MethodGet && req.Method != http.MethodHead) || frontendHasInterceptor(req) {
+		return false
+	}
+	if req.Header.Get("Sec-Fetch-Mode") != "" {
+		return req.Header.Get("Sec-Fetch-Mode") == "navigate"
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+func getRefreshPageURL(config sessmodels.TypeNormalisedInput, req *http.Request) string {
+	redirectToPath := api.NormaliseRedirectToPath(req.URL.RequestURI())
+	return config.RefreshTokenPath.GetAsStringDangerous() + "?redirectToPath=" + url.QueryEscape(redirectToPath)
+}
+
+func redirectPageRequest(req *http.Request, res http.ResponseWriter, location string) {
+	res.Header().Set("Cache-Control", "no-store")
+	http.Redirect(res, req, location, http.StatusFound)
+}
+
+// isPageRefreshRequest is true when the session is refreshed by the refresh API on a page load. The anti-csrf
+// checks are skipped for it since a browser navigation can not send custom headers. This is only done if the
+// navigation cannot come from another site: either the cookies are SameSite=Strict, which browsers do not send on
+// navigations from other sites (unlike Lax cookies), or the browser says that it comes from this origin or from the
+// user (for example a bookmark) in the Sec-Fetch-Site header.
+func isPageRefreshRequest(config sessmodels.TypeNormalisedInput, req *http.Request) bool {
+	if !config.PageRefresh.Enable || req.Method != http.MethodGet {
+		return false
+	}
+	if config.CookieSameSite == cookieSameSite_STRICT {
+		return true
+	}
+	secFetchSite := req.Header.Get("Sec-Fetch-Site")
+	return secFetchSite == "same-origin" || secFetchSite == "none"
+}
@@ -0,0 +1,243 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestNormaliseRedirectToPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/dashboard", "/dashboard"},
+		{"/dashboard?tab=settings#top", "/dashboard?tab=settings#top"},
+		{"", "/"},
+		{"dashboard", "/"},
+		{"https://evil.com", "/"},
+		{"//evil.com", "/"},
+		{"/\\evil.com", "/"},
+		{"/dashboard\\..\\", "/"},
+		{"javascript:alert(1)", "/"},
+		{"/\r\nLocation: https://evil.com", "/"},
+		{"\t//evil.com", "/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, api.NormaliseRedirectToPath(test.input))
+		})
+	}
+}
+
+func TestIsPageRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		expected bool
+	}{
+		{"navigation", http.MethodGet, map[string]string{"Sec-Fetch-Mode": "navigate", "Accept": "text/html"}, true},
+		{"html without fetch metadata", http.MethodGet, map[string]string{"Accept": "text/html,application/xhtml+xml"}, true},
+		{"fetch from the page", http.MethodGet, map[string]string{"Sec-Fetch-Mode": "cors", "Accept": "*/*"}, false},
+		{"request with the interceptor", http.MethodGet, map[string]string{"rid": "session", "Accept": "text/html"}, false},
+		{"json request", http.MethodGet, map[string]string{"Accept": "application/json"}, false},
+		{"form post", http.MethodPost, map[string]string{"Sec-Fetch-Mode": "navigate", "Accept": "text/html"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/dashboard", nil)
+			for key, value := range test.headers {
+				req.Header.Set(key, value)
+			}
+			assert.Equal(t, test.expected, isPageRequest(req))
+		})
+	}
+}
+
+func getPageRefreshConfigForTests(t *testing.T) sessmodels.TypeNormalisedInput {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		PageRefresh: &sessmodels.PageRefreshInputConfig{},
+	})
+	assert.NoError(t, err)
+	return config
+}
+
+func TestIsPageRefreshRequest(t *testing.T) {
+	config := getPageRefreshConfigForTests(t)
+	assert.Equal(t, cookieSameSite_LAX, config.CookieSameSite)
+
+	for secFetchSite, expected := range map[string]bool{"same-origin": true, "none": true, "same-site": false, "cross-site": false, "": false} {
+		req := httptest.NewRequest(http.MethodGet, "/auth/session/refresh", nil)
+		if secFetchSite != "" {
+			req.Header.Set("Sec-Fetch-Site", secFetchSite)
+		}
+		assert.Equal(t, expected, isPageRefreshRequest(config, req), secFetchSite)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil)
+	req.Header.Set("Sec-Fetch-Site", "same-origin")
+	assert.False(t, isPageRefreshRequest(config, req))
+
+	strict := cookieSameSite_STRICT
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		PageRefresh:    &sessmodels.PageRefreshInputConfig{},
+		CookieSameSite: &strict,
+	})
+	assert.NoError(t, err)
+	req = httptest.NewRequest(http.MethodGet, "/auth/session/refresh", nil)
+	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	assert.True(t, isPageRefreshRequest(config, req))
+}
+
+func TestPageRequestsAreRedirectedToRefresh(t *testing.T) {
+	config := getPageRefreshConfigForTests(t)
+	assert.Equal(t, "https://supertokens.io/auth", config.PageRefresh.LoginURL)
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard?tab=settings", nil)
+	req.Header.Set("Accept", "text/html")
+	res := httptest.NewRecorder()
+	err := sendTryRefreshTokenResponse(Recipe{Config: config}, "try refresh token", req, res)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, res.Code)
+	assert.Equal(t, "/auth/session/refresh?redirectToPath=%2Fdashboard%3Ftab%3Dsettings", res.Header().Get("Location"))
+
+	res = httptest.NewRecorder()
+	err = sendUnauthorisedResponse(Recipe{Config: config}, "unauthorised", req, res)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, res.Code)
+	assert.Equal(t, "https://supertokens.io/auth?redirectToPath=%2Fdashboard%3Ftab%3Dsettings", res.Header().Get("Location"))
+
+	apiReq := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	apiReq.Header.Set("rid", "session")
+	res = httptest.NewRecorder()
+	err = sendTryRefreshTokenResponse(Recipe{Config: config}, "try refresh token", apiReq, res)
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.Code)
+}
+
+func TestRefreshGETRedirects(t *testing.T) {
+	config := getPageRefreshConfigForTests(t)
+	tests := []struct {
+		name           string
+		redirectToPath string
+		refreshErr     error
+		location       string
+	}{
+		{"refreshed", "/dashboard", nil, "/dashboard"},
+		{"refreshed with unsafe redirect", "https://evil.com", nil, "/"},
+		{"session expired", "/dashboard", errors.UnauthorizedError{Msg: "unauthorised"}, "https://supertokens.io/auth?redirectToPath=%2Fdashboard"},
+		{"session expired with unsafe redirect", "//evil.com", errors.UnauthorizedError{Msg: "unauthorised"}, "https://supertokens.io/auth?redirectToPath=%2F"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refreshErr := test.refreshErr
+			refreshSession := func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+				return sessmodels.SessionContainer{}, refreshErr
+			}
+			req := httptest.NewRequest(http.MethodGet, "/auth/session/refresh", nil)
+			res := httptest.NewRecorder()
+			apiImpl := api.MakeAPIImplementation()
+			err := (*apiImpl.RefreshGET)(test.redirectToPath, sessmodels.APIOptions{
+				Config:               config,
+				RecipeImplementation: sessmodels.RecipeInterface{RefreshSession: &refreshSession},
+				Req:                  req,
+				Res:                  res,
+			}, &map[string]interface{}{})
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusFound, res.Code)
+			assert.Equal(t, test.location, res.Header().Get("Location"))
+			assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestPageRefreshFromAnotherSiteIsRejected(t *testing.T) {
+	customAntiCsrfVal := "VIA_CUSTOM_HEADER"
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				AntiCsrf:    &customAntiCsrfVal,
+				PageRefresh: &sessmodels.PageRefreshInputConfig{},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSession(rw, "user", map[string]interface{}{}, map[string]interface{}{})
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	refreshPage := func(secFetchSite string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/auth/session/refresh?redirectToPath=%2Fdashboard", nil)
+		assert.NoError(t, err)
+		req.Header.Add("Cookie", "sRefreshToken="+cookieData["sRefreshToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+		req.Header.Set("Sec-Fetch-Site", secFetchSite)
+		req.Header.Set("Sec-Fetch-Mode", "navigate")
+		res, err := client.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res = refreshPage("cross-site")
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "https://supertokens.io/auth?redirectToPath=%2Fdashboard", res.Header.Get("Location"))
+
+	res = refreshPage("same-origin")
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	assert.Equal(t, "/dashboard", res.Header.Get("Location"))
+}
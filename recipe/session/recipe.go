@@ -66,7 +66,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		if err != nil {
 			return Recipe{}, err
 		}
-		r.RecipeImpl = verifiedConfig.Override.Functions(sessionwithjwt.MakeRecipeImplementation(recipeImplementation, openIdRecipe.RecipeImpl, verifiedConfig))
+		r.RecipeImpl = verifiedConfig.Override.Functions(sessionwithjwt.MakeRecipeImplementation(recipeImplementation, openIdRecipe.RecipeImpl, verifiedConfig, buildAccessTokenPayloadOfNewSession(verifiedConfig)))
 		r.OpenIdRecipe = &openIdRecipe
 	} else {
 		r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
@@ -107,16 +107,48 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	sessionTransferAPIPathNormalised, err := supertokens.NewNormalisedURLPath(sessionTransferAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	sessionTransferExchangeAPIPathNormalised, err := supertokens.NewNormalisedURLPath(sessionTransferExchangeAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	accountDeletionAPIPathNormalised, err := supertokens.NewNormalisedURLPath(accountDeletionAPIPath)
+	if err != nil {
+		return nil, err
+	}
 	resp := []supertokens.APIHandled{{
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: refreshAPIPathNormalised,
 		ID:                     refreshAPIPath,
 		Disabled:               r.APIImpl.RefreshPOST == nil,
+	}, {
+		Method:                 http.MethodGet,
+		PathWithoutAPIBasePath: refreshAPIPathNormalised,
+		ID:                     refreshAPIPath,
+		Disabled:               r.APIImpl.RefreshGET == nil || !r.Config.PageRefresh.Enable,
 	}, {
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: signoutAPIPathNormalised,
 		ID:                     signoutAPIPath,
 		Disabled:               r.APIImpl.SignOutPOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: sessionTransferAPIPathNormalised,
+		ID:                     sessionTransferAPIPath,
+		Disabled:               r.APIImpl.SessionTransferPOST == nil || !r.Config.SessionTransfer.Enable,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: sessionTransferExchangeAPIPathNormalised,
+		ID:                     sessionTransferExchangeAPIPath,
+		Disabled:               r.APIImpl.SessionTransferExchangePOST == nil || !r.Config.SessionTransfer.Enable,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: accountDeletionAPIPathNormalised,
+		ID:                     accountDeletionAPIPath,
+		Disabled:               r.APIImpl.AccountDeletionPOST == nil || !r.Config.AccountDeletion.Enable,
 	}}
 
 	if r.OpenIdRecipe != nil {
@@ -140,9 +172,18 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		OtherHandler:         theirhandler,
 	}
 	if id == refreshAPIPath {
+		if method == http.MethodGet {
+			return api.HandleRefreshPageAPI(r.APIImpl, options)
+		}
 		return api.HandleRefreshAPI(r.APIImpl, options)
 	} else if id == signoutAPIPath {
 		return api.SignOutAPI(r.APIImpl, options)
+	} else if id == sessionTransferAPIPath {
+		return api.SessionTransferAPI(r.APIImpl, options)
+	} else if id == sessionTransferExchangeAPIPath {
+		return api.SessionTransferExchangeAPI(r.APIImpl, options)
+	} else if id == accountDeletionAPIPath {
+		return api.AccountDeletionAPI(r.APIImpl, options)
 	} else if r.OpenIdRecipe != nil {
 		return r.OpenIdRecipe.RecipeModule.HandleAPIRequest(id, req, res, theirhandler, path, method)
 	}
@@ -165,6 +206,11 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 	} else if defaultErrors.As(err, &errors.TokenTheftDetectedError{}) {
 		errs := err.(errors.TokenTheftDetectedError)
 		return true, r.Config.ErrorHandlers.OnTokenTheftDetected(errs.Payload.SessionHandle, errs.Payload.UserID, req, res)
+	} else if defaultErrors.As(err, &errors.SessionLimitReachedError{}) {
+		errs := err.(errors.SessionLimitReachedError)
+		return true, r.Config.ErrorHandlers.OnSessionLimitReached(errs.UserID, req, res)
+	} else if defaultErrors.As(err, &errors.SchemaValidationError{}) || defaultErrors.As(err, &errors.AccessTokenPayloadTooLargeError{}) {
+		return true, r.Config.ErrorHandlers.OnInvalidSessionPayload(err.Error(), req, res)
 	} else if r.OpenIdRecipe != nil {
 		return r.OpenIdRecipe.RecipeModule.HandleError(err, req, res)
 	}
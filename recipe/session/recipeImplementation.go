@@ -35,13 +35,66 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	var recipeImplHandshakeInfo *sessmodels.HandshakeInfo = nil
 	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
 
+	refreshes := newRefreshDeduplicator()
+
 	createNewSession := func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		var err error
+		if !isAccessTokenPayloadBuilt(userContext) {
+			accessTokenPayload, _, err = buildAccessTokenPayload(config, userID, accessTokenPayload, userContext)
+			if err != nil {
+				return sessmodels.SessionContainer{}, err
+			}
+		}
+		err = validateAccessTokenPayloadWithSchema(config, accessTokenPayload, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		err = validateSessionDataWithSchema(config, sessionData, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		if config.SessionLifetime.Enable {
+			accessTokenPayload = addSessionLifetimeInfoToAccessTokenPayload(accessTokenPayload)
+		}
+		if config.RememberMe.Enable && !getRememberMeFromUserContext(userContext) {
+			accessTokenPayload = addRememberMeInfoToAccessTokenPayload(config, accessTokenPayload)
+		}
+		encryptedAccessTokenPayload, err := encryptAccessTokenPayload(config, accessTokenPayload)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		// the size is checked before the session limit, which may revoke the other sessions of the user
+		err = validateAccessTokenPayloadSize(config, encryptedAccessTokenPayload)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		err = enforceSessionLimit(result, config, userID, accessTokenPayload, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		accessTokenPayload = encryptedAccessTokenPayload
+		sessionData, err = encryptSessionData(config, sessionData)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
 		response, err := createNewSessionHelper(recipeImplHandshakeInfo, config, querier, userID, accessTokenPayload, sessionData)
 		if err != nil {
 			return sessmodels.SessionContainer{}, err
 		}
 		attachCreateOrRefreshSessionResponseToRes(config, res, response)
-		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		userDataInAccessToken, err := decryptAccessTokenPayload(config, response.Session.UserDataInAccessToken)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		// signing in again cancels a pending account deletion. Impersonation does not count as the user signing in
+		_, impersonated := getImpersonatorUserID(userDataInAccessToken)
+		if config.AccountDeletion.Enable && !impersonated && !isGuestAccessTokenPayload(userDataInAccessToken) {
+			_, err = (*result.CancelAccountDeletion)(userID, userContext)
+			if err != nil {
+				return sessmodels.SessionContainer{}, err
+			}
+		}
+		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, userDataInAccessToken, res, result)
 		return newSessionContainer(config, &sessionContainerInput), nil
 	}
 
@@ -50,8 +103,12 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		if options != nil {
 			doAntiCsrfCheck = options.AntiCsrfCheck
 		}
+		antiCsrf, err := getAntiCsrfModeForRoute(config, options)
+		if err != nil {
+			return nil, err
+		}
 
-		idRefreshToken := getIDRefreshTokenFromCookie(req)
+		idRefreshToken := getIDRefreshTokenFromCookie(config, req)
 		if idRefreshToken == nil {
 			if options != nil && options.SessionRequired != nil &&
 				!(*options.SessionRequired) {
@@ -60,7 +117,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			return nil, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
 		}
 
-		accessToken := getAccessTokenFromCookie(req)
+		accessToken := getAccessTokenFromCookie(config, req)
 		if accessToken == nil {
 			if options == nil || (options.SessionRequired != nil && *options.SessionRequired) || frontendHasInterceptor(req) || req.Method == http.MethodGet {
 				return nil, errors.TryRefreshTokenError{
@@ -76,7 +133,14 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			doAntiCsrfCheck = &doAntiCsrfCheckBool
 		}
 
-		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
+		if *doAntiCsrfCheck {
+			err = doAntiCsrfCheckForRequest(config, antiCsrf, req)
+			if err != nil {
+				return nil, errors.TryRefreshTokenError{Msg: err.Error()}
+			}
+		}
+
+		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, antiCsrf, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
 		if err != nil {
 			if defaultErrors.As(err, &errors.UnauthorizedError{}) {
 				clearSessionFromCookie(config, res)
@@ -86,32 +150,114 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 
 		if !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 			setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
-			attachAccessTokenToCookie(config, res, response.AccessToken.Token, response.AccessToken.Expiry)
+			attachAccessTokenToCookie(config, res, response.AccessToken.Token, response.AccessToken.Expiry, isSessionRemembered(response.Session.UserDataInAccessToken))
 			accessToken = &response.AccessToken.Token
 		}
-		sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		userDataInAccessToken, err := decryptAccessTokenPayload(config, response.Session.UserDataInAccessToken)
+		if err != nil {
+			return nil, err
+		}
+		sessionContainerInput := makeSessionContainerInput(*accessToken, response.Session.Handle, response.Session.UserID, userDataInAccessToken, res, result)
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
+
+		err = enforceImpersonationExpiry(result, config, res, sessionContainer, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		err = enforceRememberMeExpiry(result, config, res, sessionContainer, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		err = enforceSessionLifetime(result, config, res, &sessionContainerInput, true, userContext)
+		if err != nil {
+			return nil, err
+		}
 		return &sessionContainer, nil
 	}
 
 	getSessionInformation := func(sessionHandle string, userContext supertokens.UserContext) (sessmodels.SessionInformation, error) {
-		return getSessionInformationHelper(querier, sessionHandle)
+		sessionInformation, err := getSessionInformationHelper(querier, sessionHandle)
+		if err != nil {
+			return sessmodels.SessionInformation{}, err
+		}
+		sessionInformation.SessionData, err = decryptSessionData(config, sessionInformation.SessionData)
+		if err != nil {
+			return sessmodels.SessionInformation{}, err
+		}
+		sessionInformation.AccessTokenPayload, err = decryptAccessTokenPayload(config, sessionInformation.AccessTokenPayload)
+		if err != nil {
+			return sessmodels.SessionInformation{}, err
+		}
+		return sessionInformation, nil
+	}
+
+	// rebuildAccessTokenPayloadOnRefresh runs the BuildAccessTokenPayload hook after a refresh. The core does not
+	// take a new payload while refreshing, so a changed payload is applied by regenerating the new access token before
+	// it is sent to the frontend.
+	rebuildAccessTokenPayloadOnRefresh := func(response sessmodels.CreateOrRefreshAPIResponse, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
+		if config.BuildAccessTokenPayload == nil {
+			return response, nil
+		}
+		currentAccessTokenPayload, err := decryptAccessTokenPayload(config, response.Session.UserDataInAccessToken)
+		if err != nil {
+			return sessmodels.CreateOrRefreshAPIResponse{}, err
+		}
+		newAccessTokenPayload, changed, err := buildAccessTokenPayload(config, response.Session.UserID, currentAccessTokenPayload, userContext)
+		if err != nil {
+			return sessmodels.CreateOrRefreshAPIResponse{}, err
+		}
+		if !changed {
+			return response, nil
+		}
+		regenerateResponse, err := (*result.RegenerateAccessToken)(response.AccessToken.Token, &newAccessTokenPayload, userContext)
+		if err != nil {
+			return sessmodels.CreateOrRefreshAPIResponse{}, err
+		}
+		response.Session.UserDataInAccessToken = regenerateResponse.Session.UserDataInAccessToken
+		if !reflect.DeepEqual(regenerateResponse.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
+			response.AccessToken = regenerateResponse.AccessToken
+		}
+		return response, nil
 	}
 
 	refreshSession := func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-		inputIdRefreshToken := getIDRefreshTokenFromCookie(req)
+		inputIdRefreshToken := getIDRefreshTokenFromCookie(config, req)
 		if inputIdRefreshToken == nil {
 			return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
 		}
 
-		inputRefreshToken := getRefreshTokenFromCookie(req)
+		inputRefreshToken := getRefreshTokenFromCookie(config, req)
 		if inputRefreshToken == nil {
 			clearSessionFromCookie(config, res)
 			return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Refresh token not found. Are you sending the refresh token in the request as a cookie?"}
 		}
 
+		isPageRefresh := isPageRefreshRequest(config, req)
+		if !isPageRefresh {
+			err := doAntiCsrfCheckForRequest(config, config.AntiCsrf, req)
+			if err != nil {
+				clearCookies := false
+				return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: err.Error(), ClearCookies: &clearCookies}
+			}
+		}
+
 		antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
-		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, getRidFromHeader(req) != nil)
+		containsCustomHeader := getRidFromHeader(req) != nil || isPageRefresh
+		refreshKey := getRefreshDeduplicationKey(*inputRefreshToken, antiCsrfToken, containsCustomHeader)
+		response, err := refreshes.refresh(refreshKey, config.RefreshTokenReuseGraceWindowMS, func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+			response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *inputRefreshToken, antiCsrfToken, containsCustomHeader)
+			if err != nil {
+				var tokenTheftErr errors.TokenTheftDetectedError
+				if config.TokenTheftPolicy.Enable && defaultErrors.As(err, &tokenTheftErr) {
+					event := makeTokenTheftEvent(tokenTheftErr.Payload.SessionHandle, tokenTheftErr.Payload.UserID, req)
+					runTokenTheftPolicy(config.TokenTheftPolicy, event, userContext)
+				}
+				return sessmodels.CreateOrRefreshAPIResponse{}, err
+			}
+			return rebuildAccessTokenPayloadOnRefresh(response, userContext)
+		})
 		if err != nil {
 			// we clear cookies if it is UnauthorizedError & ClearCookies in it is nil or true
 			// we clear cookies if it is TokenTheftDetectedError
@@ -121,8 +267,27 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			return sessmodels.SessionContainer{}, err
 		}
 		attachCreateOrRefreshSessionResponseToRes(config, res, response)
-		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, result)
+		userDataInAccessToken, err := decryptAccessTokenPayload(config, response.Session.UserDataInAccessToken)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, userDataInAccessToken, res, result)
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
+
+		err = enforceImpersonationExpiry(result, config, res, sessionContainer, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+
+		err = enforceRememberMeExpiry(result, config, res, sessionContainer, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+
+		err = enforceSessionLifetime(result, config, res, &sessionContainerInput, false, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
 		return sessionContainer, nil
 	}
 
@@ -143,10 +308,43 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	}
 
 	updateSessionData := func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) error {
+		err := validateSessionDataWithSchema(config, newSessionData, userContext)
+		if err != nil {
+			return err
+		}
+		newSessionData, err = encryptSessionData(config, newSessionData)
+		if err != nil {
+			return err
+		}
 		return updateSessionDataHelper(querier, sessionHandle, newSessionData)
 	}
 
 	updateAccessTokenPayload := func(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+		err := validateAccessTokenPayloadWithSchema(config, newAccessTokenPayload, userContext)
+		if err != nil {
+			return err
+		}
+		existingAccessTokenPayload := map[string]interface{}{}
+		if usesReservedAccessTokenPayloadKeys(config) {
+			sessionInformation, err := (*result.GetSessionInformation)(sessionHandle, userContext)
+			if err != nil {
+				return err
+			}
+			existingAccessTokenPayload = sessionInformation.AccessTokenPayload
+		}
+		err = validateReservedAccessTokenPayloadKeys(existingAccessTokenPayload, newAccessTokenPayload)
+		if err != nil {
+			return err
+		}
+		newAccessTokenPayload = copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload, newAccessTokenPayload)
+		newAccessTokenPayload, err = encryptAccessTokenPayload(config, newAccessTokenPayload)
+		if err != nil {
+			return err
+		}
+		err = validateAccessTokenPayloadSize(config, newAccessTokenPayload)
+		if err != nil {
+			return err
+		}
 		return updateAccessTokenPayloadHelper(querier, sessionHandle, newAccessTokenPayload)
 	}
 
@@ -166,24 +364,181 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		return recipeImplHandshakeInfo.RefreshTokenValidity, nil
 	}
 
+	createNewImpersonationSession := func(res http.ResponseWriter, targetUserID string, impersonatorUserID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		if !config.Impersonation.Enable {
+			return sessmodels.SessionContainer{}, defaultErrors.New("impersonation is not enabled. Please provide the Impersonation config when initialising the session recipe")
+		}
+		if impersonatorUserID == "" {
+			return sessmodels.SessionContainer{}, defaultErrors.New("impersonatorUserID must not be empty")
+		}
+		accessTokenPayload = addImpersonationInfoToAccessTokenPayload(accessTokenPayload, impersonatorUserID, getCurrTimeInMS()+config.Impersonation.MaxLifetimeMS)
+		sessionContainer, err := (*result.CreateNewSession)(res, targetUserID, accessTokenPayload, sessionData, userContext)
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		if config.Impersonation.OnImpersonationStart != nil {
+			config.Impersonation.OnImpersonationStart(sessmodels.ImpersonationEvent{
+				SessionHandle:      sessionContainer.GetHandleWithContext(userContext),
+				UserID:             targetUserID,
+				ImpersonatorUserID: impersonatorUserID,
+			}, userContext)
+		}
+		return sessionContainer, nil
+	}
+
+	createNewGuestSession := func(res http.ResponseWriter, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		if !config.GuestSession.Enable {
+			return sessmodels.SessionContainer{}, defaultErrors.New("guest sessions are not enabled. Please provide the GuestSession config when initialising the session recipe")
+		}
+		guestUserID, err := generateGuestUserID()
+		if err != nil {
+			return sessmodels.SessionContainer{}, err
+		}
+		guestAccessTokenPayload := map[string]interface{}{}
+		for k, v := range accessTokenPayload {
+			guestAccessTokenPayload[k] = v
+		}
+		guestAccessTokenPayload[isGuestPayloadKey] = true
+		return (*result.CreateNewSession)(res, guestUserID, guestAccessTokenPayload, sessionData, userContext)
+	}
+
 	regenerateAccessToken := func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (sessmodels.RegenerateAccessTokenResponse, error) {
+		if newAccessTokenPayload != nil {
+			err := validateAccessTokenPayloadWithSchema(config, *newAccessTokenPayload, userContext)
+			if err != nil {
+				return sessmodels.RegenerateAccessTokenResponse{}, err
+			}
+			encryptedAccessTokenPayload, err := encryptAccessTokenPayload(config, *newAccessTokenPayload)
+			if err != nil {
+				return sessmodels.RegenerateAccessTokenResponse{}, err
+			}
+			err = validateAccessTokenPayloadSize(config, encryptedAccessTokenPayload)
+			if err != nil {
+				return sessmodels.RegenerateAccessTokenResponse{}, err
+			}
+			newAccessTokenPayload = &encryptedAccessTokenPayload
+		}
 		return regenerateAccessTokenHelper(querier, newAccessTokenPayload, accessToken)
 	}
 
+	createSessionTransferToken := func(userID string, targetOrigin string, userContext supertokens.UserContext) (sessmodels.CreateSessionTransferTokenResponse, error) {
+		if !config.SessionTransfer.Enable {
+			return sessmodels.CreateSessionTransferTokenResponse{}, defaultErrors.New("session transfer is not enabled. Please provide the SessionTransfer config when initialising the session recipe")
+		}
+		transferToken, err := createSessionTransferToken(config, userID, targetOrigin)
+		if err != nil {
+			return sessmodels.CreateSessionTransferTokenResponse{}, err
+		}
+		if transferToken == nil {
+			return sessmodels.CreateSessionTransferTokenResponse{
+				TargetOriginNotAllowedError: &struct{}{},
+			}, nil
+		}
+		return sessmodels.CreateSessionTransferTokenResponse{
+			OK: &struct{ TransferToken string }{
+				TransferToken: *transferToken,
+			},
+		}, nil
+	}
+
+	exchangeSessionTransferToken := func(res http.ResponseWriter, transferToken string, userContext supertokens.UserContext) (sessmodels.ExchangeSessionTransferTokenResponse, error) {
+		if !config.SessionTransfer.Enable {
+			return sessmodels.ExchangeSessionTransferTokenResponse{}, defaultErrors.New("session transfer is not enabled. Please provide the SessionTransfer config when initialising the session recipe")
+		}
+		token := verifySessionTransferToken(config, transferToken, getCurrTimeInMS())
+		if token == nil {
+			return sessmodels.ExchangeSessionTransferTokenResponse{
+				InvalidTransferTokenError: &struct{}{},
+			}, nil
+		}
+		unused, err := config.SessionTransfer.MarkTokenAsUsed(token.ID, token.Expiry, userContext)
+		if err != nil {
+			return sessmodels.ExchangeSessionTransferTokenResponse{}, err
+		}
+		if !unused {
+			return sessmodels.ExchangeSessionTransferTokenResponse{
+				InvalidTransferTokenError: &struct{}{},
+			}, nil
+		}
+		session, err := (*result.CreateNewSession)(res, token.UserID, map[string]interface{}{}, map[string]interface{}{}, userContext)
+		if err != nil {
+			return sessmodels.ExchangeSessionTransferTokenResponse{}, err
+		}
+		return sessmodels.ExchangeSessionTransferTokenResponse{
+			OK: &struct{ Session sessmodels.SessionContainer }{
+				Session: session,
+			},
+		}, nil
+	}
+
+	scheduleAccountDeletion := func(userID string, userContext supertokens.UserContext) (sessmodels.ScheduleAccountDeletionResponse, error) {
+		if !config.AccountDeletion.Enable {
+			return sessmodels.ScheduleAccountDeletionResponse{}, defaultErrors.New("account deletion is not enabled. Please provide the AccountDeletion config when initialising the session recipe")
+		}
+		deletionTime := getCurrTimeInMS() + config.AccountDeletion.GracePeriodMS
+		err := config.AccountDeletion.SetPendingAccountDeletion(userID, deletionTime, userContext)
+		if err != nil {
+			return sessmodels.ScheduleAccountDeletionResponse{}, err
+		}
+		_, err = (*result.RevokeAllSessionsForUser)(userID, userContext)
+		if err != nil {
+			return sessmodels.ScheduleAccountDeletionResponse{}, err
+		}
+		if config.AccountDeletion.OnAccountDeletionScheduled != nil {
+			config.AccountDeletion.OnAccountDeletionScheduled(userID, deletionTime, userContext)
+		}
+		return sessmodels.ScheduleAccountDeletionResponse{
+			OK: &struct{ DeletionTime uint64 }{
+				DeletionTime: deletionTime,
+			},
+		}, nil
+	}
+
+	cancelAccountDeletion := func(userID string, userContext supertokens.UserContext) (sessmodels.CancelAccountDeletionResponse, error) {
+		if !config.AccountDeletion.Enable {
+			return sessmodels.CancelAccountDeletionResponse{}, defaultErrors.New("account deletion is not enabled. Please provide the AccountDeletion config when initialising the session recipe")
+		}
+		deletionTime, err := config.AccountDeletion.GetPendingAccountDeletion(userID, userContext)
+		if err != nil {
+			return sessmodels.CancelAccountDeletionResponse{}, err
+		}
+		if deletionTime == nil {
+			return sessmodels.CancelAccountDeletionResponse{
+				OK: &struct{ Cancelled bool }{Cancelled: false},
+			}, nil
+		}
+		err = config.AccountDeletion.RemovePendingAccountDeletion(userID, userContext)
+		if err != nil {
+			return sessmodels.CancelAccountDeletionResponse{}, err
+		}
+		if config.AccountDeletion.OnAccountDeletionCancelled != nil {
+			config.AccountDeletion.OnAccountDeletionCancelled(userID, userContext)
+		}
+		return sessmodels.CancelAccountDeletionResponse{
+			OK: &struct{ Cancelled bool }{Cancelled: true},
+		}, nil
+	}
+
 	result = sessmodels.RecipeInterface{
-		CreateNewSession:            &createNewSession,
-		GetSession:                  &getSession,
-		RefreshSession:              &refreshSession,
-		GetSessionInformation:       &getSessionInformation,
-		RevokeAllSessionsForUser:    &revokeAllSessionsForUser,
-		GetAllSessionHandlesForUser: &getAllSessionHandlesForUser,
-		RevokeSession:               &revokeSession,
-		RevokeMultipleSessions:      &revokeMultipleSessions,
-		UpdateSessionData:           &updateSessionData,
-		UpdateAccessTokenPayload:    &updateAccessTokenPayload,
-		GetAccessTokenLifeTimeMS:    &getAccessTokenLifeTimeMS,
-		GetRefreshTokenLifeTimeMS:   &getRefreshTokenLifeTimeMS,
-		RegenerateAccessToken:       &regenerateAccessToken,
+		CreateNewSession:              &createNewSession,
+		GetSession:                    &getSession,
+		RefreshSession:                &refreshSession,
+		GetSessionInformation:         &getSessionInformation,
+		RevokeAllSessionsForUser:      &revokeAllSessionsForUser,
+		GetAllSessionHandlesForUser:   &getAllSessionHandlesForUser,
+		RevokeSession:                 &revokeSession,
+		RevokeMultipleSessions:        &revokeMultipleSessions,
+		UpdateSessionData:             &updateSessionData,
+		UpdateAccessTokenPayload:      &updateAccessTokenPayload,
+		GetAccessTokenLifeTimeMS:      &getAccessTokenLifeTimeMS,
+		GetRefreshTokenLifeTimeMS:     &getRefreshTokenLifeTimeMS,
+		RegenerateAccessToken:         &regenerateAccessToken,
+		CreateNewImpersonationSession: &createNewImpersonationSession,
+		CreateNewGuestSession:         &createNewGuestSession,
+		CreateSessionTransferToken:    &createSessionTransferToken,
+		ExchangeSessionTransferToken:  &exchangeSessionTransferToken,
+		ScheduleAccountDeletion:       &scheduleAccountDeletion,
+		CancelAccountDeletion:         &cancelAccountDeletion,
 	}
 
 	return result
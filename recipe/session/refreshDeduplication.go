@@ -0,0 +1,102 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+type refreshCall struct {
+	done        chan struct{}
+	response    sessmodels.CreateOrRefreshAPIResponse
+	err         error
+	completedAt uint64
+}
+
+// refreshDeduplicator makes sure that concurrent refresh calls using the same refresh token within this process
+// result in one call to the core. If a grace window is configured, the response of a successful refresh is also
+// replayed to the requests that use the same refresh token within that window, instead of the core treating the
+// reuse of the refresh token as token theft.
+type refreshDeduplicator struct {
+	lock  sync.Mutex
+	calls map[string]*refreshCall
+}
+
+func newRefreshDeduplicator() *refreshDeduplicator {
+	return &refreshDeduplicator{
+		calls: map[string]*refreshCall{},
+	}
+}
+
+// getRefreshDeduplicationKey includes the anti-csrf inputs of the request so that a request failing the anti-csrf
+// check can never be given the response of a request that passed it
+func getRefreshDeduplicationKey(refreshToken string, antiCsrfToken *string, containsCustomHeader bool) string {
+	input := refreshToken + ";"
+	if antiCsrfToken != nil {
+		input += *antiCsrfToken
+	}
+	if containsCustomHeader {
+		input += ";rid"
+	}
+	hash := sha256.Sum256([]byte(input))
+	return hex.EncodeToString(hash[:])
+}
+
+func (d *refreshDeduplicator) refresh(key string, graceWindowMS uint64, refresh func() (sessmodels.CreateOrRefreshAPIResponse, error)) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	d.lock.Lock()
+	d.removeExpiredCalls(graceWindowMS)
+	call, ok := d.calls[key]
+	if ok {
+		d.lock.Unlock()
+		<-call.done
+		return call.response, call.err
+	}
+	call = &refreshCall{
+		done: make(chan struct{}),
+		// replaced by the result of refresh, unless it panics
+		err: errors.New("refreshing the session failed"),
+	}
+	d.calls[key] = call
+	d.lock.Unlock()
+
+	defer func() {
+		d.lock.Lock()
+		if call.err != nil || graceWindowMS == 0 {
+			delete(d.calls, key)
+		} else {
+			call.completedAt = getCurrTimeInMS()
+		}
+		d.lock.Unlock()
+		close(call.done)
+	}()
+	call.response, call.err = refresh()
+	return call.response, call.err
+}
+
+// removeExpiredCalls must be called with the lock held
+func (d *refreshDeduplicator) removeExpiredCalls(graceWindowMS uint64) {
+	currentTime := getCurrTimeInMS()
+	for key, call := range d.calls {
+		if call.completedAt != 0 && call.completedAt+graceWindowMS <= currentTime {
+			delete(d.calls, key)
+		}
+	}
+}
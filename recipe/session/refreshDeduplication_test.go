@@ -0,0 +1,125 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func TestConcurrentRefreshesAreDeduplicated(t *testing.T) {
+	deduplicator := newRefreshDeduplicator()
+	var calls int32
+	release := make(chan struct{})
+	refresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return sessmodels.CreateOrRefreshAPIResponse{
+			RefreshToken: sessmodels.CreateOrRefreshAPIResponseToken{Token: "newRefreshToken"},
+		}, nil
+	}
+
+	key := getRefreshDeduplicationKey("refreshToken", nil, true)
+	var wg sync.WaitGroup
+	responses := make([]sessmodels.CreateOrRefreshAPIResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := deduplicator.refresh(key, 0, refresh)
+			assert.NoError(t, err)
+			responses[i] = response
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, response := range responses {
+		assert.Equal(t, "newRefreshToken", response.RefreshToken.Token)
+	}
+	assert.Equal(t, 0, len(deduplicator.calls))
+}
+
+func TestRefreshIsReplayedWithinGraceWindow(t *testing.T) {
+	deduplicator := newRefreshDeduplicator()
+	calls := 0
+	refresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		calls++
+		return sessmodels.CreateOrRefreshAPIResponse{}, nil
+	}
+
+	key := getRefreshDeduplicationKey("refreshToken", nil, true)
+	_, err := deduplicator.refresh(key, 100, refresh)
+	assert.NoError(t, err)
+	_, err = deduplicator.refresh(key, 100, refresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	time.Sleep(150 * time.Millisecond)
+	_, err = deduplicator.refresh(key, 100, refresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRefreshIsNotReplayedWithoutGraceWindow(t *testing.T) {
+	deduplicator := newRefreshDeduplicator()
+	calls := 0
+	refresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		calls++
+		return sessmodels.CreateOrRefreshAPIResponse{}, nil
+	}
+
+	key := getRefreshDeduplicationKey("refreshToken", nil, true)
+	deduplicator.refresh(key, 0, refresh)
+	deduplicator.refresh(key, 0, refresh)
+	assert.Equal(t, 2, calls)
+}
+
+func TestFailedRefreshIsNotReplayed(t *testing.T) {
+	deduplicator := newRefreshDeduplicator()
+	calls := 0
+	refresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		calls++
+		return sessmodels.CreateOrRefreshAPIResponse{}, errors.New("unauthorised")
+	}
+
+	key := getRefreshDeduplicationKey("refreshToken", nil, true)
+	_, err := deduplicator.refresh(key, 1000, refresh)
+	assert.Error(t, err)
+	_, err = deduplicator.refresh(key, 1000, refresh)
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRefreshDeduplicationKeyIncludesAntiCsrfInputs(t *testing.T) {
+	antiCsrfToken := "antiCsrfToken"
+	otherAntiCsrfToken := "otherAntiCsrfToken"
+	key := getRefreshDeduplicationKey("refreshToken", &antiCsrfToken, true)
+
+	assert.Equal(t, key, getRefreshDeduplicationKey("refreshToken", &antiCsrfToken, true))
+	assert.NotEqual(t, key, getRefreshDeduplicationKey("refreshToken", &otherAntiCsrfToken, true))
+	assert.NotEqual(t, key, getRefreshDeduplicationKey("refreshToken", nil, true))
+	assert.NotEqual(t, key, getRefreshDeduplicationKey("refreshToken", &antiCsrfToken, false))
+	assert.NotEqual(t, key, getRefreshDeduplicationKey("otherRefreshToken", &antiCsrfToken, true))
+}
@@ -0,0 +1,80 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// SetRememberMe sets whether a session created with this user context should outlive the browser being closed.
+// The sign in and sign up APIs set it from the rememberMe field in the request body. Sessions are remembered by default, and
+// this has no effect unless the RememberMe config is given.
+func SetRememberMe(userContext supertokens.UserContext, rememberMe bool) {
+	if userContext == nil {
+		return
+	}
+	(*userContext)[rememberMeUserContextKey] = rememberMe
+}
+
+func getRememberMeFromUserContext(userContext supertokens.UserContext) bool {
+	if userContext == nil {
+		return true
+	}
+	rememberMe, ok := (*userContext)[rememberMeUserContextKey].(bool)
+	return !ok || rememberMe
+}
+
+// addRememberMeInfoToAccessTokenPayload marks a session that is not remembered, so that the refreshed tokens of
+// the session are also set in cookies without an expiry
+func addRememberMeInfoToAccessTokenPayload(config sessmodels.TypeNormalisedInput, accessTokenPayload map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range accessTokenPayload {
+		result[k] = v
+	}
+	result[rememberMePayloadKey] = false
+	if config.RememberMe.MaxLifetimeMS > 0 {
+		result[rememberMeExpiryPayloadKey] = getCurrTimeInMS() + config.RememberMe.MaxLifetimeMS
+	}
+	return result
+}
+
+func isSessionRemembered(accessTokenPayload map[string]interface{}) bool {
+	rememberMe, ok := accessTokenPayload[rememberMePayloadKey].(bool)
+	return !ok || rememberMe
+}
+
+func isRememberMeExpired(accessTokenPayload map[string]interface{}, now uint64) bool {
+	expiry, ok := getTimeFromAccessTokenPayload(accessTokenPayload, rememberMeExpiryPayloadKey)
+	return ok && now > expiry
+}
+
+// enforceRememberMeExpiry revokes a session that was created without remember me and returns an UnauthorizedError
+// once it has gone past config.RememberMe.MaxLifetimeMS
+func enforceRememberMeExpiry(recipeImpl sessmodels.RecipeInterface, config sessmodels.TypeNormalisedInput, res http.ResponseWriter, session sessmodels.SessionContainer, userContext supertokens.UserContext) error {
+	if !isRememberMeExpired(session.GetAccessTokenPayloadWithContext(userContext), getCurrTimeInMS()) {
+		return nil
+	}
+	_, err := (*recipeImpl.RevokeSession)(session.GetHandleWithContext(userContext), userContext)
+	if err != nil {
+		return err
+	}
+	clearSessionFromCookie(config, res)
+	return errors.UnauthorizedError{Msg: "Session has exceeded its maximum lifetime. Please sign in again"}
+}
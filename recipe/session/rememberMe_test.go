@@ -0,0 +1,116 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func makeCreateOrRefreshAPIResponseForTest(accessTokenPayload map[string]interface{}) sessmodels.CreateOrRefreshAPIResponse {
+	return sessmodels.CreateOrRefreshAPIResponse{
+		Session: sessmodels.SessionStruct{
+			Handle:                "sessionHandle",
+			UserID:                "userId",
+			UserDataInAccessToken: accessTokenPayload,
+		},
+		AccessToken:    sessmodels.CreateOrRefreshAPIResponseToken{Token: "accessToken", Expiry: 1000000},
+		RefreshToken:   sessmodels.CreateOrRefreshAPIResponseToken{Token: "refreshToken", Expiry: 2000000},
+		IDRefreshToken: sessmodels.CreateOrRefreshAPIResponseToken{Token: "idRefreshToken", Expiry: 2000000},
+	}
+}
+
+func TestRememberedSessionCookiesHaveExpiry(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", nil)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	attachCreateOrRefreshSessionResponseToRes(config, res, makeCreateOrRefreshAPIResponseForTest(map[string]interface{}{}))
+	cookies := res.Header().Values("Set-Cookie")
+	assert.Equal(t, 3, len(cookies))
+	for _, cookie := range cookies {
+		assert.Contains(t, cookie, "Expires=")
+	}
+}
+
+func TestNotRememberedSessionCookiesHaveNoExpiry(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", nil)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	attachCreateOrRefreshSessionResponseToRes(config, res, makeCreateOrRefreshAPIResponseForTest(map[string]interface{}{rememberMePayloadKey: false}))
+	cookies := res.Header().Values("Set-Cookie")
+	assert.Equal(t, 3, len(cookies))
+	for _, cookie := range cookies {
+		assert.NotContains(t, cookie, "Expires=")
+	}
+	// the frontend still gets the expiry of the session
+	assert.Equal(t, "idRefreshToken;2000000", res.Header().Get(idRefreshTokenHeaderKey))
+
+	// clearing the cookies still sets an expiry in the past
+	res = httptest.NewRecorder()
+	clearSessionFromCookie(config, res)
+	for _, cookie := range res.Header().Values("Set-Cookie") {
+		assert.True(t, strings.Contains(cookie, "Expires=Thu, 01 Jan 1970"))
+	}
+}
+
+func TestRememberMeFromUserContext(t *testing.T) {
+	assert.True(t, getRememberMeFromUserContext(nil))
+
+	userContext := &map[string]interface{}{}
+	assert.True(t, getRememberMeFromUserContext(userContext))
+
+	SetRememberMe(userContext, false)
+	assert.False(t, getRememberMeFromUserContext(userContext))
+
+	SetRememberMe(userContext, true)
+	assert.True(t, getRememberMeFromUserContext(userContext))
+}
+
+func TestAddRememberMeInfoToAccessTokenPayload(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", nil)
+	assert.NoError(t, err)
+
+	accessTokenPayload := addRememberMeInfoToAccessTokenPayload(config, map[string]interface{}{"role": "admin"})
+	assert.Equal(t, map[string]interface{}{"role": "admin", rememberMePayloadKey: false}, accessTokenPayload)
+	assert.False(t, isSessionRemembered(accessTokenPayload))
+	assert.False(t, isRememberMeExpired(accessTokenPayload, getCurrTimeInMS()))
+	assert.True(t, isSessionRemembered(map[string]interface{}{"role": "admin"}))
+
+	config, err = getNormalisedConfigForCookieTests(t, "https://api.supertokens.io", &sessmodels.TypeInput{
+		RememberMe: &sessmodels.RememberMeInputConfig{MaxLifetimeMS: 1000},
+	})
+	assert.NoError(t, err)
+
+	now := getCurrTimeInMS()
+	accessTokenPayload = addRememberMeInfoToAccessTokenPayload(config, nil)
+	expiry, ok := getTimeFromAccessTokenPayload(accessTokenPayload, rememberMeExpiryPayloadKey)
+	assert.True(t, ok)
+	assert.GreaterOrEqual(t, expiry, now+1000)
+	assert.False(t, isRememberMeExpired(accessTokenPayload, now))
+	assert.True(t, isRememberMeExpired(accessTokenPayload, expiry+1))
+
+	// the remember me info is kept when the access token payload is updated
+	accessTokenPayload = copyReservedKeysToAccessTokenPayload(accessTokenPayload, map[string]interface{}{})
+	assert.False(t, isSessionRemembered(accessTokenPayload))
+	_, ok = accessTokenPayload[rememberMeExpiryPayloadKey]
+	assert.True(t, ok)
+}
@@ -45,6 +45,28 @@ func makeSessionContainerInput(accessToken string, sessionHandle string, userID
 	}
 }
 
+// regenerateAccessTokenOfSession sets the access token payload of the session as it is, including the keys reserved
+// by the SDK, and sends the new access token to the frontend
+func regenerateAccessTokenOfSession(config sessmodels.TypeNormalisedInput, session *SessionContainerInput, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+	resp, err := (*session.recipeImpl.RegenerateAccessToken)(session.accessToken, &newAccessTokenPayload, userContext)
+	if err != nil {
+		return err
+	}
+
+	userDataInAccessToken, err := decryptAccessTokenPayload(config, resp.Session.UserDataInAccessToken)
+	if err != nil {
+		return err
+	}
+	session.userDataInAccessToken = userDataInAccessToken
+
+	if !reflect.DeepEqual(resp.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
+		session.accessToken = resp.AccessToken.Token
+		setFrontTokenInHeaders(session.res, resp.Session.UserID, resp.AccessToken.Expiry, resp.Session.UserDataInAccessToken)
+		attachAccessTokenToCookie(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry, isSessionRemembered(resp.Session.UserDataInAccessToken))
+	}
+	return nil
+}
+
 func newSessionContainer(config sessmodels.TypeNormalisedInput, session *SessionContainerInput) sessmodels.SessionContainer {
 
 	revokeSessionWithContext := func(userContext supertokens.UserContext) error {
@@ -54,6 +76,7 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		}
 		if success {
 			clearSessionFromCookie(config, session.res)
+			emitImpersonationEnd(config, session.sessionHandle, session.userID, session.userDataInAccessToken, impersonationEnd_REVOKED, userContext)
 		}
 		return nil
 	}
@@ -84,21 +107,12 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		if newAccessTokenPayload == nil {
 			newAccessTokenPayload = map[string]interface{}{}
 		}
-
-		resp, err := (*session.recipeImpl.RegenerateAccessToken)(session.accessToken, &newAccessTokenPayload, userContext)
-
+		err := validateReservedAccessTokenPayloadKeys(session.userDataInAccessToken, newAccessTokenPayload)
 		if err != nil {
 			return err
 		}
-
-		session.userDataInAccessToken = resp.Session.UserDataInAccessToken
-
-		if !reflect.DeepEqual(resp.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
-			session.accessToken = resp.AccessToken.Token
-			setFrontTokenInHeaders(session.res, resp.Session.UserID, resp.AccessToken.Expiry, resp.Session.UserDataInAccessToken)
-			attachAccessTokenToCookie(config, session.res, resp.AccessToken.Token, resp.AccessToken.Expiry)
-		}
-		return nil
+		newAccessTokenPayload = copyReservedKeysToAccessTokenPayload(session.userDataInAccessToken, newAccessTokenPayload)
+		return regenerateAccessTokenOfSession(config, session, newAccessTokenPayload, userContext)
 	}
 
 	getTimeCreatedWithContext := func(userContext supertokens.UserContext) (uint64, error) {
@@ -130,6 +144,15 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		return session.userDataInAccessToken
 	}
 
+	isImpersonatedWithContext := func(userContext supertokens.UserContext) bool {
+		_, ok := getImpersonatorUserID(session.userDataInAccessToken)
+		return ok
+	}
+
+	isGuestWithContext := func(userContext supertokens.UserContext) bool {
+		return isGuestAccessTokenPayload(session.userDataInAccessToken)
+	}
+
 	getHandleWithContext := func(userContext supertokens.UserContext) string {
 		return session.sessionHandle
 	}
@@ -148,6 +171,8 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		GetAccessTokenWithContext:           getAccessTokenWithContext,
 		GetTimeCreatedWithContext:           getTimeCreatedWithContext,
 		GetExpiryWithContext:                getExpiryWithContext,
+		IsImpersonatedWithContext:           isImpersonatedWithContext,
+		IsGuestWithContext:                  isGuestWithContext,
 		RevokeSession: func() error {
 			return revokeSessionWithContext(&map[string]interface{}{})
 		},
@@ -178,5 +203,11 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		GetExpiry: func() (uint64, error) {
 			return getExpiryWithContext(&map[string]interface{}{})
 		},
+		IsImpersonated: func() bool {
+			return isImpersonatedWithContext(&map[string]interface{}{})
+		},
+		IsGuest: func() bool {
+			return isGuestWithContext(&map[string]interface{}{})
+		},
 	}
 }
@@ -61,10 +61,16 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
+	if config.AntiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE {
+		resp.AntiCsrfToken, err = generateDoubleSubmitAntiCsrfToken()
+		if err != nil {
+			return sessmodels.CreateOrRefreshAPIResponse{}, err
+		}
+	}
 	return resp, nil
 }
 
-func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
+func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, accessToken string, antiCsrfToken *string, antiCsrf string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
 	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
 	if err != nil {
 		return sessmodels.GetSessionResponse{}, err
@@ -74,7 +80,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	foundASigningKeyThatIsOlderThanTheAccessToken := false
 	for _, key := range recipeImplHandshakeInfo.GetJwtSigningPublicKeyList() {
 
-		accessTokenInfo, err = getInfoFromAccessToken(accessToken, key.PublicKey, recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck)
+		accessTokenInfo, err = getInfoFromAccessToken(accessToken, key.PublicKey, antiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck)
 		if err != nil {
 			if !defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
 				return sessmodels.GetSessionResponse{}, err
@@ -110,7 +116,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 
 	if doAntiCsrfCheck {
-		if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN {
+		if antiCsrf == antiCSRF_VIA_TOKEN {
 			if accessTokenInfo != nil {
 				if antiCsrfToken == nil || *antiCsrfToken != *accessTokenInfo.antiCsrfToken {
 					if antiCsrfToken == nil {
@@ -120,7 +126,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 					}
 				}
 			}
-		} else if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
+		} else if antiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
 			if !containsCustomHeader {
 				return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request, or set doAntiCsrfCheck to false for this API"}
 			}
@@ -140,7 +146,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 	requestBody := map[string]interface{}{
 		"accessToken":     accessToken,
-		"doAntiCsrfCheck": doAntiCsrfCheck,
+		"doAntiCsrfCheck": antiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck,
 		"enableAntiCsrf":  recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
 	}
 	if antiCsrfToken != nil {
@@ -236,6 +242,12 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 		if err != nil {
 			return sessmodels.CreateOrRefreshAPIResponse{}, err
 		}
+		if config.AntiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE {
+			result.AntiCsrfToken, err = generateDoubleSubmitAntiCsrfToken()
+			if err != nil {
+				return sessmodels.CreateOrRefreshAPIResponse{}, err
+			}
+		}
 		return result, nil
 	} else if response["status"].(string) == errors.UnauthorizedErrorStr {
 		return sessmodels.CreateOrRefreshAPIResponse{}, errors.UnauthorizedError{Msg: response["message"].(string)}
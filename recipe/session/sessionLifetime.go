@@ -0,0 +1,121 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func addSessionLifetimeInfoToAccessTokenPayload(accessTokenPayload map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range accessTokenPayload {
+		result[k] = v
+	}
+	now := getCurrTimeInMS()
+	result[signInTimePayloadKey] = now
+	result[lastActivityTimePayloadKey] = now
+	return result
+}
+
+func getTimeFromAccessTokenPayload(accessTokenPayload map[string]interface{}, key string) (uint64, bool) {
+	switch val := accessTokenPayload[key].(type) {
+	case float64:
+		return uint64(val), true
+	case uint64:
+		return val, true
+	}
+	return 0, false
+}
+
+// getSessionLifetimeExpiredReason returns nil if the session is still within its idle timeout and absolute lifetime
+func getSessionLifetimeExpiredReason(lifetime sessmodels.SessionLifetime, accessTokenPayload map[string]interface{}, now uint64) *string {
+	if lifetime.AbsoluteLifetimeMS > 0 {
+		signInTime, ok := getTimeFromAccessTokenPayload(accessTokenPayload, signInTimePayloadKey)
+		if ok && now > signInTime && now-signInTime > lifetime.AbsoluteLifetimeMS {
+			reason := "Session has exceeded its maximum lifetime. Please sign in again"
+			return &reason
+		}
+	}
+	if lifetime.IdleTimeoutMS > 0 {
+		lastActivityTime, ok := getTimeFromAccessTokenPayload(accessTokenPayload, lastActivityTimePayloadKey)
+		if ok && now > lastActivityTime && now-lastActivityTime > lifetime.IdleTimeoutMS {
+			reason := "Session has been idle for too long. Please sign in again"
+			return &reason
+		}
+	}
+	return nil
+}
+
+func shouldUpdateLastActivityTime(lifetime sessmodels.SessionLifetime, accessTokenPayload map[string]interface{}, now uint64) bool {
+	if _, ok := getTimeFromAccessTokenPayload(accessTokenPayload, signInTimePayloadKey); !ok {
+		// the session was created before the session lifetime feature was enabled
+		return true
+	}
+	if lifetime.IdleTimeoutMS == 0 {
+		return false
+	}
+	lastActivityTime, ok := getTimeFromAccessTokenPayload(accessTokenPayload, lastActivityTimePayloadKey)
+	if !ok {
+		return true
+	}
+	updateInterval := lastActivityUpdateIntervalMS
+	if lifetime.IdleTimeoutMS/2 < updateInterval {
+		updateInterval = lifetime.IdleTimeoutMS / 2
+	}
+	return now > lastActivityTime && now-lastActivityTime >= updateInterval
+}
+
+// enforceSessionLifetime revokes the session and returns an UnauthorizedError if the session is past its
+// idle timeout or absolute lifetime. Otherwise, it records activity on the session if updateLastActivity is true
+func enforceSessionLifetime(recipeImpl sessmodels.RecipeInterface, config sessmodels.TypeNormalisedInput, res http.ResponseWriter, session *SessionContainerInput, updateLastActivity bool, userContext supertokens.UserContext) error {
+	if !config.SessionLifetime.Enable {
+		return nil
+	}
+	lifetime, err := config.SessionLifetime.GetLifetimeForUser(session.userID, userContext)
+	if err != nil {
+		return err
+	}
+
+	now := getCurrTimeInMS()
+	accessTokenPayload := session.userDataInAccessToken
+
+	reason := getSessionLifetimeExpiredReason(lifetime, accessTokenPayload, now)
+	if reason != nil {
+		_, err := (*recipeImpl.RevokeSession)(session.sessionHandle, userContext)
+		if err != nil {
+			return err
+		}
+		clearSessionFromCookie(config, res)
+		return errors.UnauthorizedError{Msg: *reason}
+	}
+
+	if updateLastActivity && shouldUpdateLastActivityTime(lifetime, accessTokenPayload, now) {
+		newAccessTokenPayload := map[string]interface{}{}
+		for k, v := range accessTokenPayload {
+			newAccessTokenPayload[k] = v
+		}
+		if _, ok := getTimeFromAccessTokenPayload(newAccessTokenPayload, signInTimePayloadKey); !ok {
+			newAccessTokenPayload[signInTimePayloadKey] = now
+		}
+		newAccessTokenPayload[lastActivityTimePayloadKey] = now
+		return regenerateAccessTokenOfSession(config, session, newAccessTokenPayload, userContext)
+	}
+	return nil
+}
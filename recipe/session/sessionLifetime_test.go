@@ -0,0 +1,69 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func TestSessionLifetimeExpiredReason(t *testing.T) {
+	now := uint64(10000000)
+	accessTokenPayload := map[string]interface{}{
+		signInTimePayloadKey:       float64(now - 5000),
+		lastActivityTimePayloadKey: float64(now - 1000),
+	}
+
+	assert.Nil(t, getSessionLifetimeExpiredReason(sessmodels.SessionLifetime{}, accessTokenPayload, now))
+	assert.Nil(t, getSessionLifetimeExpiredReason(sessmodels.SessionLifetime{IdleTimeoutMS: 2000, AbsoluteLifetimeMS: 6000}, accessTokenPayload, now))
+	assert.NotNil(t, getSessionLifetimeExpiredReason(sessmodels.SessionLifetime{IdleTimeoutMS: 500}, accessTokenPayload, now))
+	assert.NotNil(t, getSessionLifetimeExpiredReason(sessmodels.SessionLifetime{AbsoluteLifetimeMS: 4000}, accessTokenPayload, now))
+
+	// sessions created before the feature was enabled are not expired
+	assert.Nil(t, getSessionLifetimeExpiredReason(sessmodels.SessionLifetime{IdleTimeoutMS: 1, AbsoluteLifetimeMS: 1}, map[string]interface{}{}, now))
+}
+
+func TestShouldUpdateLastActivityTime(t *testing.T) {
+	now := uint64(10000000)
+	accessTokenPayload := map[string]interface{}{
+		signInTimePayloadKey:       float64(now - 5000),
+		lastActivityTimePayloadKey: float64(now - 1000),
+	}
+
+	assert.False(t, shouldUpdateLastActivityTime(sessmodels.SessionLifetime{AbsoluteLifetimeMS: 6000}, accessTokenPayload, now))
+	assert.False(t, shouldUpdateLastActivityTime(sessmodels.SessionLifetime{IdleTimeoutMS: 15 * 60 * 1000}, accessTokenPayload, now))
+	assert.True(t, shouldUpdateLastActivityTime(sessmodels.SessionLifetime{IdleTimeoutMS: 2000}, accessTokenPayload, now))
+	assert.True(t, shouldUpdateLastActivityTime(sessmodels.SessionLifetime{AbsoluteLifetimeMS: 6000}, map[string]interface{}{}, now))
+}
+
+func TestUpdatingAccessTokenPayloadKeepsSessionLifetimeInfo(t *testing.T) {
+	existingAccessTokenPayload := addSessionLifetimeInfoToAccessTokenPayload(map[string]interface{}{
+		"role": "admin",
+	})
+	newAccessTokenPayload := copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload, map[string]interface{}{
+		"role": "user",
+	})
+	assert.Equal(t, "user", newAccessTokenPayload["role"])
+	assert.Equal(t, existingAccessTokenPayload[signInTimePayloadKey], newAccessTokenPayload[signInTimePayloadKey])
+	assert.Equal(t, existingAccessTokenPayload[lastActivityTimePayloadKey], newAccessTokenPayload[lastActivityTimePayloadKey])
+
+	newAccessTokenPayload = copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload, map[string]interface{}{
+		lastActivityTimePayloadKey: uint64(1),
+	})
+	assert.Equal(t, existingAccessTokenPayload[lastActivityTimePayloadKey], newAccessTokenPayload[lastActivityTimePayloadKey])
+}
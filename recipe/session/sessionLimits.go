@@ -0,0 +1,110 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"sort"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// enforceSessionLimit makes room for one more session for the user, either by revoking their oldest
+// sessions or by returning a SessionLimitReachedError, depending on config.SessionLimit.OnLimitReached
+func enforceSessionLimit(recipeImpl sessmodels.RecipeInterface, config sessmodels.TypeNormalisedInput, userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+	if !config.SessionLimit.Enable {
+		return nil
+	}
+	if _, ok := getImpersonatorUserID(accessTokenPayload); ok {
+		// impersonation sessions must not evict the sessions of the impersonated user
+		return nil
+	}
+	maxSessions, err := config.SessionLimit.GetMaxSessionsForUser(userID, userContext)
+	if err != nil {
+		return err
+	}
+	if maxSessions <= 0 {
+		return nil
+	}
+
+	sessionHandles, err := (*recipeImpl.GetAllSessionHandlesForUser)(userID, userContext)
+	if err != nil {
+		return err
+	}
+	if len(sessionHandles) < maxSessions {
+		return nil
+	}
+
+	if config.SessionLimit.OnLimitReached == sessionLimit_REJECT {
+		return errors.SessionLimitReachedError{
+			Msg:         "Maximum number of sessions reached for this user",
+			UserID:      userID,
+			MaxSessions: maxSessions,
+		}
+	}
+
+	sessions := []sessmodels.SessionInformation{}
+	for _, sessionHandle := range sessionHandles {
+		sessionInformation, err := (*recipeImpl.GetSessionInformation)(sessionHandle, userContext)
+		if err != nil {
+			if defaultErrors.As(err, &errors.UnauthorizedError{}) {
+				// the session was revoked or expired in the meantime
+				continue
+			}
+			return err
+		}
+		sessions = append(sessions, sessionInformation)
+	}
+
+	sessionHandlesToEvict := getSessionHandlesToEvict(sessions, maxSessions-1)
+	if len(sessionHandlesToEvict) == 0 {
+		return nil
+	}
+
+	revokedSessionHandles, err := (*recipeImpl.RevokeMultipleSessions)(sessionHandlesToEvict, userContext)
+	if err != nil {
+		return err
+	}
+
+	if config.SessionLimit.OnSessionsEvicted != nil && len(revokedSessionHandles) > 0 {
+		config.SessionLimit.OnSessionsEvicted(userID, revokedSessionHandles, userContext)
+	}
+	return nil
+}
+
+// getSessionHandlesToEvict returns the handles of the oldest sessions so that at most sessionsToKeep remain
+func getSessionHandlesToEvict(sessions []sessmodels.SessionInformation, sessionsToKeep int) []string {
+	if sessionsToKeep < 0 {
+		sessionsToKeep = 0
+	}
+	if len(sessions) <= sessionsToKeep {
+		return []string{}
+	}
+
+	sortedSessions := make([]sessmodels.SessionInformation, len(sessions))
+	copy(sortedSessions, sessions)
+	sort.SliceStable(sortedSessions, func(i, j int) bool {
+		return sortedSessions[i].TimeCreated < sortedSessions[j].TimeCreated
+	})
+
+	result := []string{}
+	for _, session := range sortedSessions[:len(sortedSessions)-sessionsToKeep] {
+		result = append(result, session.SessionHandle)
+	}
+	return result
+}
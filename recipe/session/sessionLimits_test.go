@@ -0,0 +1,163 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestGetSessionHandlesToEvictReturnsOldestSessions(t *testing.T) {
+	sessions := []sessmodels.SessionInformation{
+		{SessionHandle: "c", TimeCreated: 300},
+		{SessionHandle: "a", TimeCreated: 100},
+		{SessionHandle: "d", TimeCreated: 400},
+		{SessionHandle: "b", TimeCreated: 200},
+	}
+	assert.Equal(t, []string{"a", "b"}, getSessionHandlesToEvict(sessions, 2))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, getSessionHandlesToEvict(sessions, 0))
+	assert.Equal(t, []string{}, getSessionHandlesToEvict(sessions, 4))
+	assert.Equal(t, []string{}, getSessionHandlesToEvict(sessions, 10))
+	assert.Equal(t, "c", sessions[0].SessionHandle)
+}
+
+func TestSessionLimitConfigValidation(t *testing.T) {
+	_, err := validateAndNormaliseSessionLimitConfig(sessmodels.SessionLimitInputConfig{})
+	assert.Error(t, err)
+
+	onLimitReached := "RANDOM"
+	_, err = validateAndNormaliseSessionLimitConfig(sessmodels.SessionLimitInputConfig{
+		MaxSessionsPerUser: 1,
+		OnLimitReached:     &onLimitReached,
+	})
+	assert.Error(t, err)
+
+	config, err := validateAndNormaliseSessionLimitConfig(sessmodels.SessionLimitInputConfig{
+		MaxSessionsPerUser: 2,
+	})
+	assert.NoError(t, err)
+	assert.True(t, config.Enable)
+	assert.Equal(t, sessionLimit_REVOKE_OLDEST, config.OnLimitReached)
+	maxSessions, err := config.GetMaxSessionsForUser("userId", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, maxSessions)
+
+	onLimitReached = "REJECT"
+	config, err = validateAndNormaliseSessionLimitConfig(sessmodels.SessionLimitInputConfig{
+		GetMaxSessionsForUser: func(userID string, userContext supertokens.UserContext) (int, error) {
+			if userID == "seat" {
+				return 1, nil
+			}
+			return 0, nil
+		},
+		OnLimitReached: &onLimitReached,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sessionLimit_REJECT, config.OnLimitReached)
+	maxSessions, err = config.GetMaxSessionsForUser("seat", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, maxSessions)
+}
+
+func TestSessionLimitRevokesOldestSessionsOnCreate(t *testing.T) {
+	var evictedSessionHandles []string
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				SessionLimit: &sessmodels.SessionLimitInputConfig{
+					MaxSessionsPerUser: 2,
+					OnSessionsEvicted: func(userID string, sessionHandles []string, userContext supertokens.UserContext) {
+						evictedSessionHandles = append(evictedSessionHandles, sessionHandles...)
+					},
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	oldestSession, err := CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	_, err = CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	newestSession, err := CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+
+	sessionHandles, err := GetAllSessionHandlesForUser("user")
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 2)
+	assert.NotContains(t, sessionHandles, oldestSession.GetHandle())
+	assert.Contains(t, sessionHandles, newestSession.GetHandle())
+	assert.Equal(t, []string{oldestSession.GetHandle()}, evictedSessionHandles)
+}
+
+func TestSessionLimitRejectsNewSessions(t *testing.T) {
+	onLimitReached := "REJECT"
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				SessionLimit: &sessmodels.SessionLimitInputConfig{
+					MaxSessionsPerUser: 1,
+					OnLimitReached:     &onLimitReached,
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.NoError(t, err)
+	_, err = CreateNewSession(httptest.NewRecorder(), "user", map[string]interface{}{}, map[string]interface{}{})
+	assert.True(t, defaultErrors.As(err, &errors.SessionLimitReachedError{}))
+
+	sessionHandles, err := GetAllSessionHandlesForUser("user")
+	assert.NoError(t, err)
+	assert.Len(t, sessionHandles, 1)
+}
@@ -0,0 +1,187 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	defaultErrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func makeSessionEndedEvent(reason string) sessmodels.SessionEndedEvent {
+	closeCode := WebSocketCloseCodeUnauthorised
+	if reason == sessionEndReason_ACCESS_TOKEN_EXPIRED {
+		closeCode = WebSocketCloseCodeTryRefreshToken
+	}
+	return sessmodels.SessionEndedEvent{
+		Reason:             reason,
+		WebSocketCloseCode: closeCode,
+	}
+}
+
+func startSessionMonitor(ctx context.Context, recipeInstance Recipe, session sessmodels.SessionContainer, options *sessmodels.SessionMonitorOptions, userContext supertokens.UserContext) (sessmodels.SessionMonitor, error) {
+	recheckIntervalMS := defaultSessionMonitorRecheckIntervalMS
+	endOnAccessTokenExpiry := false
+	if options != nil {
+		if options.RecheckIntervalMS != nil {
+			if *options.RecheckIntervalMS == 0 {
+				return sessmodels.SessionMonitor{}, defaultErrors.New("recheckIntervalMS must be greater than 0")
+			}
+			recheckIntervalMS = *options.RecheckIntervalMS
+		}
+		endOnAccessTokenExpiry = options.EndOnAccessTokenExpiry
+	}
+
+	sessionExpiry, err := session.GetExpiryWithContext(userContext)
+	if err != nil {
+		return sessmodels.SessionMonitor{}, err
+	}
+
+	payload, err := getPayloadWithoutVerifying(session.GetAccessTokenWithContext(userContext))
+	if err != nil {
+		return sessmodels.SessionMonitor{}, err
+	}
+	accessTokenExpiryFloat, ok := payload["expiryTime"].(float64)
+	if !ok {
+		return sessmodels.SessionMonitor{}, defaultErrors.New("access token does not contain an expiry time")
+	}
+	accessTokenExpiry := uint64(accessTokenExpiryFloat)
+
+	var accessTokenDeadline *uint64 = nil
+	if endOnAccessTokenExpiry {
+		accessTokenDeadline = &accessTokenExpiry
+	}
+
+	sessionHandle := session.GetHandleWithContext(userContext)
+	getSessionInformation := func() (sessmodels.SessionInformation, error) {
+		return (*recipeInstance.RecipeImpl.GetSessionInformation)(sessionHandle, userContext)
+	}
+
+	ended := make(chan sessmodels.SessionEndedEvent, 1)
+	go monitorSession(ctx, getSessionInformation, sessionExpiry, accessTokenDeadline, time.Duration(recheckIntervalMS)*time.Millisecond, ended)
+
+	return sessmodels.SessionMonitor{
+		Session:           session,
+		SessionExpiry:     sessionExpiry,
+		AccessTokenExpiry: accessTokenExpiry,
+		Ended:             ended,
+	}, nil
+}
+
+// getSessionForMonitoring verifies the session of a request that opens a WebSocket or Server-Sent-Events connection.
+// These are GET requests and browsers cannot add headers to WebSocket upgrade requests, so whatever the anti-csrf
+// mode of the recipe is, the Origin (or Referer) header is checked against AntiCsrfAllowedOrigins instead.
+func getSessionForMonitoring(recipeInstance Recipe, req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	sessionRequired := true
+	antiCsrfCheck := true
+	antiCsrf := antiCSRF_VIA_ORIGIN
+	session, err := (*recipeInstance.RecipeImpl.GetSession)(req, res, &sessmodels.VerifySessionOptions{
+		SessionRequired: &sessionRequired,
+		AntiCsrfCheck:   &antiCsrfCheck,
+		AntiCsrf:        &antiCsrf,
+	}, userContext)
+	if err != nil {
+		return sessmodels.SessionContainer{}, err
+	}
+	if session == nil {
+		return sessmodels.SessionContainer{}, errors.UnauthorizedError{Msg: "Session does not exist. Are you sending the session tokens in the request as cookies?"}
+	}
+	return *session, nil
+}
+
+// monitorSession sends at most one event to ended and closes it. The session is checked for revocation
+// every recheckInterval, and its expiry is updated from the core since refreshing the session extends it.
+func monitorSession(ctx context.Context, getSessionInformation func() (sessmodels.SessionInformation, error), sessionExpiry uint64, accessTokenExpiry *uint64, recheckInterval time.Duration, ended chan<- sessmodels.SessionEndedEvent) {
+	defer close(ended)
+
+	ticker := time.NewTicker(recheckInterval)
+	defer ticker.Stop()
+
+	// returns true if the session has ended
+	recheck := func() bool {
+		sessionInformation, err := getSessionInformation()
+		if err != nil {
+			if defaultErrors.As(err, &errors.UnauthorizedError{}) {
+				ended <- makeSessionEndedEvent(sessionEndReason_SESSION_REVOKED)
+				return true
+			}
+			// we try again in the next interval if the core could not be queried
+			return false
+		}
+		sessionExpiry = sessionInformation.Expiry
+		return false
+	}
+
+	for {
+		deadline := sessionExpiry
+		reason := sessionEndReason_SESSION_EXPIRED
+		if accessTokenExpiry != nil && *accessTokenExpiry < deadline {
+			deadline = *accessTokenExpiry
+			reason = sessionEndReason_ACCESS_TOKEN_EXPIRED
+		}
+
+		now := getCurrTimeInMS()
+		if now >= deadline {
+			if reason == sessionEndReason_SESSION_EXPIRED {
+				// the session may have been refreshed since it was last checked
+				sessionInformation, err := getSessionInformation()
+				if err == nil && sessionInformation.Expiry > now {
+					sessionExpiry = sessionInformation.Expiry
+					continue
+				}
+			}
+			ended <- makeSessionEndedEvent(reason)
+			return
+		}
+
+		timer := time.NewTimer(time.Duration(deadline-now) * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-ticker.C:
+			timer.Stop()
+			if recheck() {
+				return
+			}
+		}
+	}
+}
+
+func sendSessionEndedSSEEvent(res http.ResponseWriter, event sessmodels.SessionEndedEvent) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"reason": event.Reason,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", sessionEndedSSEEventName, data)
+	if err != nil {
+		return err
+	}
+	if flusher, ok := res.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSessionMonitorEndsWhenSessionIsRevoked(t *testing.T) {
+	revoked := make(chan struct{})
+	getSessionInformation := func() (sessmodels.SessionInformation, error) {
+		select {
+		case <-revoked:
+			return sessmodels.SessionInformation{}, errors.UnauthorizedError{Msg: "session revoked"}
+		default:
+			return sessmodels.SessionInformation{Expiry: getCurrTimeInMS() + 60000}, nil
+		}
+	}
+	ended := make(chan sessmodels.SessionEndedEvent, 1)
+	go monitorSession(context.Background(), getSessionInformation, getCurrTimeInMS()+60000, nil, 10*time.Millisecond, ended)
+
+	time.Sleep(30 * time.Millisecond)
+	close(revoked)
+
+	select {
+	case event := <-ended:
+		assert.Equal(t, sessionEndReason_SESSION_REVOKED, event.Reason)
+		assert.Equal(t, WebSocketCloseCodeUnauthorised, event.WebSocketCloseCode)
+	case <-time.After(time.Second):
+		t.Fatal("session monitor did not end")
+	}
+	_, ok := <-ended
+	assert.False(t, ok)
+}
+
+func TestSessionMonitorEndsWhenAccessTokenExpires(t *testing.T) {
+	getSessionInformation := func() (sessmodels.SessionInformation, error) {
+		return sessmodels.SessionInformation{Expiry: getCurrTimeInMS() + 60000}, nil
+	}
+	accessTokenExpiry := getCurrTimeInMS() + 20
+	ended := make(chan sessmodels.SessionEndedEvent, 1)
+	go monitorSession(context.Background(), getSessionInformation, getCurrTimeInMS()+60000, &accessTokenExpiry, time.Minute, ended)
+
+	select {
+	case event := <-ended:
+		assert.Equal(t, sessionEndReason_ACCESS_TOKEN_EXPIRED, event.Reason)
+		assert.Equal(t, WebSocketCloseCodeTryRefreshToken, event.WebSocketCloseCode)
+	case <-time.After(time.Second):
+		t.Fatal("session monitor did not end")
+	}
+}
+
+func TestSessionMonitorFollowsRefreshedSessionExpiry(t *testing.T) {
+	calls := 0
+	getSessionInformation := func() (sessmodels.SessionInformation, error) {
+		calls++
+		if calls == 1 {
+			return sessmodels.SessionInformation{Expiry: getCurrTimeInMS() + 20}, nil
+		}
+		return sessmodels.SessionInformation{}, errors.UnauthorizedError{Msg: "session expired"}
+	}
+	ended := make(chan sessmodels.SessionEndedEvent, 1)
+	go monitorSession(context.Background(), getSessionInformation, getCurrTimeInMS()+20, nil, time.Minute, ended)
+
+	select {
+	case event := <-ended:
+		assert.Equal(t, sessionEndReason_SESSION_EXPIRED, event.Reason)
+		assert.Equal(t, 2, calls)
+	case <-time.After(time.Second):
+		t.Fatal("session monitor did not end")
+	}
+}
+
+func TestSessionMonitorStopsWhenContextIsDone(t *testing.T) {
+	getSessionInformation := func() (sessmodels.SessionInformation, error) {
+		return sessmodels.SessionInformation{Expiry: getCurrTimeInMS() + 60000}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ended := make(chan sessmodels.SessionEndedEvent, 1)
+	go monitorSession(ctx, getSessionInformation, getCurrTimeInMS()+60000, nil, time.Minute, ended)
+	cancel()
+
+	select {
+	case _, ok := <-ended:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("session monitor did not stop")
+	}
+}
+
+func TestSendSessionEndedSSEEvent(t *testing.T) {
+	res := httptest.NewRecorder()
+	err := sendSessionEndedSSEEvent(res, makeSessionEndedEvent(sessionEndReason_SESSION_REVOKED))
+	assert.NoError(t, err)
+	assert.Equal(t, "event: sessionEnded\ndata: {\"reason\":\"SESSION_REVOKED\"}\n\n", res.Body.String())
+	assert.True(t, res.Flushed)
+}
+
+func TestMonitorSessionOfRequestChecksOrigin(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSession(rw, "user", map[string]interface{}{}, map[string]interface{}{})
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+	mux.HandleFunc("/events", func(rw http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		monitor, err := MonitorSessionOfRequest(ctx, r, rw, nil)
+		if err != nil {
+			err = supertokens.ErrorHandler(err, r, rw)
+			if err != nil {
+				t.Error(err.Error())
+			}
+			return
+		}
+		assert.Equal(t, "user", monitor.Session.GetUserID())
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	openConnection := func(origin string) int {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/events", nil)
+		assert.NoError(t, err)
+		req.Header.Add("Cookie", "sAccessToken="+cookieData["sAccessToken"]+";"+"sIdRefreshToken="+cookieData["sIdRefreshToken"])
+		if origin != "" {
+			req.Header.Add("Origin", origin)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode
+	}
+
+	assert.Equal(t, 200, openConnection("https://supertokens.io"))
+	assert.Equal(t, 401, openConnection("https://attacker.com"))
+	assert.Equal(t, 401, openConnection(""))
+}
@@ -0,0 +1,158 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type sessionTransferToken struct {
+	ID           string `json:"id"`
+	UserID       string `json:"sub"`
+	TargetOrigin string `json:"aud"`
+	Expiry       uint64 `json:"exp"`
+}
+
+func validateAndNormaliseSessionTransferConfig(config sessmodels.SessionTransferInputConfig, websiteOrigin string) (sessmodels.SessionTransferNormalisedConfig, error) {
+	if len(config.SigningKey) < minSessionTransferSigningKeyLength {
+		return sessmodels.SessionTransferNormalisedConfig{}, errors.New("sessionTransfer signingKey must be at least 32 bytes long")
+	}
+	allowedTargetOrigins := []string{}
+	for _, origin := range config.AllowedTargetOrigins {
+		normalisedOrigin, err := normaliseOrigin(origin)
+		if err != nil {
+			return sessmodels.SessionTransferNormalisedConfig{}, errors.New("sessionTransfer allowedTargetOrigins contains an invalid origin: " + origin)
+		}
+		if !containsString(allowedTargetOrigins, normalisedOrigin) {
+			allowedTargetOrigins = append(allowedTargetOrigins, normalisedOrigin)
+		}
+	}
+	tokenValidityMS := defaultSessionTransferTokenValidityMS
+	if config.TokenValidityMS != nil {
+		tokenValidityMS = *config.TokenValidityMS
+		if tokenValidityMS == 0 || tokenValidityMS > maxSessionTransferTokenValidityMS {
+			return sessmodels.SessionTransferNormalisedConfig{}, errors.New("sessionTransfer tokenValidityMS must be more than 0 and not more than 300000")
+		}
+	}
+	markTokenAsUsed := config.MarkTokenAsUsed
+	if markTokenAsUsed == nil {
+		markTokenAsUsed = newUsedSessionTransferTokens().markAsUsed
+	}
+	return sessmodels.SessionTransferNormalisedConfig{
+		Enable:               true,
+		SigningKey:           config.SigningKey,
+		AllowedTargetOrigins: allowedTargetOrigins,
+		TokenValidityMS:      tokenValidityMS,
+		MarkTokenAsUsed:      markTokenAsUsed,
+		Origin:               websiteOrigin,
+	}, nil
+}
+
+// usedSessionTransferTokens is the default replay protection for transfer tokens. A token is remembered until it
+// expires, after which it is rejected anyway.
+type usedSessionTransferTokens struct {
+	lock   sync.Mutex
+	tokens map[string]uint64
+}
+
+func newUsedSessionTransferTokens() *usedSessionTransferTokens {
+	return &usedSessionTransferTokens{
+		tokens: map[string]uint64{},
+	}
+}
+
+func (u *usedSessionTransferTokens) markAsUsed(tokenID string, expiry uint64, userContext supertokens.UserContext) (bool, error) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	currentTime := getCurrTimeInMS()
+	for id, tokenExpiry := range u.tokens {
+		if tokenExpiry < currentTime {
+			delete(u.tokens, id)
+		}
+	}
+	if _, ok := u.tokens[tokenID]; ok {
+		return false, nil
+	}
+	u.tokens[tokenID] = expiry
+	return true, nil
+}
+
+func signSessionTransferToken(signingKey []byte, payload string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// createSessionTransferToken returns nil if sessions cannot be transferred to the target origin
+func createSessionTransferToken(config sessmodels.TypeNormalisedInput, userID string, targetOrigin string) (*string, error) {
+	normalisedTargetOrigin, err := normaliseOrigin(targetOrigin)
+	if err != nil || !containsString(config.SessionTransfer.AllowedTargetOrigins, normalisedTargetOrigin) {
+		return nil, nil
+	}
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	payloadBytes, err := json.Marshal(sessionTransferToken{
+		ID:           hex.EncodeToString(id),
+		UserID:       userID,
+		TargetOrigin: normalisedTargetOrigin,
+		Expiry:       getCurrTimeInMS() + config.SessionTransfer.TokenValidityMS,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	transferToken := payload + "." + signSessionTransferToken(config.SessionTransfer.SigningKey, payload)
+	return &transferToken, nil
+}
+
+// verifySessionTransferToken returns nil if the token is not signed with the signing key, has expired or was
+// created for another app. It does not check if the token was already used.
+func verifySessionTransferToken(config sessmodels.TypeNormalisedInput, transferToken string, now uint64) *sessionTransferToken {
+	parts := strings.Split(transferToken, ".")
+	if len(parts) != 2 {
+		return nil
+	}
+	if !hmac.Equal([]byte(parts[1]), []byte(signSessionTransferToken(config.SessionTransfer.SigningKey, parts[0]))) {
+		return nil
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil
+	}
+	var token sessionTransferToken
+	err = json.Unmarshal(payloadBytes, &token)
+	if err != nil || token.ID == "" || token.UserID == "" {
+		return nil
+	}
+	if token.Expiry < now || token.TargetOrigin != config.SessionTransfer.Origin {
+		return nil
+	}
+	return &token
+}
@@ -0,0 +1,186 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+var sessionTransferSigningKeyForTest = []byte("0123456789abcdef0123456789abcdef")
+
+func getSessionTransferConfigsForTest(t *testing.T) (sessmodels.TypeNormalisedInput, sessmodels.TypeNormalisedInput) {
+	sourceConfig, err := getNormalisedConfigForCookieTests(t, "https://app.example.com", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{
+			SigningKey:           sessionTransferSigningKeyForTest,
+			AllowedTargetOrigins: []string{"https://ADMIN.example.org:443/"},
+		},
+	})
+	assert.NoError(t, err)
+	targetConfig, err := getNormalisedConfigForCookieTests(t, "https://admin.example.org", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{
+			SigningKey: sessionTransferSigningKeyForTest,
+		},
+	})
+	assert.NoError(t, err)
+	return sourceConfig, targetConfig
+}
+
+func TestSessionTransferConfig(t *testing.T) {
+	sourceConfig, targetConfig := getSessionTransferConfigsForTest(t)
+	assert.True(t, sourceConfig.SessionTransfer.Enable)
+	assert.Equal(t, []string{"https://admin.example.org"}, sourceConfig.SessionTransfer.AllowedTargetOrigins)
+	assert.Equal(t, defaultSessionTransferTokenValidityMS, sourceConfig.SessionTransfer.TokenValidityMS)
+	assert.Equal(t, "https://admin.example.org", targetConfig.SessionTransfer.Origin)
+
+	config, err := getNormalisedConfigForCookieTests(t, "https://app.example.com", nil)
+	assert.NoError(t, err)
+	assert.False(t, config.SessionTransfer.Enable)
+
+	_, err = getNormalisedConfigForCookieTests(t, "https://app.example.com", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{SigningKey: []byte("short")},
+	})
+	assert.EqualError(t, err, "sessionTransfer signingKey must be at least 32 bytes long")
+
+	_, err = getNormalisedConfigForCookieTests(t, "https://app.example.com", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{
+			SigningKey:           sessionTransferSigningKeyForTest,
+			AllowedTargetOrigins: []string{"admin.example.org"},
+		},
+	})
+	assert.EqualError(t, err, "sessionTransfer allowedTargetOrigins contains an invalid origin: admin.example.org")
+
+	tokenValidityMS := uint64(600000)
+	_, err = getNormalisedConfigForCookieTests(t, "https://app.example.com", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{
+			SigningKey:      sessionTransferSigningKeyForTest,
+			TokenValidityMS: &tokenValidityMS,
+		},
+	})
+	assert.EqualError(t, err, "sessionTransfer tokenValidityMS must be more than 0 and not more than 300000")
+}
+
+func TestSessionTransferToken(t *testing.T) {
+	sourceConfig, targetConfig := getSessionTransferConfigsForTest(t)
+
+	transferToken, err := createSessionTransferToken(sourceConfig, "userId", "https://admin.example.org")
+	assert.NoError(t, err)
+	assert.NotNil(t, transferToken)
+
+	now := getCurrTimeInMS()
+	token := verifySessionTransferToken(targetConfig, *transferToken, now)
+	assert.NotNil(t, token)
+	assert.Equal(t, "userId", token.UserID)
+	assert.Equal(t, "https://admin.example.org", token.TargetOrigin)
+	assert.NotEmpty(t, token.ID)
+
+	// the token is only valid for the target app and until it expires
+	assert.Nil(t, verifySessionTransferToken(sourceConfig, *transferToken, now))
+	assert.Nil(t, verifySessionTransferToken(targetConfig, *transferToken, token.Expiry+1))
+}
+
+func TestSessionTransferTokenToOriginNotAllowed(t *testing.T) {
+	sourceConfig, _ := getSessionTransferConfigsForTest(t)
+
+	transferToken, err := createSessionTransferToken(sourceConfig, "userId", "https://attacker.example.net")
+	assert.NoError(t, err)
+	assert.Nil(t, transferToken)
+
+	transferToken, err = createSessionTransferToken(sourceConfig, "userId", "not an origin")
+	assert.NoError(t, err)
+	assert.Nil(t, transferToken)
+}
+
+func TestTamperedSessionTransferToken(t *testing.T) {
+	sourceConfig, targetConfig := getSessionTransferConfigsForTest(t)
+
+	transferToken, err := createSessionTransferToken(sourceConfig, "userId", "https://admin.example.org")
+	assert.NoError(t, err)
+	parts := strings.Split(*transferToken, ".")
+
+	forgedPayload := strings.TrimRight(parts[0], "A") + "B"
+	assert.Nil(t, verifySessionTransferToken(targetConfig, forgedPayload+"."+parts[1], getCurrTimeInMS()))
+	assert.Nil(t, verifySessionTransferToken(targetConfig, parts[0], getCurrTimeInMS()))
+	assert.Nil(t, verifySessionTransferToken(targetConfig, "", getCurrTimeInMS()))
+
+	otherConfig, err := getNormalisedConfigForCookieTests(t, "https://admin.example.org", &sessmodels.TypeInput{
+		SessionTransfer: &sessmodels.SessionTransferInputConfig{
+			SigningKey: []byte("another signing key that is 32 bytes long"),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, verifySessionTransferToken(otherConfig, *transferToken, getCurrTimeInMS()))
+}
+
+func TestUsedSessionTransferTokens(t *testing.T) {
+	usedTokens := newUsedSessionTransferTokens()
+	expiry := getCurrTimeInMS() + 1000
+
+	unused, err := usedTokens.markAsUsed("tokenId", expiry, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, unused)
+
+	unused, err = usedTokens.markAsUsed("tokenId", expiry, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, unused)
+
+	unused, err = usedTokens.markAsUsed("otherTokenId", expiry, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, unused)
+
+	// expired tokens are forgotten
+	_, err = usedTokens.markAsUsed("expiredTokenId", getCurrTimeInMS()-1, &map[string]interface{}{})
+	assert.NoError(t, err)
+	_, err = usedTokens.markAsUsed("anotherTokenId", expiry, &map[string]interface{}{})
+	assert.NoError(t, err)
+	_, ok := usedTokens.tokens["expiredTokenId"]
+	assert.False(t, ok)
+}
+
+func TestSessionTransferExchangeIsOnlyAcceptedFromTheTargetOrigin(t *testing.T) {
+	_, targetConfig := getSessionTransferConfigsForTest(t)
+	exchangedTokens := []string{}
+	exchangeSessionTransferToken := func(res http.ResponseWriter, transferToken string, userContext supertokens.UserContext) (sessmodels.ExchangeSessionTransferTokenResponse, error) {
+		exchangedTokens = append(exchangedTokens, transferToken)
+		return sessmodels.ExchangeSessionTransferTokenResponse{InvalidTransferTokenError: &struct{}{}}, nil
+	}
+	apiImplementation := api.MakeAPIImplementation()
+
+	for _, origin := range []string{"", "https://evil.example.net", "https://admin.example.org"} {
+		req := httptest.NewRequest(http.MethodPost, "/auth/session/transfer/exchange", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		options := sessmodels.APIOptions{
+			RecipeImplementation: sessmodels.RecipeInterface{ExchangeSessionTransferToken: &exchangeSessionTransferToken},
+			Config:               targetConfig,
+			Req:                  req,
+			Res:                  httptest.NewRecorder(),
+		}
+		// the origin is used as the transfer token to see which requests reach the recipe implementation
+		response, err := (*apiImplementation.SessionTransferExchangePOST)(origin, options, &map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.NotNil(t, response.InvalidTransferTokenError)
+	}
+	assert.Equal(t, []string{"https://admin.example.org"}, exchangedTokens)
+}
@@ -27,8 +27,11 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
+// MakeRecipeImplementation adds a JWT to the access token payload of the sessions. buildAccessTokenPayload runs the
+// BuildAccessTokenPayload hook of the session recipe for a new session, since the JWT is created from its result.
 func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
-	openidRecipeImplementation openidmodels.RecipeInterface, config sessmodels.TypeNormalisedInput) sessmodels.RecipeInterface {
+	openidRecipeImplementation openidmodels.RecipeInterface, config sessmodels.TypeNormalisedInput,
+	buildAccessTokenPayload func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error)) sessmodels.RecipeInterface {
 
 	// Time difference between JWT expiry and access token expiry (JWT expiry = access token expiry + EXPIRY_OFFSET_SECONDS)
 	var EXPIRY_OFFSET_SECONDS uint64 = 30
@@ -46,7 +49,15 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 			}
 			accessTokenValidityInSeconds = uint64(math.Ceil(float64(accessTokenValidityInSeconds) / 1000))
 
-			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, userID, config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
+			if config.BuildAccessTokenPayload != nil {
+				// The JWT must contain the built payload, so the hook runs here instead of in CreateNewSession
+				accessTokenPayload, err = buildAccessTokenPayload(userID, accessTokenPayload, userContext)
+				if err != nil {
+					return sessmodels.SessionContainer{}, err
+				}
+			}
+
+			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, userID, config.Jwt.PropertyNameInAccessTokenPayload, config.Encryption.AccessTokenPayloadKeys, openidRecipeImplementation, userContext)
 
 			if err != nil {
 				return sessmodels.SessionContainer{}, err
@@ -58,7 +69,7 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 				return sessionContainer, err
 			}
 
-			return newSessionWithJWTContainer(sessionContainer, openidRecipeImplementation), nil
+			return newSessionWithJWTContainer(sessionContainer, openidRecipeImplementation, config), nil
 		}
 	}
 
@@ -76,7 +87,7 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 				return nil, nil
 			}
 
-			result := newSessionWithJWTContainer(*sessionContainer, openidRecipeImplementation)
+			result := newSessionWithJWTContainer(*sessionContainer, openidRecipeImplementation, config)
 
 			return &result, nil
 		}
@@ -99,7 +110,7 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 			}
 			accessTokenPayload := newSession.GetAccessTokenPayloadWithContext(userContext)
 
-			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, newSession.GetUserIDWithContext(userContext), config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
+			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, newSession.GetUserIDWithContext(userContext), config.Jwt.PropertyNameInAccessTokenPayload, config.Encryption.AccessTokenPayloadKeys, openidRecipeImplementation, userContext)
 
 			if err != nil {
 				return sessmodels.SessionContainer{}, err
@@ -110,7 +121,7 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 				return sessmodels.SessionContainer{}, err
 			}
 
-			return newSessionWithJWTContainer(newSession, openidRecipeImplementation), nil
+			return newSessionWithJWTContainer(newSession, openidRecipeImplementation, config), nil
 		}
 	}
 
@@ -156,7 +167,7 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 				jwtExpiry = 1
 			}
 
-			newAccessTokenPayload, err = addJWTToAccessTokenPayload(newAccessTokenPayload, jwtExpiry, sessionInformation.UserId, jwtPropertyName.(string), openidRecipeImplementation, userContext)
+			newAccessTokenPayload, err = addJWTToAccessTokenPayload(newAccessTokenPayload, jwtExpiry, sessionInformation.UserId, jwtPropertyName.(string), config.Encryption.AccessTokenPayloadKeys, openidRecipeImplementation, userContext)
 			if err != nil {
 				return err
 			}
@@ -168,7 +179,8 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 	return originalImplementation
 }
 
-func addJWTToAccessTokenPayload(accessTokenPayload map[string]interface{}, jwtExpiry uint64, userId string, jwtPropertyName string, openidRecipeImplementation openidmodels.RecipeInterface, userContext supertokens.UserContext) (map[string]interface{}, error) {
+// keysNotInJWT are the keys of the access token payload that are not copied into the JWT, like the ones that are encrypted
+func addJWTToAccessTokenPayload(accessTokenPayload map[string]interface{}, jwtExpiry uint64, userId string, jwtPropertyName string, keysNotInJWT []string, openidRecipeImplementation openidmodels.RecipeInterface, userContext supertokens.UserContext) (map[string]interface{}, error) {
 
 	// If jwtPropertyName is not undefined it means that the JWT was added to the access token payload already
 	existingJwtPropertyName, ok := accessTokenPayload[ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY]
@@ -182,6 +194,9 @@ func addJWTToAccessTokenPayload(accessTokenPayload map[string]interface{}, jwtEx
 		"sub": userId,
 	}
 	for k, v := range accessTokenPayload {
+		if isKeyNotInJWT(k, keysNotInJWT) {
+			continue
+		}
 		payloadInJWT[k] = v
 	}
 
@@ -200,3 +215,12 @@ func addJWTToAccessTokenPayload(accessTokenPayload map[string]interface{}, jwtEx
 
 	return accessTokenPayload, nil
 }
+
+func isKeyNotInJWT(key string, keysNotInJWT []string) bool {
+	for _, keyNotInJWT := range keysNotInJWT {
+		if key == keyNotInJWT {
+			return true
+		}
+	}
+	return false
+}
@@ -24,7 +24,7 @@ import (
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func newSessionWithJWTContainer(originalSessionClass sessmodels.SessionContainer, openidRecipeImplementation openidmodels.RecipeInterface) sessmodels.SessionContainer {
+func newSessionWithJWTContainer(originalSessionClass sessmodels.SessionContainer, openidRecipeImplementation openidmodels.RecipeInterface, config sessmodels.TypeNormalisedInput) sessmodels.SessionContainer {
 
 	updateAccessTokenPayloadWithContext := func(newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
 		if newAccessTokenPayload == nil {
@@ -61,7 +61,7 @@ func newSessionWithJWTContainer(originalSessionClass sessmodels.SessionContainer
 			jwtExpiry = 1
 		}
 
-		newAccessTokenPayload, err = addJWTToAccessTokenPayload(newAccessTokenPayload, jwtExpiry, originalSessionClass.GetUserIDWithContext(userContext), jwtPropertyName.(string), openidRecipeImplementation, userContext)
+		newAccessTokenPayload, err = addJWTToAccessTokenPayload(newAccessTokenPayload, jwtExpiry, originalSessionClass.GetUserIDWithContext(userContext), jwtPropertyName.(string), config.Encryption.AccessTokenPayloadKeys, openidRecipeImplementation, userContext)
 		if err != nil {
 			return err
 		}
@@ -78,6 +78,8 @@ func newSessionWithJWTContainer(originalSessionClass sessmodels.SessionContainer
 		GetAccessTokenWithContext:           originalSessionClass.GetAccessTokenWithContext,
 		GetTimeCreatedWithContext:           originalSessionClass.GetTimeCreatedWithContext,
 		GetExpiryWithContext:                originalSessionClass.GetExpiryWithContext,
+		IsImpersonatedWithContext:           originalSessionClass.IsImpersonatedWithContext,
+		IsGuestWithContext:                  originalSessionClass.IsGuestWithContext,
 		RevokeSession:                       originalSessionClass.RevokeSession,
 		GetSessionData:                      originalSessionClass.GetSessionData,
 		UpdateSessionData:                   originalSessionClass.UpdateSessionData,
@@ -87,6 +89,8 @@ func newSessionWithJWTContainer(originalSessionClass sessmodels.SessionContainer
 		GetAccessToken:                      originalSessionClass.GetAccessToken,
 		GetTimeCreated:                      originalSessionClass.GetTimeCreated,
 		GetExpiry:                           originalSessionClass.GetExpiry,
+		IsImpersonated:                      originalSessionClass.IsImpersonated,
+		IsGuest:                             originalSessionClass.IsGuest,
 		UpdateAccessTokenPayloadWithContext: updateAccessTokenPayloadWithContext,
 		UpdateAccessTokenPayload: func(newAccessTokenPayload map[string]interface{}) error {
 			return updateAccessTokenPayloadWithContext(newAccessTokenPayload, &map[string]interface{}{})
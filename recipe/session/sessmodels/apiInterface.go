@@ -19,10 +19,41 @@ import "github.com/supertokens/supertokens-golang/supertokens"
 
 type APIInterface struct {
 	RefreshPOST   *func(options APIOptions, userContext supertokens.UserContext) error
+	RefreshGET    *func(redirectToPath string, options APIOptions, userContext supertokens.UserContext) error
 	SignOutPOST   *func(options APIOptions, userContext supertokens.UserContext) (SignOutPOSTResponse, error)
 	VerifySession *func(verifySessionOptions *VerifySessionOptions, options APIOptions, userContext supertokens.UserContext) (*SessionContainer, error)
+
+	SessionTransferPOST         *func(targetOrigin string, options APIOptions, userContext supertokens.UserContext) (SessionTransferPOSTResponse, error)
+	SessionTransferExchangePOST *func(transferToken string, options APIOptions, userContext supertokens.UserContext) (SessionTransferExchangePOSTResponse, error)
+
+	AccountDeletionPOST *func(reauthentication map[string]interface{}, options APIOptions, userContext supertokens.UserContext) (AccountDeletionPOSTResponse, error)
 }
 
 type SignOutPOSTResponse struct {
 	OK *struct{}
 }
+
+type SessionTransferPOSTResponse struct {
+	OK *struct {
+		TransferToken string
+	}
+	TargetOriginNotAllowedError *struct{}
+	// impersonation and guest sessions cannot be transferred
+	SessionNotTransferableError *struct{}
+}
+
+type SessionTransferExchangePOSTResponse struct {
+	OK *struct {
+		Session SessionContainer
+	}
+	InvalidTransferTokenError *struct{}
+}
+
+type AccountDeletionPOSTResponse struct {
+	OK *struct {
+		DeletionTime uint64
+	}
+	ReauthenticationFailedError *struct{}
+	// impersonation and guest sessions cannot be used to delete an account
+	AccountDeletionNotAllowedError *struct{}
+}
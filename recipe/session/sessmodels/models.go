@@ -91,10 +91,44 @@ type TypeInput struct {
 	CookieSameSite           *string
 	SessionExpiredStatusCode *int
 	CookieDomain             *string
-	AntiCsrf                 *string
-	Override                 *OverrideStruct
-	ErrorHandlers            *ErrorHandlers
-	Jwt                      *JWTInputConfig
+	CookieDomains            []string
+	CookieNamePrefix         *string
+	LegacyCookieNamePrefixes []string
+	// LegacyCookieDomains are the domains the cookies with a legacy name prefix were set with, so that they can be
+	// cleared. Legacy cookies without a domain are always cleared
+	LegacyCookieDomains []string
+	// LegacyAccessTokenCookiePath and LegacyRefreshTokenCookiePath are the paths the cookies with a legacy name
+	// prefix were set with. They default to "/" and the refresh API path
+	LegacyAccessTokenCookiePath  *string
+	LegacyRefreshTokenCookiePath *string
+	CookiePartitioned            *bool
+	AccessTokenCookiePath        *string
+	RefreshTokenCookiePath       *string
+	AntiCsrf                     *string
+	AntiCsrfAllowedOrigins       []string
+	// RefreshTokenReuseGraceWindowMS is the time for which the response of a refresh is replayed to other requests
+	// using the same refresh token. 0 (default) disables it
+	RefreshTokenReuseGraceWindowMS *uint64
+	// BuildAccessTokenPayload computes the access token payload whenever a session is created or refreshed. The
+	// current payload does not contain the keys added by the SDK.
+	BuildAccessTokenPayload func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error)
+	// MaxAccessTokenPayloadSizeBytes is the maximum size of the JSON encoded access token payload. 2048 keeps the
+	// access token within the cookie size limit of browsers. It defaults to 0, which disables the check
+	MaxAccessTokenPayloadSizeBytes *uint64
+	Override                       *OverrideStruct
+	ErrorHandlers                  *ErrorHandlers
+	Jwt                            *JWTInputConfig
+	SessionLimit                   *SessionLimitInputConfig
+	SessionLifetime                *SessionLifetimeInputConfig
+	Impersonation                  *ImpersonationInputConfig
+	GuestSession                   *GuestSessionInputConfig
+	TokenTheftPolicy               *TokenTheftPolicyInputConfig
+	PageRefresh                    *PageRefreshInputConfig
+	Encryption                     *EncryptionInputConfig
+	Schema                         *SchemaInputConfig
+	RememberMe                     *RememberMeInputConfig
+	SessionTransfer                *SessionTransferInputConfig
+	AccountDeletion                *AccountDeletionInputConfig
 }
 
 type JWTInputConfig struct {
@@ -103,6 +137,161 @@ type JWTInputConfig struct {
 	PropertyNameInAccessTokenPayload *string
 }
 
+type SessionLimitInputConfig struct {
+	// MaxSessionsPerUser is used for every user unless GetMaxSessionsForUser is provided.
+	MaxSessionsPerUser int
+	// GetMaxSessionsForUser resolves the limit per user. A value <= 0 means no limit for that user.
+	GetMaxSessionsForUser func(userID string, userContext supertokens.UserContext) (int, error)
+	// OnLimitReached is one of "REVOKE_OLDEST" (default) or "REJECT"
+	OnLimitReached    *string
+	OnSessionsEvicted func(userID string, sessionHandles []string, userContext supertokens.UserContext)
+}
+
+type SessionLifetimeInputConfig struct {
+	// IdleTimeoutMS and AbsoluteLifetimeMS are used for every user unless GetLifetimeForUser is provided. 0 means no limit.
+	IdleTimeoutMS      uint64
+	AbsoluteLifetimeMS uint64
+	GetLifetimeForUser func(userID string, userContext supertokens.UserContext) (SessionLifetime, error)
+}
+
+type SessionLifetime struct {
+	IdleTimeoutMS      uint64
+	AbsoluteLifetimeMS uint64
+}
+
+type ImpersonationInputConfig struct {
+	// MaxLifetimeMS defaults to 1 hour
+	MaxLifetimeMS        *uint64
+	OnImpersonationStart func(event ImpersonationEvent, userContext supertokens.UserContext)
+	// reason is one of "REVOKED" or "EXPIRED"
+	OnImpersonationEnd func(event ImpersonationEvent, reason string, userContext supertokens.UserContext)
+}
+
+type ImpersonationEvent struct {
+	SessionHandle      string
+	UserID             string
+	ImpersonatorUserID string
+}
+
+type GuestSessionInputConfig struct {
+	// MergeGuestSession is called when a user signs in on a request that has a guest session. By default,
+	// the guest's access token payload and session data are copied into the new session unless the new session sets the same keys.
+	MergeGuestSession func(input GuestSessionMergeInput, userContext supertokens.UserContext) (accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, err error)
+}
+
+type GuestSessionMergeInput struct {
+	GuestUserID             string
+	GuestAccessTokenPayload map[string]interface{}
+	GuestSessionData        map[string]interface{}
+	UserID                  string
+	AccessTokenPayload      map[string]interface{}
+	SessionData             map[string]interface{}
+}
+
+type TokenTheftPolicyInputConfig struct {
+	// Actions are run in order when token theft is detected by RefreshSession, in addition to the session being
+	// revoked by the default ErrorHandlers.OnTokenTheftDetected
+	Actions []TokenTheftAction
+	// OnActionError is called with the error of each action that fails. The other actions still run and the token
+	// theft detected response is still sent
+	OnActionError func(err error, event TokenTheftEvent, userContext supertokens.UserContext)
+}
+
+type TokenTheftAction func(event TokenTheftEvent, userContext supertokens.UserContext) error
+
+type TokenTheftEvent struct {
+	SessionHandle string
+	UserID        string
+	// IPAddress is the remote address of the connection. ForwardedFor is the X-Forwarded-For header of the request,
+	// which is set by proxies but can also be set by the client
+	IPAddress    string
+	ForwardedFor string
+	UserAgent    string
+	TimeDetected uint64
+}
+
+// PageRefreshInputConfig enables redirect based session refreshing for page requests of server side rendered apps.
+// Instead of a try refresh token response, page requests are redirected to the refresh API, which refreshes the
+// session and redirects back to the page, or to the login page if the session can not be refreshed.
+type PageRefreshInputConfig struct {
+	// LoginURL defaults to websiteDomain + websiteBasePath. The page to go back to is added as the redirectToPath query param
+	LoginURL *string
+}
+
+type EncryptionInputConfig struct {
+	// Keys is the key ring. The first key is used to encrypt, and all of them are used to decrypt, so that a new key
+	// can be added in front of the old ones to rotate them.
+	Keys []EncryptionKey
+	// EncryptSessionData encrypts the session data with a new data key per update, which is encrypted with the key ring
+	EncryptSessionData bool
+	// AccessTokenPayloadKeys are the keys of the access token payload whose values are encrypted. They are not added to the JWT
+	AccessTokenPayloadKeys []string
+}
+
+type EncryptionKey struct {
+	ID string
+	// Key must be 16, 24 or 32 bytes long to use AES-128, AES-192 or AES-256
+	Key []byte
+}
+
+// SchemaInputConfig validates the access token payload and session data before they are written to the core. The
+// access token payload does not contain the keys added by the SDK.
+type SchemaInputConfig struct {
+	ValidateAccessTokenPayload func(accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error
+	ValidateSessionData        func(sessionData map[string]interface{}, userContext supertokens.UserContext) error
+}
+
+type RememberMeInputConfig struct {
+	// MaxLifetimeMS limits how long a session that was created without remember me is valid for. 0 (default)
+	// means that it is valid for as long as other sessions
+	MaxLifetimeMS uint64
+}
+
+// SessionTransferInputConfig enables moving a session to an app on another site using single use transfer tokens
+type SessionTransferInputConfig struct {
+	// SigningKey signs the transfer tokens. It must be the same in every app that creates or accepts transfer
+	// tokens, and be at least 32 bytes long
+	SigningKey []byte
+	// AllowedTargetOrigins are the website origins of the apps that sessions can be transferred to
+	AllowedTargetOrigins []string
+	// TokenValidityMS defaults to 30 seconds, and cannot be more than 5 minutes
+	TokenValidityMS *uint64
+	// MarkTokenAsUsed must return false if the token was already used. By default, used tokens are remembered in
+	// memory, which only prevents replays if the app accepting transfer tokens runs in a single process
+	MarkTokenAsUsed func(tokenID string, expiry uint64, userContext supertokens.UserContext) (bool, error)
+}
+
+// AccountDeletionInputConfig lets users delete their own account. The account is deleted once the grace period is
+// over, unless the user signs in again before that
+type AccountDeletionInputConfig struct {
+	// GracePeriodMS defaults to 30 days
+	GracePeriodMS *uint64
+	// VerifyReauthentication checks the credentials sent with the deletion request. See
+	// emailpassword.VerifyPasswordForReauthentication and passwordless.VerifyCodeForReauthentication
+	VerifyReauthentication ReauthenticationVerifier
+
+	// storage for the users whose account is pending deletion, along with the time at which it will be deleted
+	SetPendingAccountDeletion    func(userID string, deletionTime uint64, userContext supertokens.UserContext) error
+	GetPendingAccountDeletion    func(userID string, userContext supertokens.UserContext) (*uint64, error)
+	RemovePendingAccountDeletion func(userID string, userContext supertokens.UserContext) error
+	// GetDueAccountDeletions returns the users whose deletion time is not after now
+	GetDueAccountDeletions func(now uint64, userContext supertokens.UserContext) ([]string, error)
+
+	OnAccountDeletionScheduled func(userID string, deletionTime uint64, userContext supertokens.UserContext)
+	OnAccountDeletionCancelled func(userID string, userContext supertokens.UserContext)
+	// OnBeforeAccountDeletion is where the app deletes its own data for the user. The user is not deleted if it
+	// returns an error, and is tried again on the next purge
+	OnBeforeAccountDeletion func(userID string, userContext supertokens.UserContext) error
+}
+
+// ReauthenticationVerifier returns true if the credentials in reauthentication belong to the user
+type ReauthenticationVerifier func(userID string, reauthentication map[string]interface{}, userContext supertokens.UserContext) (bool, error)
+
+// Validator can be implemented by the structs passed to PatchAccessTokenPayload and PatchSessionData
+type Validator interface {
+	Validate() error
+}
+
 type OverrideStruct struct {
 	Functions     func(originalImplementation RecipeInterface) RecipeInterface
 	APIs          func(originalImplementation APIInterface) APIInterface
@@ -110,20 +299,47 @@ type OverrideStruct struct {
 }
 
 type ErrorHandlers struct {
-	OnUnauthorised       func(message string, req *http.Request, res http.ResponseWriter) error
-	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
+	OnUnauthorised        func(message string, req *http.Request, res http.ResponseWriter) error
+	OnTokenTheftDetected  func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
+	OnSessionLimitReached func(userID string, req *http.Request, res http.ResponseWriter) error
+	// OnInvalidSessionPayload is called for a SchemaValidationError or an AccessTokenPayloadTooLargeError
+	OnInvalidSessionPayload func(message string, req *http.Request, res http.ResponseWriter) error
 }
 
 type TypeNormalisedInput struct {
-	RefreshTokenPath         supertokens.NormalisedURLPath
-	CookieDomain             *string
-	CookieSameSite           string
-	CookieSecure             bool
-	SessionExpiredStatusCode int
-	AntiCsrf                 string
-	Override                 OverrideStruct
-	ErrorHandlers            NormalisedErrorHandlers
-	Jwt                      JWTNormalisedConfig
+	RefreshTokenPath               supertokens.NormalisedURLPath
+	CookieDomain                   *string
+	CookieDomains                  []string
+	CookieNamePrefix               string
+	LegacyCookieNamePrefixes       []string
+	LegacyCookieDomains            []string
+	LegacyAccessTokenCookiePath    string
+	LegacyRefreshTokenCookiePath   string
+	CookiePartitioned              bool
+	AccessTokenCookiePath          string
+	RefreshTokenCookiePath         string
+	CookieSameSite                 string
+	CookieSecure                   bool
+	SessionExpiredStatusCode       int
+	AntiCsrf                       string
+	AntiCsrfAllowedOrigins         []string
+	RefreshTokenReuseGraceWindowMS uint64
+	BuildAccessTokenPayload        func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error)
+	MaxAccessTokenPayloadSizeBytes uint64
+	Override                       OverrideStruct
+	ErrorHandlers                  NormalisedErrorHandlers
+	Jwt                            JWTNormalisedConfig
+	SessionLimit                   SessionLimitNormalisedConfig
+	SessionLifetime                SessionLifetimeNormalisedConfig
+	Impersonation                  ImpersonationNormalisedConfig
+	GuestSession                   GuestSessionNormalisedConfig
+	TokenTheftPolicy               TokenTheftPolicyNormalisedConfig
+	PageRefresh                    PageRefreshNormalisedConfig
+	Encryption                     EncryptionNormalisedConfig
+	Schema                         SchemaNormalisedConfig
+	RememberMe                     RememberMeNormalisedConfig
+	SessionTransfer                SessionTransferNormalisedConfig
+	AccountDeletion                AccountDeletionNormalisedConfig
 }
 
 type JWTNormalisedConfig struct {
@@ -132,8 +348,85 @@ type JWTNormalisedConfig struct {
 	PropertyNameInAccessTokenPayload string
 }
 
+type SessionLimitNormalisedConfig struct {
+	Enable                bool
+	GetMaxSessionsForUser func(userID string, userContext supertokens.UserContext) (int, error)
+	OnLimitReached        string
+	OnSessionsEvicted     func(userID string, sessionHandles []string, userContext supertokens.UserContext)
+}
+
+type SessionLifetimeNormalisedConfig struct {
+	Enable             bool
+	GetLifetimeForUser func(userID string, userContext supertokens.UserContext) (SessionLifetime, error)
+}
+
+type ImpersonationNormalisedConfig struct {
+	Enable               bool
+	MaxLifetimeMS        uint64
+	OnImpersonationStart func(event ImpersonationEvent, userContext supertokens.UserContext)
+	OnImpersonationEnd   func(event ImpersonationEvent, reason string, userContext supertokens.UserContext)
+}
+
+type GuestSessionNormalisedConfig struct {
+	Enable            bool
+	MergeGuestSession func(input GuestSessionMergeInput, userContext supertokens.UserContext) (accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, err error)
+}
+
+type TokenTheftPolicyNormalisedConfig struct {
+	Enable        bool
+	Actions       []TokenTheftAction
+	OnActionError func(err error, event TokenTheftEvent, userContext supertokens.UserContext)
+}
+
+type PageRefreshNormalisedConfig struct {
+	Enable   bool
+	LoginURL string
+}
+
+type EncryptionNormalisedConfig struct {
+	Enable                 bool
+	Keys                   []EncryptionKey
+	EncryptSessionData     bool
+	AccessTokenPayloadKeys []string
+}
+
+type SchemaNormalisedConfig struct {
+	ValidateAccessTokenPayload func(accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error
+	ValidateSessionData        func(sessionData map[string]interface{}, userContext supertokens.UserContext) error
+}
+
+type RememberMeNormalisedConfig struct {
+	Enable        bool
+	MaxLifetimeMS uint64
+}
+
+type SessionTransferNormalisedConfig struct {
+	Enable               bool
+	SigningKey           []byte
+	AllowedTargetOrigins []string
+	TokenValidityMS      uint64
+	MarkTokenAsUsed      func(tokenID string, expiry uint64, userContext supertokens.UserContext) (bool, error)
+	// Origin is the website origin of this app, which transfer tokens must be created for to be accepted here
+	Origin string
+}
+
+type AccountDeletionNormalisedConfig struct {
+	Enable                       bool
+	GracePeriodMS                uint64
+	VerifyReauthentication       ReauthenticationVerifier
+	SetPendingAccountDeletion    func(userID string, deletionTime uint64, userContext supertokens.UserContext) error
+	GetPendingAccountDeletion    func(userID string, userContext supertokens.UserContext) (*uint64, error)
+	RemovePendingAccountDeletion func(userID string, userContext supertokens.UserContext) error
+	GetDueAccountDeletions       func(now uint64, userContext supertokens.UserContext) ([]string, error)
+	OnAccountDeletionScheduled   func(userID string, deletionTime uint64, userContext supertokens.UserContext)
+	OnAccountDeletionCancelled   func(userID string, userContext supertokens.UserContext)
+	OnBeforeAccountDeletion      func(userID string, userContext supertokens.UserContext) error
+}
+
 type VerifySessionOptions struct {
-	AntiCsrfCheck   *bool
+	AntiCsrfCheck *bool
+	// AntiCsrf overrides the anti-csrf mode from the recipe config for this route
+	AntiCsrf        *string
 	SessionRequired *bool
 }
 
@@ -147,9 +440,12 @@ type APIOptions struct {
 }
 
 type NormalisedErrorHandlers struct {
-	OnUnauthorised       func(message string, req *http.Request, res http.ResponseWriter) error
-	OnTryRefreshToken    func(message string, req *http.Request, res http.ResponseWriter) error
-	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
+	OnUnauthorised        func(message string, req *http.Request, res http.ResponseWriter) error
+	OnTryRefreshToken     func(message string, req *http.Request, res http.ResponseWriter) error
+	OnTokenTheftDetected  func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
+	OnSessionLimitReached func(userID string, req *http.Request, res http.ResponseWriter) error
+	// OnInvalidSessionPayload is called for a SchemaValidationError or an AccessTokenPayloadTooLargeError
+	OnInvalidSessionPayload func(message string, req *http.Request, res http.ResponseWriter) error
 }
 
 type SessionContainer struct {
@@ -163,6 +459,8 @@ type SessionContainer struct {
 	UpdateAccessTokenPayload func(newAccessTokenPayload map[string]interface{}) error
 	GetTimeCreated           func() (uint64, error)
 	GetExpiry                func() (uint64, error)
+	IsImpersonated           func() bool
+	IsGuest                  func() bool
 
 	RevokeSessionWithContext            func(userContext supertokens.UserContext) error
 	GetSessionDataWithContext           func(userContext supertokens.UserContext) (map[string]interface{}, error)
@@ -174,6 +472,32 @@ type SessionContainer struct {
 	UpdateAccessTokenPayloadWithContext func(newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error
 	GetTimeCreatedWithContext           func(userContext supertokens.UserContext) (uint64, error)
 	GetExpiryWithContext                func(userContext supertokens.UserContext) (uint64, error)
+	IsImpersonatedWithContext           func(userContext supertokens.UserContext) bool
+	IsGuestWithContext                  func(userContext supertokens.UserContext) bool
+}
+
+type SessionMonitorOptions struct {
+	// RecheckIntervalMS is how often the session is checked for revocation. Defaults to 1 minute
+	RecheckIntervalMS *uint64
+	// EndOnAccessTokenExpiry ends the connection when the access token used to open it expires, so that the
+	// frontend refreshes the session before reconnecting
+	EndOnAccessTokenExpiry bool
+}
+
+type SessionMonitor struct {
+	// Session is the monitored session
+	Session           SessionContainer
+	SessionExpiry     uint64
+	AccessTokenExpiry uint64
+	// Ended receives one event once the session ends, and is closed after that or when the monitoring context is done
+	Ended <-chan SessionEndedEvent
+}
+
+type SessionEndedEvent struct {
+	// Reason is one of "ACCESS_TOKEN_EXPIRED", "SESSION_EXPIRED" or "SESSION_REVOKED"
+	Reason string
+	// WebSocketCloseCode is the close code to send when closing a WebSocket connection for this event
+	WebSocketCloseCode int
 }
 
 type SessionInformation struct {
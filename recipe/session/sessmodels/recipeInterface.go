@@ -22,17 +22,50 @@ import (
 )
 
 type RecipeInterface struct {
-	CreateNewSession            *func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (SessionContainer, error)
-	GetSession                  *func(req *http.Request, res http.ResponseWriter, options *VerifySessionOptions, userContext supertokens.UserContext) (*SessionContainer, error)
-	RefreshSession              *func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (SessionContainer, error)
-	GetSessionInformation       *func(sessionHandle string, userContext supertokens.UserContext) (SessionInformation, error)
-	RevokeAllSessionsForUser    *func(userID string, userContext supertokens.UserContext) ([]string, error)
-	GetAllSessionHandlesForUser *func(userID string, userContext supertokens.UserContext) ([]string, error)
-	RevokeSession               *func(sessionHandle string, userContext supertokens.UserContext) (bool, error)
-	RevokeMultipleSessions      *func(sessionHandles []string, userContext supertokens.UserContext) ([]string, error)
-	UpdateSessionData           *func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) error
-	UpdateAccessTokenPayload    *func(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error
-	GetAccessTokenLifeTimeMS    *func(userContext supertokens.UserContext) (uint64, error)
-	GetRefreshTokenLifeTimeMS   *func(userContext supertokens.UserContext) (uint64, error)
-	RegenerateAccessToken       *func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (RegenerateAccessTokenResponse, error)
+	CreateNewSession              *func(res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (SessionContainer, error)
+	GetSession                    *func(req *http.Request, res http.ResponseWriter, options *VerifySessionOptions, userContext supertokens.UserContext) (*SessionContainer, error)
+	RefreshSession                *func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (SessionContainer, error)
+	GetSessionInformation         *func(sessionHandle string, userContext supertokens.UserContext) (SessionInformation, error)
+	RevokeAllSessionsForUser      *func(userID string, userContext supertokens.UserContext) ([]string, error)
+	GetAllSessionHandlesForUser   *func(userID string, userContext supertokens.UserContext) ([]string, error)
+	RevokeSession                 *func(sessionHandle string, userContext supertokens.UserContext) (bool, error)
+	RevokeMultipleSessions        *func(sessionHandles []string, userContext supertokens.UserContext) ([]string, error)
+	UpdateSessionData             *func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) error
+	UpdateAccessTokenPayload      *func(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error
+	GetAccessTokenLifeTimeMS      *func(userContext supertokens.UserContext) (uint64, error)
+	GetRefreshTokenLifeTimeMS     *func(userContext supertokens.UserContext) (uint64, error)
+	CreateNewImpersonationSession *func(res http.ResponseWriter, targetUserID string, impersonatorUserID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (SessionContainer, error)
+	CreateNewGuestSession         *func(res http.ResponseWriter, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (SessionContainer, error)
+	RegenerateAccessToken         *func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (RegenerateAccessTokenResponse, error)
+	CreateSessionTransferToken    *func(userID string, targetOrigin string, userContext supertokens.UserContext) (CreateSessionTransferTokenResponse, error)
+	ExchangeSessionTransferToken  *func(res http.ResponseWriter, transferToken string, userContext supertokens.UserContext) (ExchangeSessionTransferTokenResponse, error)
+	ScheduleAccountDeletion       *func(userID string, userContext supertokens.UserContext) (ScheduleAccountDeletionResponse, error)
+	CancelAccountDeletion         *func(userID string, userContext supertokens.UserContext) (CancelAccountDeletionResponse, error)
+}
+
+type CreateSessionTransferTokenResponse struct {
+	OK *struct {
+		TransferToken string
+	}
+	TargetOriginNotAllowedError *struct{}
+}
+
+type ExchangeSessionTransferTokenResponse struct {
+	OK *struct {
+		Session SessionContainer
+	}
+	InvalidTransferTokenError *struct{}
+}
+
+type ScheduleAccountDeletionResponse struct {
+	OK *struct {
+		DeletionTime uint64
+	}
+}
+
+type CancelAccountDeletionResponse struct {
+	OK *struct {
+		// false if the account was not pending deletion
+		Cancelled bool
+	}
 }
@@ -0,0 +1,78 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// RevokeSessionOnTokenTheft revokes the session for which token theft was detected
+func RevokeSessionOnTokenTheft() sessmodels.TokenTheftAction {
+	return func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		_, err := RevokeSessionWithContext(event.SessionHandle, userContext)
+		return err
+	}
+}
+
+// RevokeAllSessionsOnTokenTheft revokes all the sessions of the user for whom token theft was detected
+func RevokeAllSessionsOnTokenTheft() sessmodels.TokenTheftAction {
+	return func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		_, err := RevokeAllSessionsForUserWithContext(event.UserID, userContext)
+		return err
+	}
+}
+
+// EmitEventOnTokenTheft calls onTokenTheft with the details of the incident, for example to log it or send it to a SIEM
+func EmitEventOnTokenTheft(onTokenTheft func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext)) sessmodels.TokenTheftAction {
+	return func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		onTokenTheft(event, userContext)
+		return nil
+	}
+}
+
+func makeTokenTheftEvent(sessionHandle string, userID string, req *http.Request) sessmodels.TokenTheftEvent {
+	event := sessmodels.TokenTheftEvent{
+		SessionHandle: sessionHandle,
+		UserID:        userID,
+		TimeDetected:  getCurrTimeInMS(),
+	}
+	if req == nil {
+		return event
+	}
+	event.IPAddress = req.RemoteAddr
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err == nil {
+		event.IPAddress = host
+	}
+	event.ForwardedFor = req.Header.Get("X-Forwarded-For")
+	event.UserAgent = req.Header.Get("User-Agent")
+	return event
+}
+
+// runTokenTheftPolicy runs all the actions even if one of them fails, so that for example a failure in
+// notifying the user does not stop the sessions from being revoked. Failures are reported to OnActionError.
+func runTokenTheftPolicy(config sessmodels.TokenTheftPolicyNormalisedConfig, event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+	for _, action := range config.Actions {
+		err := action(event, userContext)
+		if err != nil && config.OnActionError != nil {
+			config.OnActionError(err, event, userContext)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestTokenTheftEventHasRequestContext(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil)
+	req.RemoteAddr = "10.0.0.1:52341"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	event := makeTokenTheftEvent("sessionHandle", "userID", req)
+	assert.Equal(t, "sessionHandle", event.SessionHandle)
+	assert.Equal(t, "userID", event.UserID)
+	assert.Equal(t, "10.0.0.1", event.IPAddress)
+	assert.Equal(t, "203.0.113.7, 10.0.0.2", event.ForwardedFor)
+	assert.Equal(t, "Mozilla/5.0", event.UserAgent)
+	assert.NotZero(t, event.TimeDetected)
+}
+
+func TestTokenTheftPolicyRunsAllActions(t *testing.T) {
+	calls := []string{}
+	failingAction := func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+		calls = append(calls, "failing")
+		return errors.New("could not revoke")
+	}
+	var emittedEvent *sessmodels.TokenTheftEvent
+	var actionErrors []error
+	config := sessmodels.TokenTheftPolicyNormalisedConfig{
+		Enable: true,
+		Actions: []sessmodels.TokenTheftAction{
+			failingAction,
+			EmitEventOnTokenTheft(func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+				calls = append(calls, "emit")
+				emittedEvent = &event
+			}),
+		},
+		OnActionError: func(err error, event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+			actionErrors = append(actionErrors, err)
+		},
+	}
+
+	event := sessmodels.TokenTheftEvent{SessionHandle: "sessionHandle", UserID: "userID"}
+	runTokenTheftPolicy(config, event, &map[string]interface{}{})
+	assert.Equal(t, []string{"failing", "emit"}, calls)
+	assert.Equal(t, 1, len(actionErrors))
+	assert.EqualError(t, actionErrors[0], "could not revoke")
+	assert.Equal(t, event, *emittedEvent)
+}
+
+func TestTokenTheftPolicyWithoutActions(t *testing.T) {
+	_, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		TokenTheftPolicy: &sessmodels.TokenTheftPolicyInputConfig{},
+	})
+	assert.EqualError(t, err, "tokenTheftPolicy must have at least one action")
+
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		TokenTheftPolicy: &sessmodels.TokenTheftPolicyInputConfig{
+			Actions: []sessmodels.TokenTheftAction{RevokeAllSessionsOnTokenTheft()},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, config.TokenTheftPolicy.Enable)
+	assert.Equal(t, 1, len(config.TokenTheftPolicy.Actions))
+}
+
+func TestTokenTheftPolicyRevokesSessionAndSendsResponse(t *testing.T) {
+	var emittedEvents []sessmodels.TokenTheftEvent
+	var actionErrors []error
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				TokenTheftPolicy: &sessmodels.TokenTheftPolicyInputConfig{
+					Actions: []sessmodels.TokenTheftAction{
+						func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) error {
+							return errors.New("could not notify the user")
+						},
+						EmitEventOnTokenTheft(func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+							emittedEvents = append(emittedEvents, event)
+						}),
+					},
+					OnActionError: func(err error, event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+						actionErrors = append(actionErrors, err)
+					},
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		_, err := CreateNewSession(rw, "user", map[string]interface{}{}, map[string]interface{}{})
+		if err != nil {
+			t.Error(err.Error())
+		}
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	res, err := http.Post(testServer.URL+"/create", "application/json", nil)
+	assert.NoError(t, err)
+	cookieData := unittesting.ExtractInfoFromResponse(res)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, cookieData["sRefreshToken"], cookieData["sIdRefreshToken"], cookieData["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, cookieData["sRefreshToken"], cookieData["sIdRefreshToken"], cookieData["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, 401, res.StatusCode)
+	var jsonResponse map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&jsonResponse)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "token theft detected", jsonResponse["message"])
+
+	assert.Equal(t, 1, len(emittedEvents))
+	assert.Equal(t, "user", emittedEvents[0].UserID)
+	assert.Equal(t, 1, len(actionErrors))
+
+	sessionHandles, err := GetAllSessionHandlesForUser("user")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(sessionHandles))
+}
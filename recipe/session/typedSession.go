@@ -0,0 +1,167 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	defaultErrors "errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// GetAccessTokenPayloadIntoWithContext decodes the access token payload into out, which must be a pointer to a struct
+// (or map), using the json tags of its fields
+func GetAccessTokenPayloadIntoWithContext(session sessmodels.SessionContainer, out interface{}, userContext supertokens.UserContext) error {
+	return decodeInto(session.GetAccessTokenPayloadWithContext(userContext), out)
+}
+
+// GetSessionDataIntoWithContext decodes the session data into out, which must be a pointer to a struct (or map),
+// using the json tags of its fields
+func GetSessionDataIntoWithContext(session sessmodels.SessionContainer, out interface{}, userContext supertokens.UserContext) error {
+	sessionData, err := session.GetSessionDataWithContext(userContext)
+	if err != nil {
+		return err
+	}
+	return decodeInto(sessionData, out)
+}
+
+// PatchAccessTokenPayloadWithContext merges patch into the access token payload as a JSON merge patch (RFC 7396):
+// keys in the patch replace the existing ones, nested objects are merged, null values remove keys and keys that
+// are not in the patch (for example because of omitempty) are left as they are. If patch implements
+// sessmodels.Validator, it is validated first.
+func PatchAccessTokenPayloadWithContext(session sessmodels.SessionContainer, patch interface{}, userContext supertokens.UserContext) error {
+	patchAsMap, err := getPatchAsMap(patch)
+	if err != nil {
+		return err
+	}
+	for key := range patchAsMap {
+		if containsString(reservedAccessTokenPayloadKeys, key) {
+			return errors.SchemaValidationError{Msg: "the access token payload key '" + key + "' is used by the SDK and cannot be patched"}
+		}
+	}
+	newAccessTokenPayload := mergePatch(session.GetAccessTokenPayloadWithContext(userContext), patchAsMap)
+	return session.UpdateAccessTokenPayloadWithContext(newAccessTokenPayload, userContext)
+}
+
+// PatchSessionDataWithContext merges patch into the session data like PatchAccessTokenPayloadWithContext does for
+// the access token payload
+func PatchSessionDataWithContext(session sessmodels.SessionContainer, patch interface{}, userContext supertokens.UserContext) error {
+	patchAsMap, err := getPatchAsMap(patch)
+	if err != nil {
+		return err
+	}
+	sessionData, err := session.GetSessionDataWithContext(userContext)
+	if err != nil {
+		return err
+	}
+	return session.UpdateSessionDataWithContext(mergePatch(sessionData, patchAsMap), userContext)
+}
+
+func GetAccessTokenPayloadInto(session sessmodels.SessionContainer, out interface{}) error {
+	return GetAccessTokenPayloadIntoWithContext(session, out, &map[string]interface{}{})
+}
+
+func GetSessionDataInto(session sessmodels.SessionContainer, out interface{}) error {
+	return GetSessionDataIntoWithContext(session, out, &map[string]interface{}{})
+}
+
+func PatchAccessTokenPayload(session sessmodels.SessionContainer, patch interface{}) error {
+	return PatchAccessTokenPayloadWithContext(session, patch, &map[string]interface{}{})
+}
+
+func PatchSessionData(session sessmodels.SessionContainer, patch interface{}) error {
+	return PatchSessionDataWithContext(session, patch, &map[string]interface{}{})
+}
+
+func decodeInto(input map[string]interface{}, out interface{}) error {
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(inputJSON, out)
+}
+
+func getPatchAsMap(patch interface{}) (map[string]interface{}, error) {
+	if validator, ok := patch.(sessmodels.Validator); ok {
+		err := validator.Validate()
+		if err != nil {
+			return nil, errors.SchemaValidationError{Msg: err.Error()}
+		}
+	}
+	patchJSON, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal(patchJSON, &result)
+	if err != nil || result == nil {
+		return nil, defaultErrors.New("patch must be a struct or map that is encoded as a JSON object")
+	}
+	return result, nil
+}
+
+func mergePatch(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range target {
+		result[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		if patchValue, ok := v.(map[string]interface{}); ok {
+			targetValue, ok := result[k].(map[string]interface{})
+			if !ok {
+				targetValue = map[string]interface{}{}
+			}
+			result[k] = mergePatch(targetValue, patchValue)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func validateAccessTokenPayloadWithSchema(config sessmodels.TypeNormalisedInput, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+	if config.Schema.ValidateAccessTokenPayload == nil {
+		return nil
+	}
+	err := config.Schema.ValidateAccessTokenPayload(removeSDKKeysFromAccessTokenPayload(accessTokenPayload), userContext)
+	if err != nil {
+		return errors.SchemaValidationError{Msg: "invalid access token payload: " + err.Error()}
+	}
+	return nil
+}
+
+func validateSessionDataWithSchema(config sessmodels.TypeNormalisedInput, sessionData map[string]interface{}, userContext supertokens.UserContext) error {
+	if config.Schema.ValidateSessionData == nil {
+		return nil
+	}
+	if sessionData == nil {
+		sessionData = map[string]interface{}{}
+	}
+	err := config.Schema.ValidateSessionData(sessionData, userContext)
+	if err != nil {
+		return errors.SchemaValidationError{Msg: "invalid session data: " + err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+type testAccessTokenPayload struct {
+	Roles       []string          `json:"roles,omitempty"`
+	Level       int               `json:"level,omitempty"`
+	Preferences map[string]string `json:"preferences,omitempty"`
+	Nickname    *string           `json:"nickname,omitempty"`
+}
+
+func (p testAccessTokenPayload) Validate() error {
+	if p.Level < 0 {
+		return defaultErrors.New("level must not be negative")
+	}
+	return nil
+}
+
+type testSessionDataPatch struct {
+	CartItems []string    `json:"cartItems"`
+	Coupon    interface{} `json:"coupon"`
+}
+
+func makeInMemorySessionContainer(accessTokenPayload map[string]interface{}, sessionData map[string]interface{}) *sessmodels.SessionContainer {
+	return &sessmodels.SessionContainer{
+		GetAccessTokenPayloadWithContext: func(userContext supertokens.UserContext) map[string]interface{} {
+			return accessTokenPayload
+		},
+		UpdateAccessTokenPayloadWithContext: func(newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+			accessTokenPayload = newAccessTokenPayload
+			return nil
+		},
+		GetSessionDataWithContext: func(userContext supertokens.UserContext) (map[string]interface{}, error) {
+			return sessionData, nil
+		},
+		UpdateSessionDataWithContext: func(newSessionData map[string]interface{}, userContext supertokens.UserContext) error {
+			sessionData = newSessionData
+			return nil
+		},
+	}
+}
+
+func TestGetAccessTokenPayloadInto(t *testing.T) {
+	session := makeInMemorySessionContainer(map[string]interface{}{
+		"roles":       []interface{}{"admin", "editor"},
+		"level":       float64(3),
+		"preferences": map[string]interface{}{"theme": "dark"},
+		"_signInTime": float64(1000),
+	}, nil)
+
+	var payload testAccessTokenPayload
+	err := GetAccessTokenPayloadInto(*session, &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "editor"}, payload.Roles)
+	assert.Equal(t, 3, payload.Level)
+	assert.Equal(t, "dark", payload.Preferences["theme"])
+
+	session = makeInMemorySessionContainer(map[string]interface{}{"level": "high"}, nil)
+	err = GetAccessTokenPayloadInto(*session, &payload)
+	assert.Error(t, err)
+}
+
+func TestPatchAccessTokenPayload(t *testing.T) {
+	session := makeInMemorySessionContainer(map[string]interface{}{
+		"roles":       []interface{}{"admin"},
+		"level":       float64(3),
+		"preferences": map[string]interface{}{"theme": "dark", "language": "en"},
+		"tenant":      "public",
+	}, nil)
+
+	err := PatchAccessTokenPayload(*session, testAccessTokenPayload{
+		Roles:       []string{"editor"},
+		Preferences: map[string]string{"language": "de"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"roles":       []interface{}{"editor"},
+		"level":       float64(3),
+		"preferences": map[string]interface{}{"theme": "dark", "language": "de"},
+		"tenant":      "public",
+	}, session.GetAccessTokenPayloadWithContext(&map[string]interface{}{}))
+
+	err = PatchAccessTokenPayload(*session, map[string]interface{}{"tenant": nil})
+	assert.NoError(t, err)
+	_, ok := session.GetAccessTokenPayloadWithContext(&map[string]interface{}{})["tenant"]
+	assert.False(t, ok)
+
+	err = PatchAccessTokenPayload(*session, testAccessTokenPayload{Level: -1})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+	assert.Equal(t, float64(3), session.GetAccessTokenPayloadWithContext(&map[string]interface{}{})["level"])
+
+	err = PatchAccessTokenPayload(*session, map[string]interface{}{"_impersonatedBy": "attacker"})
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+
+	err = PatchAccessTokenPayload(*session, []string{"not", "an", "object"})
+	assert.Error(t, err)
+}
+
+func TestPatchSessionData(t *testing.T) {
+	session := makeInMemorySessionContainer(nil, map[string]interface{}{"cartItems": []interface{}{"item1"}, "coupon": "SAVE10", "visits": float64(2)})
+
+	err := PatchSessionData(*session, testSessionDataPatch{CartItems: []string{"item1", "item2"}, Coupon: nil})
+	assert.NoError(t, err)
+
+	var sessionData struct {
+		CartItems []string `json:"cartItems"`
+		Coupon    *string  `json:"coupon"`
+		Visits    int      `json:"visits"`
+	}
+	err = GetSessionDataInto(*session, &sessionData)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"item1", "item2"}, sessionData.CartItems)
+	assert.Nil(t, sessionData.Coupon)
+	assert.Equal(t, 2, sessionData.Visits)
+}
+
+func TestSchemaValidation(t *testing.T) {
+	config, err := getNormalisedConfigForCookieTests(t, "https://supertokens.io", &sessmodels.TypeInput{
+		Schema: &sessmodels.SchemaInputConfig{
+			ValidateAccessTokenPayload: func(accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+				for key := range accessTokenPayload {
+					if key != "roles" {
+						return defaultErrors.New("unknown key " + key)
+					}
+				}
+				return nil
+			},
+			ValidateSessionData: func(sessionData map[string]interface{}, userContext supertokens.UserContext) error {
+				if _, ok := sessionData["cartItems"].([]interface{}); !ok {
+					return defaultErrors.New("cartItems must be a list")
+				}
+				return nil
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	userContext := &map[string]interface{}{}
+	// the keys added by the SDK are not validated
+	assert.NoError(t, validateAccessTokenPayloadWithSchema(config, map[string]interface{}{"roles": []interface{}{}, "_signInTime": float64(1)}, userContext))
+	err = validateAccessTokenPayloadWithSchema(config, map[string]interface{}{"role": "admin"}, userContext)
+	assert.EqualError(t, err, "invalid access token payload: unknown key role")
+	assert.True(t, defaultErrors.As(err, &errors.SchemaValidationError{}))
+
+	assert.NoError(t, validateSessionDataWithSchema(config, map[string]interface{}{"cartItems": []interface{}{}}, userContext))
+	assert.EqualError(t, validateSessionDataWithSchema(config, nil, userContext), "invalid session data: cartItems must be a list")
+}
+
+func TestInvalidSessionPayloadRespondsWith400(t *testing.T) {
+	maxAccessTokenPayloadSizeBytes := uint64(100)
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+			APIDomain:     "api.supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&sessmodels.TypeInput{
+				MaxAccessTokenPayloadSizeBytes: &maxAccessTokenPayloadSizeBytes,
+				Schema: &sessmodels.SchemaInputConfig{
+					ValidateAccessTokenPayload: func(accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) error {
+						if _, ok := accessTokenPayload["role"]; ok {
+							return defaultErrors.New("role is not allowed")
+						}
+						return nil
+					},
+				},
+			}),
+		},
+	}
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/create", func(rw http.ResponseWriter, r *http.Request) {
+		accessTokenPayload := map[string]interface{}{}
+		switch r.URL.Query().Get("payload") {
+		case "schema":
+			accessTokenPayload["role"] = "admin"
+		case "size":
+			accessTokenPayload["value"] = strings.Repeat("a", 200)
+		}
+		_, err := CreateNewSession(rw, "rope", accessTokenPayload, map[string]interface{}{})
+		if err != nil {
+			err = supertokens.ErrorHandler(err, r, rw)
+			if err != nil {
+				rw.WriteHeader(500)
+			}
+		}
+	})
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	for _, payload := range []string{"schema", "size"} {
+		res, err := http.Post(testServer.URL+"/create?payload="+payload, "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 400, res.StatusCode)
+	}
+
+	res, err := http.Post(testServer.URL+"/create", "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
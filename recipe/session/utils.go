@@ -22,6 +22,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/supertokens/supertokens-golang/recipe/session/api"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessionwithjwt"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -87,8 +88,8 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 	}
 
 	if config != nil && config.AntiCsrf != nil {
-		if *config.AntiCsrf != antiCSRF_NONE && *config.AntiCsrf != antiCSRF_VIA_CUSTOM_HEADER && *config.AntiCsrf != antiCSRF_VIA_TOKEN {
-			return sessmodels.TypeNormalisedInput{}, errors.New("antiCsrf config must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN'")
+		if !isValidAntiCsrfMode(*config.AntiCsrf) {
+			return sessmodels.TypeNormalisedInput{}, errors.New("antiCsrf config must be one of 'NONE' or 'VIA_CUSTOM_HEADER' or 'VIA_TOKEN' or 'VIA_ORIGIN' or 'VIA_DOUBLE_SUBMIT_COOKIE'")
 		}
 	}
 
@@ -103,6 +104,41 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		antiCsrf = *config.AntiCsrf
 	}
 
+	websiteOrigin, err := normaliseOrigin(appInfo.WebsiteDomain.GetAsStringDangerous())
+	if err != nil {
+		return sessmodels.TypeNormalisedInput{}, err
+	}
+	antiCsrfAllowedOrigins := []string{websiteOrigin}
+	if config != nil {
+		for _, origin := range config.AntiCsrfAllowedOrigins {
+			normalisedOrigin, err := normaliseOrigin(origin)
+			if err != nil {
+				return sessmodels.TypeNormalisedInput{}, errors.New("antiCsrfAllowedOrigins contains an invalid origin: " + origin)
+			}
+			if !containsString(antiCsrfAllowedOrigins, normalisedOrigin) {
+				antiCsrfAllowedOrigins = append(antiCsrfAllowedOrigins, normalisedOrigin)
+			}
+		}
+	}
+
+	refreshTokenReuseGraceWindowMS := uint64(0)
+	if config != nil && config.RefreshTokenReuseGraceWindowMS != nil {
+		refreshTokenReuseGraceWindowMS = *config.RefreshTokenReuseGraceWindowMS
+		if refreshTokenReuseGraceWindowMS > maxRefreshTokenReuseGraceWindowMS {
+			return sessmodels.TypeNormalisedInput{}, errors.New("refreshTokenReuseGraceWindowMS must not be more than 60000")
+		}
+	}
+
+	var buildAccessTokenPayload func(userID string, currentAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error)
+	if config != nil {
+		buildAccessTokenPayload = config.BuildAccessTokenPayload
+	}
+
+	maxAccessTokenPayloadSizeBytes := defaultMaxAccessTokenPayloadSizeBytes
+	if config != nil && config.MaxAccessTokenPayloadSizeBytes != nil {
+		maxAccessTokenPayloadSizeBytes = *config.MaxAccessTokenPayloadSizeBytes
+	}
+
 	errorHandlers := sessmodels.NormalisedErrorHandlers{
 		OnTokenTheftDetected: func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error {
 			recipeInstance, err := getRecipeInstanceOrThrowError()
@@ -125,6 +161,12 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 			}
 			return sendUnauthorisedResponse(*recipeInstance, message, req, res)
 		},
+		OnSessionLimitReached: func(userID string, req *http.Request, res http.ResponseWriter) error {
+			return sendSessionLimitReachedResponse(userID, req, res)
+		},
+		OnInvalidSessionPayload: func(message string, req *http.Request, res http.ResponseWriter) error {
+			return sendInvalidSessionPayloadResponse(message, req, res)
+		},
 	}
 
 	if config != nil && config.ErrorHandlers != nil {
@@ -134,6 +176,12 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		if config.ErrorHandlers.OnUnauthorised != nil {
 			errorHandlers.OnUnauthorised = config.ErrorHandlers.OnUnauthorised
 		}
+		if config.ErrorHandlers.OnSessionLimitReached != nil {
+			errorHandlers.OnSessionLimitReached = config.ErrorHandlers.OnSessionLimitReached
+		}
+		if config.ErrorHandlers.OnInvalidSessionPayload != nil {
+			errorHandlers.OnInvalidSessionPayload = config.ErrorHandlers.OnInvalidSessionPayload
+		}
 	}
 
 	IsAnIPAPIDomain, err := supertokens.IsAnIPAddress(topLevelAPIDomain)
@@ -169,15 +217,241 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		return sessmodels.TypeNormalisedInput{}, errors.New(sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY + " is a reserved property name, please use a different key name for the jwt")
 	}
 
+	sessionLimit := sessmodels.SessionLimitNormalisedConfig{Enable: false, OnLimitReached: sessionLimit_REVOKE_OLDEST}
+	if config != nil && config.SessionLimit != nil {
+		sessionLimit, err = validateAndNormaliseSessionLimitConfig(*config.SessionLimit)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	sessionLifetime := sessmodels.SessionLifetimeNormalisedConfig{Enable: false}
+	if config != nil && config.SessionLifetime != nil {
+		sessionLifetime, err = validateAndNormaliseSessionLifetimeConfig(*config.SessionLifetime)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	impersonation := sessmodels.ImpersonationNormalisedConfig{Enable: false, MaxLifetimeMS: defaultImpersonationMaxLifetimeMS}
+	if config != nil && config.Impersonation != nil {
+		impersonation.Enable = true
+		if config.Impersonation.MaxLifetimeMS != nil {
+			if *config.Impersonation.MaxLifetimeMS == 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("impersonation maxLifetimeMS must be greater than 0")
+			}
+			impersonation.MaxLifetimeMS = *config.Impersonation.MaxLifetimeMS
+		}
+		impersonation.OnImpersonationStart = config.Impersonation.OnImpersonationStart
+		impersonation.OnImpersonationEnd = config.Impersonation.OnImpersonationEnd
+	}
+
+	guestSession := sessmodels.GuestSessionNormalisedConfig{Enable: false, MergeGuestSession: defaultMergeGuestSession}
+	if config != nil && config.GuestSession != nil {
+		guestSession.Enable = true
+		if config.GuestSession.MergeGuestSession != nil {
+			guestSession.MergeGuestSession = config.GuestSession.MergeGuestSession
+		}
+	}
+
+	tokenTheftPolicy := sessmodels.TokenTheftPolicyNormalisedConfig{Enable: false}
+	if config != nil && config.TokenTheftPolicy != nil {
+		if len(config.TokenTheftPolicy.Actions) == 0 {
+			return sessmodels.TypeNormalisedInput{}, errors.New("tokenTheftPolicy must have at least one action")
+		}
+		tokenTheftPolicy.Enable = true
+		tokenTheftPolicy.Actions = config.TokenTheftPolicy.Actions
+		tokenTheftPolicy.OnActionError = config.TokenTheftPolicy.OnActionError
+	}
+
+	pageRefresh := sessmodels.PageRefreshNormalisedConfig{
+		Enable:   false,
+		LoginURL: appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous(),
+	}
+	if config != nil && config.PageRefresh != nil {
+		pageRefresh.Enable = true
+		if config.PageRefresh.LoginURL != nil {
+			if strings.TrimSpace(*config.PageRefresh.LoginURL) == "" {
+				return sessmodels.TypeNormalisedInput{}, errors.New("pageRefresh loginURL cannot be empty")
+			}
+			pageRefresh.LoginURL = *config.PageRefresh.LoginURL
+		}
+	}
+
+	encryption := sessmodels.EncryptionNormalisedConfig{Enable: false}
+	if config != nil && config.Encryption != nil {
+		encryption, err = validateAndNormaliseEncryptionConfig(*config.Encryption)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	schema := sessmodels.SchemaNormalisedConfig{}
+	if config != nil && config.Schema != nil {
+		schema.ValidateAccessTokenPayload = config.Schema.ValidateAccessTokenPayload
+		schema.ValidateSessionData = config.Schema.ValidateSessionData
+	}
+
+	rememberMe := sessmodels.RememberMeNormalisedConfig{Enable: false}
+	if config != nil && config.RememberMe != nil {
+		rememberMe.Enable = true
+		rememberMe.MaxLifetimeMS = config.RememberMe.MaxLifetimeMS
+	}
+
+	sessionTransfer := sessmodels.SessionTransferNormalisedConfig{Enable: false}
+	if config != nil && config.SessionTransfer != nil {
+		sessionTransfer, err = validateAndNormaliseSessionTransferConfig(*config.SessionTransfer, websiteOrigin)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	accountDeletion := sessmodels.AccountDeletionNormalisedConfig{Enable: false}
+	if config != nil && config.AccountDeletion != nil {
+		accountDeletion, err = validateAndNormaliseAccountDeletionConfig(*config.AccountDeletion)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	refreshTokenPath := appInfo.APIBasePath.AppendPath(refreshAPIPath)
+
+	cookieDomains := []string{}
+	if cookieDomain != nil {
+		cookieDomains = append(cookieDomains, *cookieDomain)
+	}
+	if config != nil {
+		for _, domain := range config.CookieDomains {
+			normalisedDomain, err := normaliseSessionScopeOrThrowError(domain)
+			if err != nil {
+				return sessmodels.TypeNormalisedInput{}, err
+			}
+			if !containsString(cookieDomains, *normalisedDomain) {
+				cookieDomains = append(cookieDomains, *normalisedDomain)
+			}
+		}
+	}
+
+	accessTokenCookiePath := "/"
+	if config != nil && config.AccessTokenCookiePath != nil {
+		accessTokenCookiePath, err = normaliseCookiePathOrThrowError(*config.AccessTokenCookiePath)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	refreshTokenCookiePath := refreshTokenPath.GetAsStringDangerous()
+	if config != nil && config.RefreshTokenCookiePath != nil {
+		refreshTokenCookiePath, err = normaliseCookiePathOrThrowError(*config.RefreshTokenCookiePath)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+		if !cookiePathMatches(refreshTokenCookiePath, refreshTokenPath.GetAsStringDangerous()) {
+			return sessmodels.TypeNormalisedInput{}, errors.New("refreshTokenCookiePath must be a parent path of the refresh API path (" + refreshTokenPath.GetAsStringDangerous() + "), otherwise the refresh token will not be sent to it")
+		}
+	}
+
+	cookieNamePrefix := ""
+	if config != nil && config.CookieNamePrefix != nil {
+		cookieNamePrefix = strings.TrimSpace(*config.CookieNamePrefix)
+	}
+	if strings.HasPrefix(cookieNamePrefix, cookieNamePrefix_HOST) {
+		if !cookieSecure {
+			return sessmodels.TypeNormalisedInput{}, errors.New("cookies with the __Host- prefix must be secure. Please use https on your apiDomain and dont set cookieSecure to false")
+		}
+		if len(cookieDomains) > 0 {
+			return sessmodels.TypeNormalisedInput{}, errors.New("cookies with the __Host- prefix cannot have a domain. Please remove cookieDomain and cookieDomains")
+		}
+		if accessTokenCookiePath != "/" || refreshTokenCookiePath != "/" {
+			return sessmodels.TypeNormalisedInput{}, errors.New("cookies with the __Host- prefix must use the path \"/\". Please set accessTokenCookiePath and refreshTokenCookiePath to \"/\"")
+		}
+	} else if strings.HasPrefix(cookieNamePrefix, cookieNamePrefix_SECURE) && !cookieSecure {
+		return sessmodels.TypeNormalisedInput{}, errors.New("cookies with the __Secure- prefix must be secure. Please use https on your apiDomain and dont set cookieSecure to false")
+	}
+
+	legacyCookieNamePrefixes := []string{}
+	if config != nil {
+		for _, prefix := range config.LegacyCookieNamePrefixes {
+			prefix = strings.TrimSpace(prefix)
+			if prefix == cookieNamePrefix {
+				return sessmodels.TypeNormalisedInput{}, errors.New("legacyCookieNamePrefixes must not contain the current cookieNamePrefix")
+			}
+			if !containsString(legacyCookieNamePrefixes, prefix) {
+				legacyCookieNamePrefixes = append(legacyCookieNamePrefixes, prefix)
+			}
+		}
+	}
+
+	legacyCookieDomains := []string{""}
+	if config != nil {
+		for _, domain := range config.LegacyCookieDomains {
+			normalisedDomain, err := normaliseSessionScopeOrThrowError(domain)
+			if err != nil {
+				return sessmodels.TypeNormalisedInput{}, err
+			}
+			if !containsString(legacyCookieDomains, *normalisedDomain) {
+				legacyCookieDomains = append(legacyCookieDomains, *normalisedDomain)
+			}
+		}
+	}
+
+	legacyAccessTokenCookiePath := "/"
+	if config != nil && config.LegacyAccessTokenCookiePath != nil {
+		legacyAccessTokenCookiePath, err = normaliseCookiePathOrThrowError(*config.LegacyAccessTokenCookiePath)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	legacyRefreshTokenCookiePath := refreshTokenPath.GetAsStringDangerous()
+	if config != nil && config.LegacyRefreshTokenCookiePath != nil {
+		legacyRefreshTokenCookiePath, err = normaliseCookiePathOrThrowError(*config.LegacyRefreshTokenCookiePath)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
+	cookiePartitioned := false
+	if config != nil && config.CookiePartitioned != nil {
+		cookiePartitioned = *config.CookiePartitioned
+	}
+	if cookiePartitioned && !cookieSecure {
+		return sessmodels.TypeNormalisedInput{}, errors.New("partitioned cookies must be secure. Please use https on your apiDomain and dont set cookieSecure to false")
+	}
+
 	typeNormalisedInput := sessmodels.TypeNormalisedInput{
-		RefreshTokenPath:         appInfo.APIBasePath.AppendPath(refreshAPIPath),
-		CookieDomain:             cookieDomain,
-		CookieSameSite:           cookieSameSite,
-		CookieSecure:             cookieSecure,
-		SessionExpiredStatusCode: sessionExpiredStatusCode,
-		AntiCsrf:                 antiCsrf,
-		ErrorHandlers:            errorHandlers,
-		Jwt:                      Jwt,
+		RefreshTokenPath:               refreshTokenPath,
+		CookieDomain:                   cookieDomain,
+		CookieDomains:                  cookieDomains,
+		CookieNamePrefix:               cookieNamePrefix,
+		LegacyCookieNamePrefixes:       legacyCookieNamePrefixes,
+		LegacyCookieDomains:            legacyCookieDomains,
+		LegacyAccessTokenCookiePath:    legacyAccessTokenCookiePath,
+		LegacyRefreshTokenCookiePath:   legacyRefreshTokenCookiePath,
+		CookiePartitioned:              cookiePartitioned,
+		AccessTokenCookiePath:          accessTokenCookiePath,
+		RefreshTokenCookiePath:         refreshTokenCookiePath,
+		CookieSameSite:                 cookieSameSite,
+		CookieSecure:                   cookieSecure,
+		SessionExpiredStatusCode:       sessionExpiredStatusCode,
+		AntiCsrf:                       antiCsrf,
+		AntiCsrfAllowedOrigins:         antiCsrfAllowedOrigins,
+		RefreshTokenReuseGraceWindowMS: refreshTokenReuseGraceWindowMS,
+		BuildAccessTokenPayload:        buildAccessTokenPayload,
+		MaxAccessTokenPayloadSizeBytes: maxAccessTokenPayloadSizeBytes,
+		ErrorHandlers:                  errorHandlers,
+		Jwt:                            Jwt,
+		SessionLimit:                   sessionLimit,
+		SessionLifetime:                sessionLifetime,
+		Impersonation:                  impersonation,
+		GuestSession:                   guestSession,
+		TokenTheftPolicy:               tokenTheftPolicy,
+		PageRefresh:                    pageRefresh,
+		Encryption:                     encryption,
+		Schema:                         schema,
+		RememberMe:                     rememberMe,
+		SessionTransfer:                sessionTransfer,
+		AccountDeletion:                accountDeletion,
 		Override: sessmodels.OverrideStruct{
 			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
 				return originalImplementation
@@ -199,6 +473,58 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	return typeNormalisedInput, nil
 }
+
+func validateAndNormaliseSessionLimitConfig(config sessmodels.SessionLimitInputConfig) (sessmodels.SessionLimitNormalisedConfig, error) {
+	if config.GetMaxSessionsForUser == nil && config.MaxSessionsPerUser <= 0 {
+		return sessmodels.SessionLimitNormalisedConfig{}, errors.New("sessionLimit config must have maxSessionsPerUser greater than 0 or provide getMaxSessionsForUser")
+	}
+
+	onLimitReached := sessionLimit_REVOKE_OLDEST
+	if config.OnLimitReached != nil {
+		if *config.OnLimitReached != sessionLimit_REVOKE_OLDEST && *config.OnLimitReached != sessionLimit_REJECT {
+			return sessmodels.SessionLimitNormalisedConfig{}, errors.New("sessionLimit onLimitReached must be one of 'REVOKE_OLDEST' or 'REJECT'")
+		}
+		onLimitReached = *config.OnLimitReached
+	}
+
+	getMaxSessionsForUser := config.GetMaxSessionsForUser
+	if getMaxSessionsForUser == nil {
+		maxSessionsPerUser := config.MaxSessionsPerUser
+		getMaxSessionsForUser = func(userID string, userContext supertokens.UserContext) (int, error) {
+			return maxSessionsPerUser, nil
+		}
+	}
+
+	return sessmodels.SessionLimitNormalisedConfig{
+		Enable:                true,
+		GetMaxSessionsForUser: getMaxSessionsForUser,
+		OnLimitReached:        onLimitReached,
+		OnSessionsEvicted:     config.OnSessionsEvicted,
+	}, nil
+}
+
+func validateAndNormaliseSessionLifetimeConfig(config sessmodels.SessionLifetimeInputConfig) (sessmodels.SessionLifetimeNormalisedConfig, error) {
+	if config.GetLifetimeForUser == nil && config.IdleTimeoutMS == 0 && config.AbsoluteLifetimeMS == 0 {
+		return sessmodels.SessionLifetimeNormalisedConfig{}, errors.New("sessionLifetime config must have idleTimeoutMS or absoluteLifetimeMS greater than 0 or provide getLifetimeForUser")
+	}
+
+	getLifetimeForUser := config.GetLifetimeForUser
+	if getLifetimeForUser == nil {
+		lifetime := sessmodels.SessionLifetime{
+			IdleTimeoutMS:      config.IdleTimeoutMS,
+			AbsoluteLifetimeMS: config.AbsoluteLifetimeMS,
+		}
+		getLifetimeForUser = func(userID string, userContext supertokens.UserContext) (sessmodels.SessionLifetime, error) {
+			return lifetime, nil
+		}
+	}
+
+	return sessmodels.SessionLifetimeNormalisedConfig{
+		Enable:             true,
+		GetLifetimeForUser: getLifetimeForUser,
+	}, nil
+}
+
 func normaliseSameSiteOrThrowError(sameSite string) (string, error) {
 	sameSite = strings.TrimSpace(sameSite)
 	sameSite = strings.ToLower(sameSite)
@@ -208,6 +534,40 @@ func normaliseSameSiteOrThrowError(sameSite string) (string, error) {
 	return sameSite, nil
 }
 
+func normaliseCookiePathOrThrowError(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		return "", errors.New("cookie paths must start with \"/\"")
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path, nil
+}
+
+// cookiePathMatches implements the path-match algorithm of RFC 6265
+func cookiePathMatches(cookiePath string, requestPath string) bool {
+	if requestPath == "" {
+		requestPath = "/"
+	}
+	if cookiePath == requestPath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func GetTopLevelDomainForSameSiteResolution(URL string) (string, error) {
 	urlObj, err := url.Parse(URL)
 	if err != nil {
@@ -278,19 +638,32 @@ func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedI
 	refreshToken := response.RefreshToken
 	idRefreshToken := response.IDRefreshToken
 	setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
-	attachAccessTokenToCookie(config, res, accessToken.Token, accessToken.Expiry)
-	attachRefreshTokenToCookie(config, res, refreshToken.Token, refreshToken.Expiry)
-	setIDRefreshTokenInHeaderAndCookie(config, res, idRefreshToken.Token, idRefreshToken.Expiry)
+	persistent := isSessionRemembered(response.Session.UserDataInAccessToken)
+	attachAccessTokenToCookie(config, res, accessToken.Token, accessToken.Expiry, persistent)
+	attachRefreshTokenToCookie(config, res, refreshToken.Token, refreshToken.Expiry, persistent)
+	setIDRefreshTokenInHeaderAndCookie(config, res, idRefreshToken.Token, idRefreshToken.Expiry, persistent)
+	clearLegacySessionCookies(config, res)
 	if response.AntiCsrfToken != nil {
 		setAntiCsrfTokenInHeaders(res, *response.AntiCsrfToken)
+		if config.AntiCsrf == antiCSRF_VIA_DOUBLE_SUBMIT_COOKIE {
+			attachAntiCsrfTokenToCookie(config, res, *response.AntiCsrfToken, refreshToken.Expiry, persistent)
+		}
 	}
 }
 
-func sendTryRefreshTokenResponse(recipeInstance Recipe, _ string, _ *http.Request, response http.ResponseWriter) error {
+func sendTryRefreshTokenResponse(recipeInstance Recipe, _ string, req *http.Request, response http.ResponseWriter) error {
+	if recipeInstance.Config.PageRefresh.Enable && isPageRequest(req) {
+		redirectPageRequest(req, response, getRefreshPageURL(recipeInstance.Config, req))
+		return nil
+	}
 	return supertokens.SendNon200Response(response, "try refresh token", recipeInstance.Config.SessionExpiredStatusCode)
 }
 
-func sendUnauthorisedResponse(recipeInstance Recipe, _ string, _ *http.Request, response http.ResponseWriter) error {
+func sendUnauthorisedResponse(recipeInstance Recipe, _ string, req *http.Request, response http.ResponseWriter) error {
+	if recipeInstance.Config.PageRefresh.Enable && isPageRequest(req) {
+		redirectPageRequest(req, response, api.GetLoginPageURL(recipeInstance.Config, req.URL.RequestURI()))
+		return nil
+	}
 	return supertokens.SendNon200Response(response, "unauthorised", recipeInstance.Config.SessionExpiredStatusCode)
 }
 
@@ -302,10 +675,58 @@ func sendTokenTheftDetectedResponse(recipeInstance Recipe, sessionHandle string,
 	return supertokens.SendNon200Response(response, "token theft detected", recipeInstance.Config.SessionExpiredStatusCode)
 }
 
+func sendSessionLimitReachedResponse(_ string, _ *http.Request, response http.ResponseWriter) error {
+	return supertokens.SendNon200Response(response, "session limit reached", 403)
+}
+
+func sendInvalidSessionPayloadResponse(message string, _ *http.Request, response http.ResponseWriter) error {
+	return supertokens.SendNon200Response(response, message, 400)
+}
+
 func frontendHasInterceptor(req *http.Request) bool {
 	return getRidFromHeader(req) != nil
 }
 
+var reservedAccessTokenPayloadKeys = []string{signInTimePayloadKey, lastActivityTimePayloadKey, impersonatedByPayloadKey, impersonationExpiryPayloadKey, isGuestPayloadKey, rememberMePayloadKey, rememberMeExpiryPayloadKey}
+
+// usesReservedAccessTokenPayloadKeys is true if a session of this app can have keys that are reserved by the SDK
+// in its access token payload, in which case they need to be kept when the payload is updated
+func usesReservedAccessTokenPayloadKeys(config sessmodels.TypeNormalisedInput) bool {
+	return config.SessionLifetime.Enable || config.Impersonation.Enable || config.GuestSession.Enable || config.RememberMe.Enable
+}
+
+// copyReservedKeysToAccessTokenPayload makes sure that updating the access token payload does not drop or
+// change the keys the SDK relies on: they are always taken from the existing payload
+func copyReservedKeysToAccessTokenPayload(existingAccessTokenPayload map[string]interface{}, newAccessTokenPayload map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range newAccessTokenPayload {
+		result[k] = v
+	}
+	for _, key := range reservedAccessTokenPayloadKeys {
+		delete(result, key)
+		if val, ok := existingAccessTokenPayload[key]; ok {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+// removeSDKKeysFromAccessTokenPayload returns a copy of the access token payload with only the keys set by the user
+func removeSDKKeysFromAccessTokenPayload(accessTokenPayload map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range accessTokenPayload {
+		result[k] = v
+	}
+	for _, key := range reservedAccessTokenPayloadKeys {
+		delete(result, key)
+	}
+	if jwtPropertyName, ok := result[sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY].(string); ok {
+		delete(result, jwtPropertyName)
+		delete(result, sessionwithjwt.ACCESS_TOKEN_PAYLOAD_JWT_PROPERTY_NAME_KEY)
+	}
+	return result
+}
+
 func getKeyInfoFromJson(response map[string]interface{}) []sessmodels.KeyInfo {
 	keyList := []sessmodels.KeyInfo{}
 
@@ -148,7 +148,38 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 			}, nil
 		}
 
+		// the sign up gate does not stop existing users from signing in. For new users, the invitation is used
+		// up before they are created, so that it cannot be used by a concurrent sign up
+		existingUser, err := (*options.RecipeImplementation.GetUserByThirdPartyInfo)(provider.ID, userInfo.ID, userContext)
+		if err != nil {
+			return tpmodels.SignInUpPOSTResponse{}, err
+		}
+		var invitation *supertokens.Invitation
+		if existingUser == nil {
+			var msg *string
+			invitation, msg, err = supertokens.ConsumeInvitation(&emailInfo.ID, nil, supertokens.GetInviteToken(userContext), userContext)
+			if err != nil {
+				return tpmodels.SignInUpPOSTResponse{}, err
+			}
+			if msg != nil {
+				return tpmodels.SignInUpPOSTResponse{
+					FieldError: &struct{ ErrorMsg string }{
+						ErrorMsg: *msg,
+					},
+				}, nil
+			}
+		}
+
 		response, err := (*options.RecipeImplementation.SignInUp)(provider.ID, userInfo.ID, *emailInfo, userContext)
+		if err == nil && response.OK != nil && response.OK.CreatedNewUser {
+			err = supertokens.RedeemInvitation(response.OK.User.ID, invitation, userContext)
+		} else {
+			// no user was created, so the invitation can still be used
+			restoreErr := supertokens.RestoreInvitation(invitation, supertokens.GetInviteToken(userContext), userContext)
+			if restoreErr != nil {
+				return tpmodels.SignInUpPOSTResponse{}, restoreErr
+			}
+		}
 		if err != nil {
 			return tpmodels.SignInUpPOSTResponse{}, err
 		}
@@ -173,7 +204,7 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 			}
 		}
 
-		session, err := session.CreateNewSessionWithContext(options.Res, response.OK.User.ID, nil, nil, userContext)
+		session, err := session.CreateNewSessionUpgradingGuestSessionWithContext(options.Req, options.Res, response.OK.User.ID, nil, nil, userContext)
 		if err != nil {
 			return tpmodels.SignInUpPOSTResponse{}, err
 		}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -29,6 +30,8 @@ type bodyParams struct {
 	RedirectURI      string                 `json:"redirectURI"`
 	AuthCodeResponse map[string]interface{} `json:"authCodeResponse"`
 	ClientId         string                 `json:"clientId"`
+	RememberMe       *bool                  `json:"rememberMe"`
+	InviteToken      string                 `json:"inviteToken"`
 }
 
 func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOptions) error {
@@ -78,7 +81,13 @@ func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOp
 		}
 	}
 
-	result, err := (*apiImplementation.SignInUpPOST)(*provider, bodyParams.Code, bodyParams.AuthCodeResponse, bodyParams.RedirectURI, options, &map[string]interface{}{})
+	userContext := &map[string]interface{}{}
+	if bodyParams.RememberMe != nil {
+		session.SetRememberMe(userContext, *bodyParams.RememberMe)
+	}
+	supertokens.SetInviteToken(userContext, bodyParams.InviteToken)
+
+	result, err := (*apiImplementation.SignInUpPOST)(*provider, bodyParams.Code, bodyParams.AuthCodeResponse, bodyParams.RedirectURI, options, userContext)
 
 	if err != nil {
 		return err
@@ -0,0 +1,74 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdparty
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// MakeEmailPolicyRecipeImplementation normalises the emails before they reach the core, and stops new
+// users from signing up with an email domain that the policy does not allow. Users are looked up by
+// the canonical form of their email as well as by the email as it was entered, so that users who
+// signed up before the policy was enabled are still found. The combined recipes apply it to their
+// third party users
+func MakeEmailPolicyRecipeImplementation(originalImplementation tpmodels.RecipeInterface, policy supertokens.NormalisedEmailPolicy) tpmodels.RecipeInterface {
+	originalGetUsersByEmail := *originalImplementation.GetUsersByEmail
+	originalSignInUp := *originalImplementation.SignInUp
+
+	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tpmodels.User, error) {
+		emails, err := supertokens.GetEmailsToLookUp(policy, email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		users := []tpmodels.User{}
+		foundUserIDs := map[string]bool{}
+		for _, emailToLookUp := range emails {
+			emailUsers, err := originalGetUsersByEmail(emailToLookUp, userContext)
+			if err != nil {
+				return nil, err
+			}
+			for _, user := range emailUsers {
+				if !foundUserIDs[user.ID] {
+					foundUserIDs[user.ID] = true
+					users = append(users, user)
+				}
+			}
+		}
+		return users, nil
+	}
+
+	signInUp := func(thirdPartyID string, thirdPartyUserID string, email tpmodels.EmailStruct, userContext supertokens.UserContext) (tpmodels.SignInUpResponse, error) {
+		if msg := supertokens.ValidateEmailDomain(policy, email.ID); msg != nil {
+			// users who signed up before their domain was blocked can still sign in
+			existingUser, err := (*originalImplementation.GetUserByThirdPartyInfo)(thirdPartyID, thirdPartyUserID, userContext)
+			if err != nil {
+				return tpmodels.SignInUpResponse{}, err
+			}
+			if existingUser == nil {
+				return tpmodels.SignInUpResponse{
+					FieldError: &struct{ ErrorMsg string }{ErrorMsg: *msg},
+				}, nil
+			}
+		}
+		email.ID = supertokens.NormaliseEmail(policy, email.ID)
+		return originalSignInUp(thirdPartyID, thirdPartyUserID, email, userContext)
+	}
+
+	*originalImplementation.GetUsersByEmail = getUsersByEmail
+	*originalImplementation.SignInUp = signInUp
+	return originalImplementation
+}
@@ -53,7 +53,11 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	}
 	r.Config = verifiedConfig
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
-	r.RecipeImpl = verifiedConfig.Override.Functions(MakeRecipeImplementation(*querierInstance))
+	recipeImplementation := MakeRecipeImplementation(*querierInstance)
+	if verifiedConfig.EmailPolicy.Enabled {
+		recipeImplementation = MakeEmailPolicyRecipeImplementation(recipeImplementation, verifiedConfig.EmailPolicy)
+	}
+	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 	r.Providers = config.SignInAndUpFeature.Providers
 
 	if emailVerificationInstance == nil {
@@ -80,12 +80,14 @@ type TypeNormalisedInputSignInAndUp struct {
 type TypeInput struct {
 	SignInAndUpFeature       TypeInputSignInAndUp
 	EmailVerificationFeature *TypeInputEmailVerificationFeature
+	EmailPolicy              *supertokens.EmailPolicy
 	Override                 *OverrideStruct
 }
 
 type TypeNormalisedInput struct {
 	SignInAndUpFeature       TypeNormalisedInputSignInAndUp
 	EmailVerificationFeature evmodels.TypeInput
+	EmailPolicy              supertokens.NormalisedEmailPolicy
 	Override                 OverrideStruct
 }
 
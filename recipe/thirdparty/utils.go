@@ -35,6 +35,14 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 
 	typeNormalisedInput.EmailVerificationFeature = validateAndNormaliseEmailVerificationConfig(recipeInstance, config)
 
+	if config != nil && config.EmailPolicy != nil {
+		emailPolicy, err := supertokens.NormaliseEmailPolicyOrThrowError(config.EmailPolicy)
+		if err != nil {
+			return tpmodels.TypeNormalisedInput{}, err
+		}
+		typeNormalisedInput.EmailPolicy = emailPolicy
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
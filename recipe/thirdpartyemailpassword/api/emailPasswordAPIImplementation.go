@@ -26,8 +26,10 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 
 	result := epmodels.APIInterface{
 		EmailExistsGET:                 apiImplmentation.EmailPasswordEmailExistsGET,
+		UsernameExistsGET:              apiImplmentation.EmailPasswordUsernameExistsGET,
 		GeneratePasswordResetTokenPOST: apiImplmentation.GeneratePasswordResetTokenPOST,
 		PasswordResetPOST:              apiImplmentation.PasswordResetPOST,
+		UnlockAccountPOST:              apiImplmentation.UnlockAccountPOST,
 		SignInPOST:                     nil,
 		SignUpPOST:                     nil,
 	}
@@ -53,6 +55,10 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 						Session: result.OK.Session,
 					},
 				}, nil
+			} else if result.LockedAccountError != nil {
+				return epmodels.SignInPOSTResponse{
+					LockedAccountError: &struct{}{},
+				}, nil
 			} else {
 				return epmodels.SignInPOSTResponse{
 					WrongCredentialsError: &struct{}{},
@@ -35,6 +35,11 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 
 	}
 
+	ogUsernameExistsGET := *emailPasswordImplementation.UsernameExistsGET
+	usernameExistsGET := func(username string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.UsernameExistsGETResponse, error) {
+		return ogUsernameExistsGET(username, options, userContext)
+	}
+
 	ogGeneratePasswordResetTokenPOST := *emailPasswordImplementation.GeneratePasswordResetTokenPOST
 	generatePasswordResetTokenPOST := func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.GeneratePasswordResetTokenPOSTResponse, error) {
 		return ogGeneratePasswordResetTokenPOST(formFields, options, userContext)
@@ -45,6 +50,11 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 		return ogPasswordResetPOST(formFields, token, options, userContext)
 	}
 
+	ogUnlockAccountPOST := *emailPasswordImplementation.UnlockAccountPOST
+	unlockAccountPOST := func(token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.UnlockAccountPOSTResponse, error) {
+		return ogUnlockAccountPOST(token, options, userContext)
+	}
+
 	ogSignInPOST := *emailPasswordImplementation.SignInPOST
 	emailPasswordSignInPOST := func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (tpepmodels.SignInPOSTResponse, error) {
 		response, err := ogSignInPOST(formFields, options, userContext)
@@ -66,6 +76,10 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 					Session: response.OK.Session,
 				},
 			}, nil
+		} else if response.LockedAccountError != nil {
+			return tpepmodels.SignInPOSTResponse{
+				LockedAccountError: &struct{}{},
+			}, nil
 		} else {
 			return tpepmodels.SignInPOSTResponse{
 				WrongCredentialsError: &struct{}{},
@@ -151,8 +165,10 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 	result := tpepmodels.APIInterface{
 		AuthorisationUrlGET:            &authorisationUrlGET,
 		EmailPasswordEmailExistsGET:    &emailExistsGET,
+		EmailPasswordUsernameExistsGET: &usernameExistsGET,
 		GeneratePasswordResetTokenPOST: &generatePasswordResetTokenPOST,
 		PasswordResetPOST:              &passwordResetPOST,
+		UnlockAccountPOST:              &unlockAccountPOST,
 		ThirdPartySignInUpPOST:         &thirdPartySignInUpPOST,
 		EmailPasswordSignInPOST:        &emailPasswordSignInPOST,
 		EmailPasswordSignUpPOST:        &emailPasswordSignUpPOST,
@@ -161,8 +177,10 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 
 	modifiedEP := GetEmailPasswordIterfaceImpl(result)
 	(*emailPasswordImplementation.EmailExistsGET) = *modifiedEP.EmailExistsGET
+	(*emailPasswordImplementation.UsernameExistsGET) = *modifiedEP.UsernameExistsGET
 	(*emailPasswordImplementation.GeneratePasswordResetTokenPOST) = *modifiedEP.GeneratePasswordResetTokenPOST
 	(*emailPasswordImplementation.PasswordResetPOST) = *modifiedEP.PasswordResetPOST
+	(*emailPasswordImplementation.UnlockAccountPOST) = *modifiedEP.UnlockAccountPOST
 	(*emailPasswordImplementation.SignInPOST) = *modifiedEP.SignInPOST
 	(*emailPasswordImplementation.SignUpPOST) = *modifiedEP.SignUpPOST
 
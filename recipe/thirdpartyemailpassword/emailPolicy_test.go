@@ -0,0 +1,76 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+package thirdpartyemailpassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestEmailPolicyIsCheckedByTheRecipeFunctions(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&tpepmodels.TypeInput{
+				EmailPolicy: &supertokens.EmailPolicy{
+					AllowedDomains: []string{"example.com"},
+				},
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = EmailPasswordSignUp("john@gmail.com", "validpass123")
+	fieldError, ok := err.(errors.FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "email", fieldError.Payload[0].ID)
+
+	signInUpResponse, err := ThirdPartySignInUp("google", "john", tpepmodels.EmailStruct{ID: "john@gmail.com"})
+	assert.NoError(t, err)
+	assert.NotNil(t, signInUpResponse.FieldError)
+
+	signUpResponse, err := EmailPasswordSignUp("John@Example.com", "validpass123")
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", signUpResponse.OK.User.Email)
+
+	signInUpResponse, err = ThirdPartySignInUp("google", "john", tpepmodels.EmailStruct{ID: "JOHN@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", signInUpResponse.OK.User.Email)
+
+	users, err := GetUsersByEmail("John@EXAMPLE.com")
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Nil(t, users[0].ThirdParty)
+	assert.Equal(t, "google", users[1].ThirdParty.ID)
+}
@@ -18,6 +18,7 @@ package thirdpartyemailpassword
 import (
 	"errors"
 
+	epapi "github.com/supertokens/supertokens-golang/recipe/emailpassword/api"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
@@ -77,6 +78,14 @@ func GetUsersByEmailWithContext(email string, userContext supertokens.UserContex
 	return (*instance.RecipeImpl.GetUsersByEmail)(email, userContext)
 }
 
+func GetUserByUsernameWithContext(username string, userContext supertokens.UserContext) (*tpepmodels.User, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return instance.getUserByUsername(username, userContext)
+}
+
 func CreateResetPasswordTokenWithContext(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -101,6 +110,19 @@ func UpdateEmailOrPasswordWithContext(userId string, email *string, password *st
 	return (*instance.RecipeImpl.UpdateEmailOrPassword)(userId, email, password, userContext)
 }
 
+// UnlockAccountWithContext removes a lock from brute force protection before it expires
+func UnlockAccountWithContext(userID string, userContext supertokens.UserContext) error {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	config := instance.emailPasswordRecipe.Config.BruteForceProtection
+	if !config.Enabled {
+		return errors.New("please configure bruteForceProtection in the thirdpartyemailpassword recipe to unlock accounts")
+	}
+	return epapi.UnlockAccount(config, userID, userContext)
+}
+
 func CreateEmailVerificationTokenWithContext(userID string, userContext supertokens.UserContext) (evmodels.CreateEmailVerificationTokenResponse, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -188,6 +210,10 @@ func GetUsersByEmail(email string) ([]tpepmodels.User, error) {
 	return GetUsersByEmailWithContext(email, &map[string]interface{}{})
 }
 
+func GetUserByUsername(username string) (*tpepmodels.User, error) {
+	return GetUserByUsernameWithContext(username, &map[string]interface{}{})
+}
+
 func CreateResetPasswordToken(userID string) (epmodels.CreateResetPasswordTokenResponse, error) {
 	return CreateResetPasswordTokenWithContext(userID, &map[string]interface{}{})
 }
@@ -200,6 +226,10 @@ func UpdateEmailOrPassword(userId string, email *string, password *string) (epmo
 	return UpdateEmailOrPasswordWithContext(userId, email, password, &map[string]interface{}{})
 }
 
+func UnlockAccount(userID string) error {
+	return UnlockAccountWithContext(userID, &map[string]interface{}{})
+}
+
 func CreateEmailVerificationToken(userID string) (evmodels.CreateEmailVerificationTokenResponse, error) {
 	return CreateEmailVerificationTokenWithContext(userID, &map[string]interface{}{})
 }
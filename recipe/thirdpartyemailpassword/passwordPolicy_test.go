@@ -0,0 +1,93 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/errors"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestPasswordPolicyIsCheckedByTheRecipeFunctions(t *testing.T) {
+	passwordHashes := map[string][]string{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&tpepmodels.TypeInput{
+				PasswordPolicy: &epmodels.TypeInputPasswordPolicy{
+					PasswordHistory: &epmodels.TypeInputPasswordHistory{
+						Size: 3,
+						GetPasswordHashes: func(userID string, userContext supertokens.UserContext) ([]string, error) {
+							return passwordHashes[userID], nil
+						},
+						SetPasswordHashes: func(userID string, hashes []string, userContext supertokens.UserContext) error {
+							passwordHashes[userID] = hashes
+							return nil
+						},
+						SigningKey: []byte("0123456789abcdef0123456789abcdef"),
+					},
+				},
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = EmailPasswordSignUp("random@gmail.com", "short")
+	fieldError, ok := err.(errors.FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "password", fieldError.Payload[0].ID)
+
+	signUpResponse, err := EmailPasswordSignUp("random@gmail.com", "validpass123")
+	assert.NoError(t, err)
+	userID := signUpResponse.OK.User.ID
+	assert.Len(t, passwordHashes[userID], 1)
+
+	tokenResponse, err := CreateResetPasswordToken(userID)
+	assert.NoError(t, err)
+	_, err = ResetPasswordUsingToken(tokenResponse.OK.Token, "validpass123")
+	fieldError, ok = err.(errors.FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, errors.PasswordPolicyReused, fieldError.Payload[0].Violations[0].Rule)
+
+	resetResponse, err := ResetPasswordUsingToken(tokenResponse.OK.Token, "validpass12345")
+	assert.NoError(t, err)
+	assert.NotNil(t, resetResponse.OK)
+	assert.Len(t, passwordHashes[userID], 2)
+
+	newPassword := "validpass123"
+	_, err = UpdateEmailOrPassword(userID, nil, &newPassword)
+	_, ok = err.(errors.FieldError)
+	assert.True(t, ok)
+}
@@ -63,7 +63,34 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 			return Recipe{}, err
 		}
 
-		r.RecipeImpl = verifiedConfig.Override.Functions(recipeimplementation.MakeRecipeImplementation(*emailpasswordquerierInstance, thirdpartyquerierInstance))
+		recipeImplementation := recipeimplementation.MakeRecipeImplementation(*emailpasswordquerierInstance, thirdpartyquerierInstance)
+		if verifiedConfig.EmailPolicy != nil {
+			emailPolicy, err := supertokens.NormaliseEmailPolicyOrThrowError(verifiedConfig.EmailPolicy)
+			if err != nil {
+				return Recipe{}, err
+			}
+			emailPasswordImplementation := emailpassword.MakeEmailPolicyRecipeImplementation(recipeimplementation.MakeEmailPasswordRecipeImplementation(recipeImplementation), emailPolicy)
+			recipeImplementation = recipeimplementation.WithEmailPasswordRecipeImplementation(recipeImplementation, emailPasswordImplementation)
+			thirdPartyImplementation := thirdparty.MakeEmailPolicyRecipeImplementation(recipeimplementation.MakeThirdPartyRecipeImplementation(recipeImplementation), emailPolicy)
+			recipeImplementation = recipeimplementation.WithThirdPartyRecipeImplementation(recipeImplementation, thirdPartyImplementation)
+		}
+		if verifiedConfig.UsernameFeature != nil {
+			usernameFeature, err := emailpassword.ValidateAndNormaliseUsernameFeature(verifiedConfig.UsernameFeature)
+			if err != nil {
+				return Recipe{}, err
+			}
+			emailPasswordImplementation := emailpassword.MakeUsernameRecipeImplementation(recipeimplementation.MakeEmailPasswordRecipeImplementation(recipeImplementation), usernameFeature)
+			recipeImplementation = recipeimplementation.WithEmailPasswordRecipeImplementation(recipeImplementation, emailPasswordImplementation)
+		}
+		if verifiedConfig.PasswordPolicy != nil {
+			passwordPolicy, err := emailpassword.ValidateAndNormalisePasswordPolicy(verifiedConfig.PasswordPolicy)
+			if err != nil {
+				return Recipe{}, err
+			}
+			emailPasswordImplementation := emailpassword.MakePasswordPolicyRecipeImplementation(recipeimplementation.MakeEmailPasswordRecipeImplementation(recipeImplementation), passwordPolicy)
+			recipeImplementation = recipeimplementation.WithEmailPasswordRecipeImplementation(recipeImplementation, emailPasswordImplementation)
+		}
+		r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 	}
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
 
@@ -83,6 +110,10 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		emailPasswordConfig := &epmodels.TypeInput{
 			SignUpFeature:                  verifiedConfig.SignUpFeature,
 			ResetPasswordUsingTokenFeature: verifiedConfig.ResetPasswordUsingTokenFeature,
+			PasswordPolicy:                 verifiedConfig.PasswordPolicy,
+			EmailPolicy:                    verifiedConfig.EmailPolicy,
+			UsernameFeature:                verifiedConfig.UsernameFeature,
+			BruteForceProtection:           verifiedConfig.BruteForceProtection,
 			Override: &epmodels.OverrideStruct{
 				Functions: func(_ epmodels.RecipeInterface) epmodels.RecipeInterface {
 					return recipeimplementation.MakeEmailPasswordRecipeImplementation(r.RecipeImpl)
@@ -108,6 +139,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 				SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
 					Providers: verifiedConfig.Providers,
 				},
+				EmailPolicy: verifiedConfig.EmailPolicy,
 				Override: &tpmodels.OverrideStruct{
 					Functions: func(_ tpmodels.RecipeInterface) tpmodels.RecipeInterface {
 						return recipeimplementation.MakeThirdPartyRecipeImplementation(r.RecipeImpl)
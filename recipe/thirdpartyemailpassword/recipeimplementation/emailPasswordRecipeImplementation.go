@@ -120,3 +120,97 @@ func MakeEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.Recip
 		UpdateEmailOrPassword:    &updateEmailOrPassword,
 	}
 }
+
+// WithEmailPasswordRecipeImplementation is the reverse of MakeEmailPasswordRecipeImplementation: it replaces the
+// email password functions of recipeImplementation with those of emailPasswordImplementation. This lets the
+// features of the emailpassword recipe be applied to the email password users of this recipe
+func WithEmailPasswordRecipeImplementation(recipeImplementation tpepmodels.RecipeInterface, emailPasswordImplementation epmodels.RecipeInterface) tpepmodels.RecipeInterface {
+	getUserByID := *recipeImplementation.GetUserByID
+	getUserByThirdPartyInfo := *recipeImplementation.GetUserByThirdPartyInfo
+	thirdPartySignInUp := *recipeImplementation.ThirdPartySignInUp
+	originalGetUsersByEmail := *recipeImplementation.GetUsersByEmail
+
+	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tpepmodels.User, error) {
+		users, err := originalGetUsersByEmail(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		emailPasswordUser, err := (*emailPasswordImplementation.GetUserByEmail)(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		finalResult := []tpepmodels.User{}
+		if emailPasswordUser != nil {
+			finalResult = append(finalResult, tpepmodels.User{
+				ID:         emailPasswordUser.ID,
+				Email:      emailPasswordUser.Email,
+				TimeJoined: emailPasswordUser.TimeJoined,
+			})
+		}
+		for _, user := range users {
+			if user.ThirdParty != nil {
+				finalResult = append(finalResult, user)
+			}
+		}
+		return finalResult, nil
+	}
+
+	emailPasswordSignUp := func(email string, password string, userContext supertokens.UserContext) (tpepmodels.SignUpResponse, error) {
+		response, err := (*emailPasswordImplementation.SignUp)(email, password, userContext)
+		if err != nil {
+			return tpepmodels.SignUpResponse{}, err
+		}
+		if response.EmailAlreadyExistsError != nil {
+			return tpepmodels.SignUpResponse{
+				EmailAlreadyExistsError: &struct{}{},
+			}, nil
+		}
+		return tpepmodels.SignUpResponse{
+			OK: &struct{ User tpepmodels.User }{
+				User: tpepmodels.User{
+					ID:         response.OK.User.ID,
+					Email:      response.OK.User.Email,
+					TimeJoined: response.OK.User.TimeJoined,
+				},
+			},
+		}, nil
+	}
+
+	emailPasswordSignIn := func(email string, password string, userContext supertokens.UserContext) (tpepmodels.SignInResponse, error) {
+		response, err := (*emailPasswordImplementation.SignIn)(email, password, userContext)
+		if err != nil {
+			return tpepmodels.SignInResponse{}, err
+		}
+		if response.WrongCredentialsError != nil {
+			return tpepmodels.SignInResponse{
+				WrongCredentialsError: &struct{}{},
+			}, nil
+		}
+		return tpepmodels.SignInResponse{
+			OK: &struct{ User tpepmodels.User }{
+				User: tpepmodels.User{
+					ID:         response.OK.User.ID,
+					Email:      response.OK.User.Email,
+					TimeJoined: response.OK.User.TimeJoined,
+				},
+			},
+		}, nil
+	}
+
+	createResetPasswordToken := *emailPasswordImplementation.CreateResetPasswordToken
+	resetPasswordUsingToken := *emailPasswordImplementation.ResetPasswordUsingToken
+	updateEmailOrPassword := *emailPasswordImplementation.UpdateEmailOrPassword
+
+	return tpepmodels.RecipeInterface{
+		GetUserByID:              &getUserByID,
+		GetUsersByEmail:          &getUsersByEmail,
+		GetUserByThirdPartyInfo:  &getUserByThirdPartyInfo,
+		ThirdPartySignInUp:       &thirdPartySignInUp,
+		EmailPasswordSignUp:      &emailPasswordSignUp,
+		EmailPasswordSignIn:      &emailPasswordSignIn,
+		CreateResetPasswordToken: &createResetPasswordToken,
+		ResetPasswordUsingToken:  &resetPasswordUsingToken,
+		UpdateEmailOrPassword:    &updateEmailOrPassword,
+	}
+}
@@ -115,3 +115,103 @@ func MakeThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeIn
 		SignInUp:                &signInUp,
 	}
 }
+
+// WithThirdPartyRecipeImplementation is the reverse of MakeThirdPartyRecipeImplementation: it replaces the
+// third party functions of recipeImplementation with those of thirdPartyImplementation, so that the features
+// of the thirdparty recipe also apply to the third party users of this recipe
+func WithThirdPartyRecipeImplementation(recipeImplementation tpepmodels.RecipeInterface, thirdPartyImplementation tpmodels.RecipeInterface) tpepmodels.RecipeInterface {
+	getUserByID := *recipeImplementation.GetUserByID
+	emailPasswordSignUp := *recipeImplementation.EmailPasswordSignUp
+	emailPasswordSignIn := *recipeImplementation.EmailPasswordSignIn
+	createResetPasswordToken := *recipeImplementation.CreateResetPasswordToken
+	resetPasswordUsingToken := *recipeImplementation.ResetPasswordUsingToken
+	updateEmailOrPassword := *recipeImplementation.UpdateEmailOrPassword
+	originalGetUsersByEmail := *recipeImplementation.GetUsersByEmail
+
+	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tpepmodels.User, error) {
+		users, err := originalGetUsersByEmail(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		thirdPartyUsers, err := (*thirdPartyImplementation.GetUsersByEmail)(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		finalResult := []tpepmodels.User{}
+		for _, user := range users {
+			if user.ThirdParty == nil {
+				finalResult = append(finalResult, user)
+			}
+		}
+		for _, tpUser := range thirdPartyUsers {
+			finalResult = append(finalResult, getUserFromThirdPartyUser(tpUser))
+		}
+		return finalResult, nil
+	}
+
+	getUserByThirdPartyInfo := func(thirdPartyID string, thirdPartyUserID string, userContext supertokens.UserContext) (*tpepmodels.User, error) {
+		user, err := (*thirdPartyImplementation.GetUserByThirdPartyInfo)(thirdPartyID, thirdPartyUserID, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, nil
+		}
+		tpepUser := getUserFromThirdPartyUser(*user)
+		return &tpepUser, nil
+	}
+
+	thirdPartySignInUp := func(thirdPartyID string, thirdPartyUserID string, email tpepmodels.EmailStruct, userContext supertokens.UserContext) (tpepmodels.SignInUpResponse, error) {
+		result, err := (*thirdPartyImplementation.SignInUp)(thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{
+			ID:         email.ID,
+			IsVerified: email.IsVerified,
+		}, userContext)
+		if err != nil {
+			return tpepmodels.SignInUpResponse{}, err
+		}
+		if result.FieldError != nil {
+			return tpepmodels.SignInUpResponse{
+				FieldError: &struct{ ErrorMsg string }{
+					ErrorMsg: result.FieldError.ErrorMsg,
+				},
+			}, nil
+		}
+		return tpepmodels.SignInUpResponse{
+			OK: &struct {
+				CreatedNewUser bool
+				User           tpepmodels.User
+			}{
+				CreatedNewUser: result.OK.CreatedNewUser,
+				User:           getUserFromThirdPartyUser(result.OK.User),
+			},
+		}, nil
+	}
+
+	return tpepmodels.RecipeInterface{
+		GetUserByID:              &getUserByID,
+		GetUsersByEmail:          &getUsersByEmail,
+		GetUserByThirdPartyInfo:  &getUserByThirdPartyInfo,
+		ThirdPartySignInUp:       &thirdPartySignInUp,
+		EmailPasswordSignUp:      &emailPasswordSignUp,
+		EmailPasswordSignIn:      &emailPasswordSignIn,
+		CreateResetPasswordToken: &createResetPasswordToken,
+		ResetPasswordUsingToken:  &resetPasswordUsingToken,
+		UpdateEmailOrPassword:    &updateEmailOrPassword,
+	}
+}
+
+func getUserFromThirdPartyUser(user tpmodels.User) tpepmodels.User {
+	return tpepmodels.User{
+		ID:         user.ID,
+		Email:      user.Email,
+		TimeJoined: user.TimeJoined,
+		ThirdParty: &struct {
+			ID     string
+			UserID string
+		}{
+			ID:     user.ThirdParty.ID,
+			UserID: user.ThirdParty.UserID,
+		},
+	}
+}
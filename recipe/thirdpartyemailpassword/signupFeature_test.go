@@ -268,3 +268,58 @@ func TestSignUpAPIWorksWhenInputIsFine(t *testing.T) {
 	assert.Equal(t, "OK", result["status"])
 	assert.Equal(t, "random@gmail.com", result["user"].(map[string]interface{})["email"])
 }
+
+func TestSignUpAPIUsesRememberMe(t *testing.T) {
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(nil),
+			session.Init(&sessmodels.TypeInput{
+				RememberMe: &sessmodels.RememberMeInputConfig{},
+			}),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	mux := http.NewServeMux()
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	defer testServer.Close()
+
+	postBody, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "email", "value": "random@gmail.com"},
+			{"id": "password", "value": "validpass123"},
+		},
+		"rememberMe": false,
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	resp, err := http.Post(testServer.URL+"/auth/signup", "application/json", bytes.NewBuffer(postBody))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var accessTokenCookie *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "sAccessToken" {
+			accessTokenCookie = cookie
+		}
+	}
+	assert.NotNil(t, accessTokenCookie)
+	assert.Equal(t, "", accessTokenCookie.RawExpires)
+}
@@ -47,6 +47,10 @@ type TypeInput struct {
 	Providers                      []tpmodels.TypeProvider
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       *TypeInputEmailVerificationFeature
+	PasswordPolicy                 *epmodels.TypeInputPasswordPolicy
+	EmailPolicy                    *supertokens.EmailPolicy
+	UsernameFeature                *epmodels.TypeInputUsernameFeature
+	BruteForceProtection           *epmodels.TypeInputBruteForceProtection
 	Override                       *OverrideStruct
 }
 
@@ -55,6 +59,10 @@ type TypeNormalisedInput struct {
 	Providers                      []tpmodels.TypeProvider
 	ResetPasswordUsingTokenFeature *epmodels.TypeInputResetPasswordUsingTokenFeature
 	EmailVerificationFeature       evmodels.TypeInput
+	PasswordPolicy                 *epmodels.TypeInputPasswordPolicy
+	EmailPolicy                    *supertokens.EmailPolicy
+	UsernameFeature                *epmodels.TypeInputUsernameFeature
+	BruteForceProtection           *epmodels.TypeInputBruteForceProtection
 	Override                       OverrideStruct
 }
 
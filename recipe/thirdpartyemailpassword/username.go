@@ -0,0 +1,38 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package thirdpartyemailpassword
+
+import (
+	"errors"
+
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func (r *Recipe) getUserByUsername(username string, userContext supertokens.UserContext) (*tpepmodels.User, error) {
+	if !r.emailPasswordRecipe.Config.UsernameFeature.Enabled {
+		return nil, errors.New("please configure usernameFeature in the thirdpartyemailpassword recipe to get users by username")
+	}
+	user, err := r.emailPasswordRecipe.GetUserByUsername(username, userContext)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return &tpepmodels.User{
+		ID:         user.ID,
+		Email:      user.Email,
+		TimeJoined: user.TimeJoined,
+	}, nil
+}
@@ -0,0 +1,112 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+package thirdpartyemailpassword
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/thirdpartyemailpassword/tpepmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSignInWithUsername(t *testing.T) {
+	usernames := map[string]string{}
+	configValue := supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{
+			ConnectionURI: "http://localhost:8080",
+		},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			Init(&tpepmodels.TypeInput{
+				UsernameFeature: &epmodels.TypeInputUsernameFeature{
+					GetUserIDByUsername: func(username string, userContext supertokens.UserContext) (*string, error) {
+						userID, ok := usernames[username]
+						if !ok || userID == "" {
+							return nil, nil
+						}
+						return &userID, nil
+					},
+					ReserveUsername: func(username string, userContext supertokens.UserContext) (bool, error) {
+						if _, ok := usernames[username]; ok {
+							return false, nil
+						}
+						usernames[username] = ""
+						return true, nil
+					},
+					SetUsername: func(userID string, username string, userContext supertokens.UserContext) error {
+						usernames[username] = userID
+						return nil
+					},
+					ReleaseUsername: func(username string, userContext supertokens.UserContext) error {
+						delete(usernames, username)
+						return nil
+					},
+				},
+			}),
+			session.Init(nil),
+		},
+	}
+
+	BeforeEach()
+	unittesting.StartUpST("localhost", "8080")
+	defer AfterEach()
+	err := supertokens.Init(configValue)
+	if err != nil {
+		t.Error(err.Error())
+	}
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	defer testServer.Close()
+
+	postBody, err := json.Marshal(map[string]interface{}{
+		"formFields": []map[string]interface{}{
+			{"id": "username", "value": "Player_One"},
+			{"id": "email", "value": "random@gmail.com"},
+			{"id": "password", "value": "validpass123"},
+		},
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+	resp, err := http.Post(testServer.URL+"/auth/signup", "application/json", bytes.NewBuffer(postBody))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	user, err := GetUserByUsername("player_one")
+	assert.NoError(t, err)
+	assert.Equal(t, "random@gmail.com", user.Email)
+	assert.Equal(t, user.ID, usernames["player_one"])
+
+	signInResponse, err := EmailPasswordSignIn("PLAYER_ONE", "validpass123")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, signInResponse.OK.User.ID)
+
+	signInResponse, err = EmailPasswordSignIn("player_two", "validpass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+}
@@ -40,6 +40,22 @@ func validateAndNormaliseUserInput(recipeInstance *Recipe, appInfo supertokens.N
 		typeNormalisedInput.ResetPasswordUsingTokenFeature = config.ResetPasswordUsingTokenFeature
 	}
 
+	if config != nil && config.PasswordPolicy != nil {
+		typeNormalisedInput.PasswordPolicy = config.PasswordPolicy
+	}
+
+	if config != nil && config.EmailPolicy != nil {
+		typeNormalisedInput.EmailPolicy = config.EmailPolicy
+	}
+
+	if config != nil && config.UsernameFeature != nil {
+		typeNormalisedInput.UsernameFeature = config.UsernameFeature
+	}
+
+	if config != nil && config.BruteForceProtection != nil {
+		typeNormalisedInput.BruteForceProtection = config.BruteForceProtection
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -60,6 +76,10 @@ func makeTypeNormalisedInput(recipeInstance *Recipe) tpepmodels.TypeNormalisedIn
 		SignUpFeature:                  nil,
 		Providers:                      nil,
 		ResetPasswordUsingTokenFeature: nil,
+		PasswordPolicy:                 nil,
+		EmailPolicy:                    nil,
+		UsernameFeature:                nil,
+		BruteForceProtection:           nil,
 		EmailVerificationFeature:       validateAndNormaliseEmailVerificationConfig(recipeInstance, nil),
 		Override: tpepmodels.OverrideStruct{
 			Functions: func(originalImplementation tpepmodels.RecipeInterface) tpepmodels.RecipeInterface {
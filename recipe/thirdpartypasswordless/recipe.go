@@ -64,7 +64,18 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config t
 			return Recipe{}, err
 		}
 
-		r.RecipeImpl = verifiedConfig.Override.Functions(recipeimplementation.MakeRecipeImplementation(*passwordlessquerierInstance, thirdpartyquerierInstance))
+		recipeImplementation := recipeimplementation.MakeRecipeImplementation(*passwordlessquerierInstance, thirdpartyquerierInstance)
+		if verifiedConfig.EmailPolicy != nil {
+			emailPolicy, err := supertokens.NormaliseEmailPolicyOrThrowError(verifiedConfig.EmailPolicy)
+			if err != nil {
+				return Recipe{}, err
+			}
+			passwordlessImplementation := passwordless.MakeEmailPolicyRecipeImplementation(recipeimplementation.MakePasswordlessRecipeImplementation(recipeImplementation), emailPolicy)
+			recipeImplementation = recipeimplementation.WithPasswordlessRecipeImplementation(recipeImplementation, passwordlessImplementation)
+			thirdPartyImplementation := thirdparty.MakeEmailPolicyRecipeImplementation(recipeimplementation.MakeThirdPartyRecipeImplementation(recipeImplementation), emailPolicy)
+			recipeImplementation = recipeimplementation.WithThirdPartyRecipeImplementation(recipeImplementation, thirdPartyImplementation)
+		}
+		r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 	}
 	r.APIImpl = verifiedConfig.Override.APIs(api.MakeAPIImplementation())
 
@@ -139,6 +150,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config t
 			FlowType:                  verifiedConfig.FlowType,
 			GetLinkDomainAndPath:      verifiedConfig.GetLinkDomainAndPath,
 			GetCustomUserInputCode:    verifiedConfig.GetCustomUserInputCode,
+			EmailPolicy:               verifiedConfig.EmailPolicy,
 			Override: &plessmodels.OverrideStruct{
 				Functions: func(originalImplementation plessmodels.RecipeInterface) plessmodels.RecipeInterface {
 					return recipeimplementation.MakePasswordlessRecipeImplementation(r.RecipeImpl)
@@ -163,6 +175,7 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config t
 				SignInAndUpFeature: tpmodels.TypeInputSignInAndUp{
 					Providers: verifiedConfig.Providers,
 				},
+				EmailPolicy: verifiedConfig.EmailPolicy,
 				Override: &tpmodels.OverrideStruct{
 					Functions: func(_ tpmodels.RecipeInterface) tpmodels.RecipeInterface {
 						return recipeimplementation.MakeThirdPartyRecipeImplementation(r.RecipeImpl)
@@ -179,3 +179,109 @@ func MakePasswordlessRecipeImplementation(recipeImplementation tplmodels.RecipeI
 		UpdateUser:                  &updateUser,
 	}
 }
+
+// WithPasswordlessRecipeImplementation is the reverse of MakePasswordlessRecipeImplementation: it replaces the
+// passwordless functions of recipeImplementation with those of passwordlessImplementation, so that the features
+// of the passwordless recipe also apply to the passwordless users of this recipe
+func WithPasswordlessRecipeImplementation(recipeImplementation tplmodels.RecipeInterface, passwordlessImplementation plessmodels.RecipeInterface) tplmodels.RecipeInterface {
+	result := recipeImplementation
+	originalGetUsersByEmail := *recipeImplementation.GetUsersByEmail
+
+	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tplmodels.User, error) {
+		users, err := originalGetUsersByEmail(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		passwordlessUser, err := (*passwordlessImplementation.GetUserByEmail)(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		finalResult := []tplmodels.User{}
+		if passwordlessUser != nil {
+			finalResult = append(finalResult, getUserFromPasswordlessUser(*passwordlessUser))
+		}
+		for _, user := range users {
+			if user.ThirdParty != nil {
+				finalResult = append(finalResult, user)
+			}
+		}
+		return finalResult, nil
+	}
+
+	getUserByPhoneNumber := func(phoneNumber string, userContext supertokens.UserContext) (*tplmodels.User, error) {
+		user, err := (*passwordlessImplementation.GetUserByPhoneNumber)(phoneNumber, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, nil
+		}
+		tplUser := getUserFromPasswordlessUser(*user)
+		return &tplUser, nil
+	}
+
+	consumeCode := func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, userContext supertokens.UserContext) (tplmodels.ConsumeCodeResponse, error) {
+		resp, err := (*passwordlessImplementation.ConsumeCode)(userInput, linkCode, preAuthSessionID, userContext)
+		if err != nil {
+			return tplmodels.ConsumeCodeResponse{}, err
+		}
+
+		if resp.ExpiredUserInputCodeError != nil {
+			return tplmodels.ConsumeCodeResponse{
+				ExpiredUserInputCodeError: resp.ExpiredUserInputCodeError,
+			}, nil
+		} else if resp.IncorrectUserInputCodeError != nil {
+			return tplmodels.ConsumeCodeResponse{
+				IncorrectUserInputCodeError: resp.IncorrectUserInputCodeError,
+			}, nil
+		} else if resp.RestartFlowError != nil {
+			return tplmodels.ConsumeCodeResponse{
+				RestartFlowError: &struct{}{},
+			}, nil
+		} else {
+			return tplmodels.ConsumeCodeResponse{
+				OK: &struct {
+					CreatedNewUser bool
+					User           tplmodels.User
+				}{
+					CreatedNewUser: resp.OK.CreatedNewUser,
+					User:           getUserFromPasswordlessUser(resp.OK.User),
+				},
+			}, nil
+		}
+	}
+
+	createCode := *passwordlessImplementation.CreateCode
+	createNewCodeForDevice := *passwordlessImplementation.CreateNewCodeForDevice
+	updatePasswordlessUser := *passwordlessImplementation.UpdateUser
+	revokeAllCodes := *passwordlessImplementation.RevokeAllCodes
+	revokeCode := *passwordlessImplementation.RevokeCode
+	listCodesByEmail := *passwordlessImplementation.ListCodesByEmail
+	listCodesByPhoneNumber := *passwordlessImplementation.ListCodesByPhoneNumber
+	listCodesByDeviceID := *passwordlessImplementation.ListCodesByDeviceID
+	listCodesByPreAuthSessionID := *passwordlessImplementation.ListCodesByPreAuthSessionID
+
+	result.GetUsersByEmail = &getUsersByEmail
+	result.GetUserByPhoneNumber = &getUserByPhoneNumber
+	result.CreateCode = &createCode
+	result.CreateNewCodeForDevice = &createNewCodeForDevice
+	result.ConsumeCode = &consumeCode
+	result.UpdatePasswordlessUser = &updatePasswordlessUser
+	result.RevokeAllCodes = &revokeAllCodes
+	result.RevokeCode = &revokeCode
+	result.ListCodesByEmail = &listCodesByEmail
+	result.ListCodesByPhoneNumber = &listCodesByPhoneNumber
+	result.ListCodesByDeviceID = &listCodesByDeviceID
+	result.ListCodesByPreAuthSessionID = &listCodesByPreAuthSessionID
+	return result
+}
+
+func getUserFromPasswordlessUser(user plessmodels.User) tplmodels.User {
+	return tplmodels.User{
+		ID:          user.ID,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		TimeJoined:  user.TimeJoined,
+	}
+}
@@ -115,3 +115,92 @@ func MakeThirdPartyRecipeImplementation(recipeImplementation tplmodels.RecipeInt
 		SignInUp:                &signInUp,
 	}
 }
+
+// WithThirdPartyRecipeImplementation is the reverse of MakeThirdPartyRecipeImplementation: it replaces the
+// third party functions of recipeImplementation with those of thirdPartyImplementation, so that the features
+// of the thirdparty recipe also apply to the third party users of this recipe
+func WithThirdPartyRecipeImplementation(recipeImplementation tplmodels.RecipeInterface, thirdPartyImplementation tpmodels.RecipeInterface) tplmodels.RecipeInterface {
+	result := recipeImplementation
+	originalGetUsersByEmail := *recipeImplementation.GetUsersByEmail
+
+	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tplmodels.User, error) {
+		users, err := originalGetUsersByEmail(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+		thirdPartyUsers, err := (*thirdPartyImplementation.GetUsersByEmail)(email, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		finalResult := []tplmodels.User{}
+		for _, user := range users {
+			if user.ThirdParty == nil {
+				finalResult = append(finalResult, user)
+			}
+		}
+		for _, tpUser := range thirdPartyUsers {
+			finalResult = append(finalResult, getUserFromThirdPartyUser(tpUser))
+		}
+		return finalResult, nil
+	}
+
+	getUserByThirdPartyInfo := func(thirdPartyID string, thirdPartyUserID string, userContext supertokens.UserContext) (*tplmodels.User, error) {
+		user, err := (*thirdPartyImplementation.GetUserByThirdPartyInfo)(thirdPartyID, thirdPartyUserID, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, nil
+		}
+		tplUser := getUserFromThirdPartyUser(*user)
+		return &tplUser, nil
+	}
+
+	thirdPartySignInUp := func(thirdPartyID string, thirdPartyUserID string, email tplmodels.EmailStruct, userContext supertokens.UserContext) (tplmodels.ThirdPartySignInUp, error) {
+		response, err := (*thirdPartyImplementation.SignInUp)(thirdPartyID, thirdPartyUserID, tpmodels.EmailStruct{
+			ID:         email.ID,
+			IsVerified: email.IsVerified,
+		}, userContext)
+		if err != nil {
+			return tplmodels.ThirdPartySignInUp{}, err
+		}
+		if response.FieldError != nil {
+			return tplmodels.ThirdPartySignInUp{
+				FieldError: &struct{ ErrorMsg string }{
+					ErrorMsg: response.FieldError.ErrorMsg,
+				},
+			}, nil
+		}
+		return tplmodels.ThirdPartySignInUp{
+			OK: &struct {
+				CreatedNewUser bool
+				User           tplmodels.User
+			}{
+				CreatedNewUser: response.OK.CreatedNewUser,
+				User:           getUserFromThirdPartyUser(response.OK.User),
+			},
+		}, nil
+	}
+
+	result.GetUsersByEmail = &getUsersByEmail
+	result.GetUserByThirdPartyInfo = &getUserByThirdPartyInfo
+	result.ThirdPartySignInUp = &thirdPartySignInUp
+	return result
+}
+
+func getUserFromThirdPartyUser(user tpmodels.User) tplmodels.User {
+	email := user.Email
+	return tplmodels.User{
+		ID:         user.ID,
+		Email:      &email,
+		TimeJoined: user.TimeJoined,
+		ThirdParty: &struct {
+			ID     string
+			UserID string
+		}{
+			ID:     user.ThirdParty.ID,
+			UserID: user.ThirdParty.UserID,
+		},
+	}
+}
@@ -47,6 +47,7 @@ type TypeInput struct {
 	GetCustomUserInputCode    func(userContext supertokens.UserContext) (string, error)
 	Providers                 []tpmodels.TypeProvider
 	EmailVerificationFeature  *TypeInputEmailVerificationFeature
+	EmailPolicy               *supertokens.EmailPolicy
 	Override                  *OverrideStruct
 }
 
@@ -59,6 +60,7 @@ type TypeNormalisedInput struct {
 	GetCustomUserInputCode    func(userContext supertokens.UserContext) (string, error)
 	Providers                 []tpmodels.TypeProvider
 	EmailVerificationFeature  evmodels.TypeInput
+	EmailPolicy               *supertokens.EmailPolicy
 	Override                  OverrideStruct
 }
 
@@ -53,6 +53,7 @@ func makeTypeNormalisedInput(recipeInstance *Recipe, inputConfig tplmodels.TypeI
 		GetLinkDomainAndPath:      inputConfig.GetLinkDomainAndPath,
 		GetCustomUserInputCode:    inputConfig.GetCustomUserInputCode,
 		EmailVerificationFeature:  validateAndNormaliseEmailVerificationConfig(recipeInstance, inputConfig),
+		EmailPolicy:               inputConfig.EmailPolicy,
 		Override: tplmodels.OverrideStruct{
 			Functions: func(originalImplementation tplmodels.RecipeInterface) tplmodels.RecipeInterface {
 				return originalImplementation
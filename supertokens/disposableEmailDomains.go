@@ -0,0 +1,72 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+// disposableEmailDomains are the domains of well known disposable email services. Apps that need
+// a complete list can add the domains to EmailPolicy.BlockedDomains
+var disposableEmailDomains = map[string]bool{
+	"10minutemail.com":       true,
+	"10minutemail.net":       true,
+	"20minutemail.com":       true,
+	"33mail.com":             true,
+	"anonbox.net":            true,
+	"burnermail.io":          true,
+	"discard.email":          true,
+	"dispostable.com":        true,
+	"dropmail.me":            true,
+	"emailondeck.com":        true,
+	"fakeinbox.com":          true,
+	"fakemail.net":           true,
+	"getairmail.com":         true,
+	"getnada.com":            true,
+	"guerrillamail.biz":      true,
+	"guerrillamail.com":      true,
+	"guerrillamail.de":       true,
+	"guerrillamail.info":     true,
+	"guerrillamail.net":      true,
+	"guerrillamail.org":      true,
+	"guerrillamailblock.com": true,
+	"harakirimail.com":       true,
+	"inboxkitten.com":        true,
+	"jetable.org":            true,
+	"mailcatch.com":          true,
+	"maildrop.cc":            true,
+	"mailinator.com":         true,
+	"mailinator.net":         true,
+	"mailnesia.com":          true,
+	"mintemail.com":          true,
+	"mohmal.com":             true,
+	"moakt.com":              true,
+	"mytemp.email":           true,
+	"nada.email":             true,
+	"sharklasers.com":        true,
+	"spam4.me":               true,
+	"spamgourmet.com":        true,
+	"temp-mail.io":           true,
+	"temp-mail.org":          true,
+	"tempail.com":            true,
+	"tempmail.dev":           true,
+	"tempmail.net":           true,
+	"tempmailo.com":          true,
+	"tempr.email":            true,
+	"throwawaymail.com":      true,
+	"trashmail.com":          true,
+	"trashmail.de":           true,
+	"trashmail.net":          true,
+	"yopmail.com":            true,
+	"yopmail.fr":             true,
+	"yopmail.net":            true,
+}
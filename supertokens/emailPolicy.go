@@ -0,0 +1,246 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+func NormaliseEmailPolicyOrThrowError(policy *EmailPolicy) (NormalisedEmailPolicy, error) {
+	if policy == nil {
+		return NormalisedEmailPolicy{}, nil
+	}
+	allowedDomains, err := normaliseEmailPolicyDomains(policy.AllowedDomains)
+	if err != nil {
+		return NormalisedEmailPolicy{}, err
+	}
+	blockedDomains, err := normaliseEmailPolicyDomains(policy.BlockedDomains)
+	if err != nil {
+		return NormalisedEmailPolicy{}, err
+	}
+	if policy.StripProviderAliases && (policy.GetEmailByCanonicalEmail == nil || policy.SetCanonicalEmail == nil) {
+		return NormalisedEmailPolicy{}, errors.New("please provide GetEmailByCanonicalEmail and SetCanonicalEmail in the email policy to strip provider aliases")
+	}
+	return NormalisedEmailPolicy{
+		Enabled:                  true,
+		CaseSensitiveLocalPart:   policy.CaseSensitiveLocalPart,
+		StripProviderAliases:     policy.StripProviderAliases,
+		GetEmailByCanonicalEmail: policy.GetEmailByCanonicalEmail,
+		SetCanonicalEmail:        policy.SetCanonicalEmail,
+		AllowedDomains:           allowedDomains,
+		BlockedDomains:           blockedDomains,
+		BlockDisposableDomains:   policy.BlockDisposableDomains,
+	}, nil
+}
+
+func normaliseEmailPolicyDomains(domains []string) ([]string, error) {
+	normalisedDomains := []string{}
+	for _, domain := range domains {
+		normalisedDomain, err := normaliseEmailDomain(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if err != nil || normalisedDomain == "" {
+			return nil, errors.New("email policy domain " + domain + " is invalid")
+		}
+		normalisedDomains = append(normalisedDomains, normalisedDomain)
+	}
+	return normalisedDomains, nil
+}
+
+// normaliseEmailDomain lower cases the domain, and converts internationalised domains to punycode
+// so that both of their forms are the same domain
+func normaliseEmailDomain(domain string) (string, error) {
+	return idna.Lookup.ToASCII(strings.TrimSuffix(strings.ToLower(domain), "."))
+}
+
+// NormaliseEmail returns the form of the email that is stored. Without a policy, it only trims the email
+func NormaliseEmail(policy NormalisedEmailPolicy, email string) string {
+	localPart, domain, ok := normaliseEmailParts(policy, email)
+	if !ok {
+		return strings.TrimSpace(email)
+	}
+	return localPart + "@" + domain
+}
+
+// GetCanonicalEmail returns the form of the email that users are looked up by. It is the normalised
+// email, without the aliases of its provider if the policy strips them
+func GetCanonicalEmail(policy NormalisedEmailPolicy, email string) string {
+	localPart, domain, ok := normaliseEmailParts(policy, email)
+	if !ok {
+		return strings.TrimSpace(email)
+	}
+	if policy.StripProviderAliases {
+		localPart, domain = stripEmailProviderAliases(localPart, domain)
+	}
+	return localPart + "@" + domain
+}
+
+func normaliseEmailParts(policy NormalisedEmailPolicy, email string) (string, string, bool) {
+	email = strings.TrimSpace(email)
+	if !policy.Enabled {
+		return "", "", false
+	}
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return "", "", false
+	}
+	localPart := email[:at]
+	domain, err := normaliseEmailDomain(email[at+1:])
+	if err != nil {
+		// the email validators reject this email, so we leave it as it is
+		domain = strings.ToLower(email[at+1:])
+	}
+	if !policy.CaseSensitiveLocalPart {
+		localPart = strings.ToLower(localPart)
+	}
+	return localPart, domain, true
+}
+
+// GetEmailsToLookUp returns the emails that a user with this email may be stored under, from the most
+// to the least likely: the email stored for its canonical form, the normalised email, and the email as
+// it was entered, for users who signed up before the policy was enabled
+func GetEmailsToLookUp(policy NormalisedEmailPolicy, email string, userContext UserContext) ([]string, error) {
+	emails := []string{}
+	addEmail := func(email string) {
+		for _, existingEmail := range emails {
+			if existingEmail == email {
+				return
+			}
+		}
+		emails = append(emails, email)
+	}
+	if policy.StripProviderAliases {
+		storedEmail, err := policy.GetEmailByCanonicalEmail(GetCanonicalEmail(policy, email), userContext)
+		if err != nil {
+			return nil, err
+		}
+		if storedEmail != nil {
+			addEmail(*storedEmail)
+		}
+	}
+	addEmail(NormaliseEmail(policy, email))
+	addEmail(strings.TrimSpace(email))
+	return emails, nil
+}
+
+// FindStoredEmail returns the first of the emails to look up that exists returns true for. If there
+// is none, it returns the normalised email and false
+func FindStoredEmail(policy NormalisedEmailPolicy, email string, exists func(email string) (bool, error), userContext UserContext) (string, bool, error) {
+	emails, err := GetEmailsToLookUp(policy, email, userContext)
+	if err != nil {
+		return "", false, err
+	}
+	for _, emailToLookUp := range emails {
+		found, err := exists(emailToLookUp)
+		if err != nil {
+			return "", false, err
+		}
+		if found {
+			return emailToLookUp, true, nil
+		}
+	}
+	return NormaliseEmail(policy, email), false, nil
+}
+
+// StoreCanonicalEmail lets a user who was stored under this email be looked up by its canonical form
+func StoreCanonicalEmail(policy NormalisedEmailPolicy, email string, userContext UserContext) error {
+	if !policy.StripProviderAliases {
+		return nil
+	}
+	return policy.SetCanonicalEmail(GetCanonicalEmail(policy, email), email, userContext)
+}
+
+// the providers that ignore everything after a + in the local part
+var plusAliasEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"mac.com":        true,
+	"fastmail.com":   true,
+	"protonmail.com": true,
+	"proton.me":      true,
+}
+
+func stripEmailProviderAliases(localPart string, domain string) (string, string) {
+	if !plusAliasEmailDomains[domain] || strings.HasPrefix(localPart, "\"") {
+		return localPart, domain
+	}
+	if plus := strings.Index(localPart, "+"); plus > 0 {
+		localPart = localPart[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		// gmail also ignores dots and case, and googlemail.com is the same mailbox as gmail.com
+		localPart = strings.ToLower(strings.ReplaceAll(localPart, ".", ""))
+		domain = "gmail.com"
+	}
+	return localPart, domain
+}
+
+// ValidateEmailDomain returns an error message if the policy does not allow the domain of the email to sign up
+func ValidateEmailDomain(policy NormalisedEmailPolicy, email string) *string {
+	if !policy.Enabled {
+		return nil
+	}
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return nil
+	}
+	domain, err := normaliseEmailDomain(email[at+1:])
+	if err != nil {
+		msg := "Email is invalid"
+		return &msg
+	}
+	if len(policy.AllowedDomains) > 0 && !isEmailDomainInList(domain, policy.AllowedDomains) {
+		msg := "Email domain is not allowed"
+		return &msg
+	}
+	if isEmailDomainInList(domain, policy.BlockedDomains) {
+		msg := "Email domain is not allowed"
+		return &msg
+	}
+	if policy.BlockDisposableDomains && isDisposableEmailDomain(domain) {
+		msg := "Disposable email addresses are not allowed"
+		return &msg
+	}
+	return nil
+}
+
+func isEmailDomainInList(domain string, domains []string) bool {
+	for _, listedDomain := range domains {
+		if domain == listedDomain || strings.HasSuffix(domain, "."+listedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDisposableEmailDomain(domain string) bool {
+	for {
+		if disposableEmailDomains[domain] {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot == -1 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
@@ -0,0 +1,130 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormaliseEmailWithoutPolicy(t *testing.T) {
+	policy, err := NormaliseEmailPolicyOrThrowError(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Foo@Example.com", NormaliseEmail(policy, " Foo@Example.com "))
+	assert.Nil(t, ValidateEmailDomain(policy, "foo@mailinator.com"))
+}
+
+func TestNormaliseEmailWithPolicy(t *testing.T) {
+	policy, err := NormaliseEmailPolicyOrThrowError(&EmailPolicy{})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo@example.com", NormaliseEmail(policy, " Foo@Example.COM "))
+	assert.Equal(t, "j.o.h.n+news@gmail.com", NormaliseEmail(policy, "J.O.H.N+news@gmail.com"))
+	assert.Equal(t, "foo@xn--bcher-kva.de", NormaliseEmail(policy, "foo@Bücher.de"))
+	assert.Equal(t, "foo@xn--bcher-kva.de", NormaliseEmail(policy, "foo@xn--bcher-kva.de"))
+
+	policy, err = NormaliseEmailPolicyOrThrowError(&EmailPolicy{CaseSensitiveLocalPart: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Foo@example.com", NormaliseEmail(policy, "Foo@Example.com"))
+}
+
+func getEmailPolicyWithCanonicalEmailsForTests(canonicalEmails map[string]string) *EmailPolicy {
+	return &EmailPolicy{
+		StripProviderAliases: true,
+		GetEmailByCanonicalEmail: func(canonicalEmail string, userContext UserContext) (*string, error) {
+			email, ok := canonicalEmails[canonicalEmail]
+			if !ok {
+				return nil, nil
+			}
+			return &email, nil
+		},
+		SetCanonicalEmail: func(canonicalEmail string, email string, userContext UserContext) error {
+			canonicalEmails[canonicalEmail] = email
+			return nil
+		},
+	}
+}
+
+func TestGetCanonicalEmailStripsProviderAliases(t *testing.T) {
+	policy, err := NormaliseEmailPolicyOrThrowError(getEmailPolicyWithCanonicalEmailsForTests(map[string]string{}))
+	assert.NoError(t, err)
+	assert.Equal(t, "john@gmail.com", GetCanonicalEmail(policy, "J.o.h.n+news@googlemail.com"))
+	assert.Equal(t, "john@outlook.com", GetCanonicalEmail(policy, "john+news@outlook.com"))
+	assert.Equal(t, "j.ohn@outlook.com", GetCanonicalEmail(policy, "j.ohn@outlook.com"))
+	assert.Equal(t, "j.ohn+news@example.com", GetCanonicalEmail(policy, "j.ohn+news@example.com"))
+	assert.Equal(t, "+news@gmail.com", GetCanonicalEmail(policy, "+news@gmail.com"))
+
+	// the aliases are only removed from the lookup key, not from the stored email
+	assert.Equal(t, "j.o.h.n+news@googlemail.com", NormaliseEmail(policy, "J.o.h.n+news@googlemail.com"))
+
+	_, err = NormaliseEmailPolicyOrThrowError(&EmailPolicy{StripProviderAliases: true})
+	assert.Error(t, err)
+}
+
+func TestGetEmailsToLookUp(t *testing.T) {
+	canonicalEmails := map[string]string{}
+	policy, err := NormaliseEmailPolicyOrThrowError(getEmailPolicyWithCanonicalEmailsForTests(canonicalEmails))
+	assert.NoError(t, err)
+
+	emails, err := GetEmailsToLookUp(policy, " J.Doe+x@Gmail.com ", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"j.doe+x@gmail.com", "J.Doe+x@Gmail.com"}, emails)
+
+	assert.NoError(t, StoreCanonicalEmail(policy, "j.doe+x@gmail.com", &map[string]interface{}{}))
+	assert.Equal(t, map[string]string{"jdoe@gmail.com": "j.doe+x@gmail.com"}, canonicalEmails)
+	emails, err = GetEmailsToLookUp(policy, "jdoe@gmail.com", &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"j.doe+x@gmail.com", "jdoe@gmail.com"}, emails)
+
+	// users stored before the policy was enabled are found by the email as it was entered
+	storedEmails := map[string]bool{"Foo@Example.com": true}
+	exists := func(email string) (bool, error) {
+		return storedEmails[email], nil
+	}
+	email, found, err := FindStoredEmail(policy, "Foo@Example.com", exists, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Foo@Example.com", email)
+
+	email, found, err = FindStoredEmail(policy, "Bar@Example.com", exists, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, "bar@example.com", email)
+}
+
+func TestValidateEmailDomain(t *testing.T) {
+	policy, err := NormaliseEmailPolicyOrThrowError(&EmailPolicy{
+		AllowedDomains: []string{"Example.com", "@bücher.de"},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, ValidateEmailDomain(policy, "foo@example.com"))
+	assert.Nil(t, ValidateEmailDomain(policy, "foo@eu.example.com"))
+	assert.Nil(t, ValidateEmailDomain(policy, "foo@xn--bcher-kva.de"))
+	assert.Equal(t, "Email domain is not allowed", *ValidateEmailDomain(policy, "foo@notexample.com"))
+
+	policy, err = NormaliseEmailPolicyOrThrowError(&EmailPolicy{
+		BlockedDomains:         []string{"competitor.com"},
+		BlockDisposableDomains: true,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, ValidateEmailDomain(policy, "foo@example.com"))
+	assert.Equal(t, "Email domain is not allowed", *ValidateEmailDomain(policy, "foo@mail.competitor.com"))
+	assert.Equal(t, "Disposable email addresses are not allowed", *ValidateEmailDomain(policy, "foo@Mailinator.com"))
+	assert.Equal(t, "Disposable email addresses are not allowed", *ValidateEmailDomain(policy, "foo@inbox.yopmail.com"))
+
+	_, err = NormaliseEmailPolicyOrThrowError(&EmailPolicy{BlockedDomains: []string{" "}})
+	assert.Error(t, err)
+}
@@ -16,7 +16,9 @@
 package supertokens
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 )
 
 func Init(config TypeInput) error {
@@ -62,3 +64,101 @@ func GetUsersNewestFirst(paginationToken *string, limit *int, includeRecipeIds *
 func DeleteUser(userId string) error {
 	return deleteUser(userId)
 }
+
+// CreateInvitationWithContext creates a single use invitation for the email, sends it with the
+// CreateAndSendCustomEmail function of the SignUpGate invitations config, and returns its token
+func CreateInvitationWithContext(email string, metadata map[string]interface{}, roles []string, userContext UserContext) (string, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return "", err
+	}
+	invitation := Invitation{
+		Email:    strings.TrimSpace(email),
+		Metadata: metadata,
+		Roles:    roles,
+	}
+	return createInvitation(instance.SignUpGate.Invitations, invitation, getCurrTimeInMS(), userContext)
+}
+
+// CreatePhoneNumberInvitationWithContext creates a single use invitation for the phone number, which can be
+// redeemed by signing up with passwordless. It is sent with the CreateAndSendCustomSms function of the
+// SignUpGate invitations config. The phone number must be in the format the passwordless recipe stores (E.164)
+func CreatePhoneNumberInvitationWithContext(phoneNumber string, metadata map[string]interface{}, roles []string, userContext UserContext) (string, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return "", err
+	}
+	invitation := Invitation{
+		PhoneNumber: strings.TrimSpace(phoneNumber),
+		Metadata:    metadata,
+		Roles:       roles,
+	}
+	return createInvitation(instance.SignUpGate.Invitations, invitation, getCurrTimeInMS(), userContext)
+}
+
+func RevokeInvitationWithContext(inviteToken string, userContext UserContext) error {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	if !instance.SignUpGate.Invitations.Enabled {
+		return errors.New("please provide signUpGate invitations when initialising SuperTokens to revoke invitations")
+	}
+	return instance.SignUpGate.Invitations.RemoveInvitation(hashInviteToken(inviteToken), userContext)
+}
+
+// CheckSignUpAllowed is called by the sign up APIs of the recipes to fail early. It returns an error message
+// if the SignUpGate does not let the user sign up. email and phoneNumber are nil for users without one.
+func CheckSignUpAllowed(email *string, phoneNumber *string, inviteToken *string, userContext UserContext) (*string, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return checkSignUpAllowed(instance.SignUpGate, email, phoneNumber, inviteToken, getCurrTimeInMS(), userContext)
+}
+
+// ConsumeInvitation is called by the sign up APIs of the recipes right before a new user is created. It
+// removes the invitation for the token, so that it cannot be used by another sign up, and returns it. It
+// returns an error message instead if the SignUpGate does not let the user sign up.
+//
+// The invitation has to be passed to RedeemInvitation once the user is created, or to RestoreInvitation
+// if no user was created.
+func ConsumeInvitation(email *string, phoneNumber *string, inviteToken *string, userContext UserContext) (*Invitation, *string, error) {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return nil, nil, err
+	}
+	return consumeInvitation(instance.SignUpGate, email, phoneNumber, inviteToken, getCurrTimeInMS(), userContext)
+}
+
+// RedeemInvitation calls OnInvitationRedeemed for the invitation returned by ConsumeInvitation. It does
+// nothing if the invitation is nil
+func RedeemInvitation(userID string, invitation *Invitation, userContext UserContext) error {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	return redeemInvitation(instance.SignUpGate.Invitations, userID, invitation, userContext)
+}
+
+// RestoreInvitation saves the invitation returned by ConsumeInvitation again, so that it can still be used
+// when the sign up did not create a user. It does nothing if the invitation is nil
+func RestoreInvitation(invitation *Invitation, inviteToken *string, userContext UserContext) error {
+	instance, err := GetInstanceOrThrowError()
+	if err != nil {
+		return err
+	}
+	return restoreInvitation(instance.SignUpGate.Invitations, invitation, inviteToken, userContext)
+}
+
+func CreateInvitation(email string, metadata map[string]interface{}, roles []string) (string, error) {
+	return CreateInvitationWithContext(email, metadata, roles, &map[string]interface{}{})
+}
+
+func CreatePhoneNumberInvitation(phoneNumber string, metadata map[string]interface{}, roles []string) (string, error) {
+	return CreatePhoneNumberInvitationWithContext(phoneNumber, metadata, roles, &map[string]interface{}{})
+}
+
+func RevokeInvitation(inviteToken string) error {
+	return RevokeInvitationWithContext(inviteToken, &map[string]interface{}{})
+}
@@ -45,6 +45,112 @@ type TypeInput struct {
 	RecipeList     []Recipe
 	Telemetry      *bool
 	OnGeneralError func(err error, req *http.Request, res http.ResponseWriter)
+	SignUpGate     *SignUpGate
+}
+
+// who can sign up through the sign up APIs of the recipes
+const (
+	SignUpModeOpen       = "OPEN"
+	SignUpModeDisabled   = "DISABLED"
+	SignUpModeAllowlist  = "ALLOWLIST"
+	SignUpModeInviteOnly = "INVITE_ONLY"
+)
+
+// SignUpGate controls who can sign up through the sign up APIs of all recipes. Existing users can always
+// sign in, and users can still be created from the backend
+type SignUpGate struct {
+	// Mode is one of the SignUpMode constants, and defaults to SignUpModeOpen
+	Mode string
+	// AllowedEmails and AllowedDomains can sign up in SignUpModeAllowlist. Subdomains of the allowed domains
+	// are allowed as well
+	AllowedEmails  []string
+	AllowedDomains []string
+	// Invitations is required in SignUpModeInviteOnly. In SignUpModeAllowlist, it lets invited users who are
+	// not on the allowlist sign up
+	Invitations *InvitationConfig
+}
+
+type InvitationConfig struct {
+	// ValidityMS defaults to 7 days
+	ValidityMS *uint64
+	// GetInvitationURL defaults to {websiteDomain}{websiteBasePath}/accept-invite
+	GetInvitationURL         func(invitation Invitation, userContext UserContext) (string, error)
+	CreateAndSendCustomEmail func(invitation Invitation, invitationURLWithToken string, userContext UserContext)
+	// CreateAndSendCustomSms is required to invite users by phone number
+	CreateAndSendCustomSms func(invitation Invitation, invitationURLWithToken string, userContext UserContext)
+
+	// storage for the invitations. They are keyed by a hash of their token, so the tokens themselves are not stored
+	SaveInvitation func(tokenHash string, invitation Invitation, userContext UserContext) error
+	GetInvitation  func(tokenHash string, userContext UserContext) (*Invitation, error)
+	// ConsumeInvitation must get and remove the invitation atomically, and return nil if it does not exist. It is
+	// called before a user is created, so that an invitation cannot be used for two concurrent sign ups
+	ConsumeInvitation func(tokenHash string, userContext UserContext) (*Invitation, error)
+	RemoveInvitation  func(tokenHash string, userContext UserContext) error
+
+	// OnInvitationRedeemed is where apps apply the metadata and roles of the invitation to the new user
+	OnInvitationRedeemed func(userID string, invitation Invitation, userContext UserContext) error
+}
+
+// Invitation is tied to either an Email or a PhoneNumber
+type Invitation struct {
+	Email       string
+	PhoneNumber string
+	Metadata    map[string]interface{}
+	Roles       []string
+	ExpiresAt   uint64
+}
+
+type NormalisedSignUpGate struct {
+	Mode           string
+	AllowedEmails  []string
+	AllowedDomains []string
+	Invitations    NormalisedInvitationConfig
+}
+
+type NormalisedInvitationConfig struct {
+	Enabled                  bool
+	ValidityMS               uint64
+	GetInvitationURL         func(invitation Invitation, userContext UserContext) (string, error)
+	CreateAndSendCustomEmail func(invitation Invitation, invitationURLWithToken string, userContext UserContext)
+	CreateAndSendCustomSms   func(invitation Invitation, invitationURLWithToken string, userContext UserContext)
+	SaveInvitation           func(tokenHash string, invitation Invitation, userContext UserContext) error
+	GetInvitation            func(tokenHash string, userContext UserContext) (*Invitation, error)
+	ConsumeInvitation        func(tokenHash string, userContext UserContext) (*Invitation, error)
+	RemoveInvitation         func(tokenHash string, userContext UserContext) error
+	OnInvitationRedeemed     func(userID string, invitation Invitation, userContext UserContext) error
+}
+
+// EmailPolicy is shared by the recipes that identify users by their email, so that the same
+// email is normalised in the same way everywhere
+type EmailPolicy struct {
+	// CaseSensitiveLocalPart keeps the case of the part of the email before the @. The domain is always lower cased
+	CaseSensitiveLocalPart bool
+	// StripProviderAliases removes the dots and +tags that providers like gmail ignore, so that
+	// j.o.h.n+news@gmail.com and john@gmail.com are the same user. Emails are still stored as they were
+	// entered, and GetEmailByCanonicalEmail and SetCanonicalEmail are required to look them up by their
+	// form without aliases
+	StripProviderAliases bool
+	// SetCanonicalEmail stores the email that was entered for the canonical form of an email, replacing any
+	// previous one. GetEmailByCanonicalEmail returns it, or nil if there is none
+	GetEmailByCanonicalEmail func(canonicalEmail string, userContext UserContext) (*string, error)
+	SetCanonicalEmail        func(canonicalEmail string, email string, userContext UserContext) error
+	// AllowedDomains are the only domains that can sign up, if not empty. Their subdomains are allowed as well
+	AllowedDomains []string
+	// BlockedDomains cannot sign up, along with their subdomains
+	BlockedDomains []string
+	// BlockDisposableDomains blocks the domains of well known disposable email services
+	BlockDisposableDomains bool
+}
+
+type NormalisedEmailPolicy struct {
+	Enabled                  bool
+	CaseSensitiveLocalPart   bool
+	StripProviderAliases     bool
+	GetEmailByCanonicalEmail func(canonicalEmail string, userContext UserContext) (*string, error)
+	SetCanonicalEmail        func(canonicalEmail string, email string, userContext UserContext) error
+	AllowedDomains           []string
+	BlockedDomains           []string
+	BlockDisposableDomains   bool
 }
 
 type ConnectionInfo struct {
@@ -0,0 +1,285 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultInvitationValidityMS = uint64(7 * 24 * 60 * 60 * 1000)
+
+const inviteTokenUserContextKey = "_default.inviteToken"
+
+func normaliseSignUpGateOrThrowError(appInfo NormalisedAppinfo, gate *SignUpGate) (NormalisedSignUpGate, error) {
+	if gate == nil {
+		return NormalisedSignUpGate{Mode: SignUpModeOpen}, nil
+	}
+	mode := SignUpModeOpen
+	if gate.Mode != "" {
+		mode = gate.Mode
+	}
+	if mode != SignUpModeOpen && mode != SignUpModeDisabled && mode != SignUpModeAllowlist && mode != SignUpModeInviteOnly {
+		return NormalisedSignUpGate{}, errors.New("signUpGate mode must be one of OPEN, DISABLED, ALLOWLIST or INVITE_ONLY")
+	}
+	allowedEmails := []string{}
+	for _, email := range gate.AllowedEmails {
+		allowedEmails = append(allowedEmails, strings.ToLower(strings.TrimSpace(email)))
+	}
+	allowedDomains, err := normaliseEmailPolicyDomains(gate.AllowedDomains)
+	if err != nil {
+		return NormalisedSignUpGate{}, err
+	}
+
+	invitations := NormalisedInvitationConfig{}
+	if gate.Invitations != nil {
+		invitations, err = normaliseInvitationConfigOrThrowError(appInfo, gate.Invitations)
+		if err != nil {
+			return NormalisedSignUpGate{}, err
+		}
+	} else if mode == SignUpModeInviteOnly {
+		return NormalisedSignUpGate{}, errors.New("please provide signUpGate invitations to use the INVITE_ONLY mode")
+	}
+
+	return NormalisedSignUpGate{
+		Mode:           mode,
+		AllowedEmails:  allowedEmails,
+		AllowedDomains: allowedDomains,
+		Invitations:    invitations,
+	}, nil
+}
+
+func normaliseInvitationConfigOrThrowError(appInfo NormalisedAppinfo, config *InvitationConfig) (NormalisedInvitationConfig, error) {
+	if config.CreateAndSendCustomEmail == nil && config.CreateAndSendCustomSms == nil {
+		return NormalisedInvitationConfig{}, errors.New("please provide signUpGate invitations createAndSendCustomEmail or createAndSendCustomSms to send invitations")
+	}
+	if config.SaveInvitation == nil || config.GetInvitation == nil || config.ConsumeInvitation == nil || config.RemoveInvitation == nil {
+		return NormalisedInvitationConfig{}, errors.New("signUpGate invitations saveInvitation, getInvitation, consumeInvitation and removeInvitation must be provided")
+	}
+	validityMS := defaultInvitationValidityMS
+	if config.ValidityMS != nil {
+		validityMS = *config.ValidityMS
+	}
+	getInvitationURL := defaultGetInvitationURL(appInfo)
+	if config.GetInvitationURL != nil {
+		getInvitationURL = config.GetInvitationURL
+	}
+	return NormalisedInvitationConfig{
+		Enabled:                  true,
+		ValidityMS:               validityMS,
+		GetInvitationURL:         getInvitationURL,
+		CreateAndSendCustomEmail: config.CreateAndSendCustomEmail,
+		CreateAndSendCustomSms:   config.CreateAndSendCustomSms,
+		SaveInvitation:           config.SaveInvitation,
+		GetInvitation:            config.GetInvitation,
+		ConsumeInvitation:        config.ConsumeInvitation,
+		RemoveInvitation:         config.RemoveInvitation,
+		OnInvitationRedeemed:     config.OnInvitationRedeemed,
+	}, nil
+}
+
+func defaultGetInvitationURL(appInfo NormalisedAppinfo) func(invitation Invitation, userContext UserContext) (string, error) {
+	return func(invitation Invitation, userContext UserContext) (string, error) {
+		return appInfo.WebsiteDomain.GetAsStringDangerous() + appInfo.WebsiteBasePath.GetAsStringDangerous() + "/accept-invite", nil
+	}
+}
+
+// SetInviteToken sets the invitation token that is redeemed if a user signs up with this user context. The sign up
+// APIs set it from the inviteToken field in the request body
+func SetInviteToken(userContext UserContext, inviteToken string) {
+	if userContext == nil || inviteToken == "" {
+		return
+	}
+	(*userContext)[inviteTokenUserContextKey] = inviteToken
+}
+
+func GetInviteToken(userContext UserContext) *string {
+	if userContext == nil {
+		return nil
+	}
+	inviteToken, ok := (*userContext)[inviteTokenUserContextKey].(string)
+	if !ok {
+		return nil
+	}
+	return &inviteToken
+}
+
+func hashInviteToken(inviteToken string) string {
+	hash := sha256.Sum256([]byte(inviteToken))
+	return hex.EncodeToString(hash[:])
+}
+
+// createInvitation saves and sends the invitation, which must have either an email or a phone number set
+func createInvitation(config NormalisedInvitationConfig, invitation Invitation, now uint64, userContext UserContext) (string, error) {
+	if !config.Enabled {
+		return "", errors.New("please provide signUpGate invitations when initialising SuperTokens to create invitations")
+	}
+	sendInvitation := config.CreateAndSendCustomEmail
+	if invitation.PhoneNumber != "" {
+		sendInvitation = config.CreateAndSendCustomSms
+		if sendInvitation == nil {
+			return "", errors.New("please provide signUpGate invitations createAndSendCustomSms to invite users by phone number")
+		}
+	} else if sendInvitation == nil {
+		return "", errors.New("please provide signUpGate invitations createAndSendCustomEmail to invite users by email")
+	}
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	inviteToken := base64.RawURLEncoding.EncodeToString(tokenBytes)
+	invitation.ExpiresAt = now + config.ValidityMS
+	err = config.SaveInvitation(hashInviteToken(inviteToken), invitation, userContext)
+	if err != nil {
+		return "", err
+	}
+	invitationURL, err := config.GetInvitationURL(invitation, userContext)
+	if err != nil {
+		return "", err
+	}
+	sendInvitation(invitation, invitationURL+"?token="+url.QueryEscape(inviteToken), userContext)
+	return inviteToken, nil
+}
+
+// isInvitationValidFor returns false if the invitation has expired or it is for another email or phone number
+func isInvitationValidFor(invitation Invitation, email *string, phoneNumber *string, now uint64) bool {
+	if invitation.ExpiresAt < now {
+		return false
+	}
+	if invitation.PhoneNumber != "" {
+		return phoneNumber != nil && strings.TrimSpace(*phoneNumber) == invitation.PhoneNumber
+	}
+	return email != nil && strings.EqualFold(strings.TrimSpace(*email), invitation.Email)
+}
+
+// getValidInvitation returns nil if there is no invitation for the token, or it is not valid for the email or phone number
+func getValidInvitation(config NormalisedInvitationConfig, email *string, phoneNumber *string, inviteToken *string, now uint64, userContext UserContext) (*Invitation, error) {
+	if !config.Enabled || inviteToken == nil {
+		return nil, nil
+	}
+	invitation, err := config.GetInvitation(hashInviteToken(*inviteToken), userContext)
+	if err != nil || invitation == nil {
+		return nil, err
+	}
+	if !isInvitationValidFor(*invitation, email, phoneNumber, now) {
+		return nil, nil
+	}
+	return invitation, nil
+}
+
+// getSignUpNotAllowedMessage returns nil if the user can sign up without an invitation
+func getSignUpNotAllowedMessage(gate NormalisedSignUpGate, email *string) *string {
+	msg := ""
+	switch gate.Mode {
+	case SignUpModeDisabled:
+		msg = "Sign up is disabled"
+	case SignUpModeAllowlist:
+		if email != nil && isEmailOnAllowlist(gate, *email) {
+			return nil
+		}
+		msg = "Sign up is not allowed for this email"
+	case SignUpModeInviteOnly:
+		msg = "A valid invitation is required to sign up"
+	default:
+		return nil
+	}
+	return &msg
+}
+
+// checkSignUpAllowed does not use up the invitation, so it can be called before the sign up APIs do any work
+func checkSignUpAllowed(gate NormalisedSignUpGate, email *string, phoneNumber *string, inviteToken *string, now uint64, userContext UserContext) (*string, error) {
+	msg := getSignUpNotAllowedMessage(gate, email)
+	if msg == nil || gate.Mode == SignUpModeDisabled {
+		return msg, nil
+	}
+	invitation, err := getValidInvitation(gate.Invitations, email, phoneNumber, inviteToken, now, userContext)
+	if err != nil || invitation != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// consumeInvitation removes the invitation for the token, if it is valid for the email or phone number, right
+// before a new user is created. It returns an error message instead if the user needs that invitation to
+// sign up. The invitation has to be passed to restoreInvitation if no user ends up being created.
+func consumeInvitation(gate NormalisedSignUpGate, email *string, phoneNumber *string, inviteToken *string, now uint64, userContext UserContext) (*Invitation, *string, error) {
+	msg := getSignUpNotAllowedMessage(gate, email)
+	if gate.Mode == SignUpModeDisabled {
+		return nil, msg, nil
+	}
+	if !gate.Invitations.Enabled || inviteToken == nil {
+		return nil, msg, nil
+	}
+	tokenHash := hashInviteToken(*inviteToken)
+	invitation, err := gate.Invitations.ConsumeInvitation(tokenHash, userContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invitation == nil {
+		return nil, msg, nil
+	}
+	if !isInvitationValidFor(*invitation, email, phoneNumber, now) {
+		// the invitation belongs to someone else, so it is put back as it was. Expired invitations stay removed
+		if invitation.ExpiresAt >= now {
+			err = gate.Invitations.SaveInvitation(tokenHash, *invitation, userContext)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, msg, nil
+	}
+	return invitation, nil, nil
+}
+
+func restoreInvitation(config NormalisedInvitationConfig, invitation *Invitation, inviteToken *string, userContext UserContext) error {
+	if invitation == nil || inviteToken == nil {
+		return nil
+	}
+	return config.SaveInvitation(hashInviteToken(*inviteToken), *invitation, userContext)
+}
+
+func redeemInvitation(config NormalisedInvitationConfig, userID string, invitation *Invitation, userContext UserContext) error {
+	if invitation == nil || config.OnInvitationRedeemed == nil {
+		return nil
+	}
+	return config.OnInvitationRedeemed(userID, *invitation, userContext)
+}
+
+func isEmailOnAllowlist(gate NormalisedSignUpGate, email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, allowedEmail := range gate.AllowedEmails {
+		if email == allowedEmail {
+			return true
+		}
+	}
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return false
+	}
+	domain, err := normaliseEmailDomain(email[at+1:])
+	return err == nil && isEmailDomainInList(domain, gate.AllowedDomains)
+}
+
+func getCurrTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / 1000000)
+}
@@ -0,0 +1,293 @@
+/* Copyright (c) 2021, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getSignUpGateAppInfoForTests(t *testing.T) NormalisedAppinfo {
+	appInfo, err := NormaliseInputAppInfoOrThrowError(AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "https://api.supertokens.io",
+		WebsiteDomain: "https://supertokens.io",
+	})
+	assert.NoError(t, err)
+	return appInfo
+}
+
+func makeInMemoryInvitationConfig(invitations map[string]Invitation, sentURLs *[]string) *InvitationConfig {
+	return &InvitationConfig{
+		CreateAndSendCustomEmail: func(invitation Invitation, invitationURLWithToken string, userContext UserContext) {
+			*sentURLs = append(*sentURLs, invitationURLWithToken)
+		},
+		CreateAndSendCustomSms: func(invitation Invitation, invitationURLWithToken string, userContext UserContext) {
+			*sentURLs = append(*sentURLs, invitationURLWithToken)
+		},
+		SaveInvitation: func(tokenHash string, invitation Invitation, userContext UserContext) error {
+			invitations[tokenHash] = invitation
+			return nil
+		},
+		GetInvitation: func(tokenHash string, userContext UserContext) (*Invitation, error) {
+			invitation, ok := invitations[tokenHash]
+			if !ok {
+				return nil, nil
+			}
+			return &invitation, nil
+		},
+		ConsumeInvitation: func(tokenHash string, userContext UserContext) (*Invitation, error) {
+			invitation, ok := invitations[tokenHash]
+			if !ok {
+				return nil, nil
+			}
+			delete(invitations, tokenHash)
+			return &invitation, nil
+		},
+		RemoveInvitation: func(tokenHash string, userContext UserContext) error {
+			delete(invitations, tokenHash)
+			return nil
+		},
+	}
+}
+
+func TestSignUpGateConfigValidation(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, SignUpModeOpen, gate.Mode)
+
+	_, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{Mode: "PRIVATE"})
+	assert.Error(t, err)
+
+	_, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{Mode: SignUpModeInviteOnly})
+	assert.Error(t, err)
+
+	_, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{Mode: SignUpModeInviteOnly, Invitations: &InvitationConfig{}})
+	assert.Error(t, err)
+
+	invitationConfig := makeInMemoryInvitationConfig(map[string]Invitation{}, &[]string{})
+	invitationConfig.ConsumeInvitation = nil
+	_, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{Mode: SignUpModeInviteOnly, Invitations: invitationConfig})
+	assert.Error(t, err)
+}
+
+func TestSignUpGateModes(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+	userContext := &map[string]interface{}{}
+	email := "john@example.com"
+
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{})
+	assert.NoError(t, err)
+	msg, err := checkSignUpAllowed(gate, &email, nil, nil, 1000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	gate, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{Mode: SignUpModeDisabled})
+	assert.NoError(t, err)
+	msg, err = checkSignUpAllowed(gate, &email, nil, nil, 1000, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sign up is disabled", *msg)
+
+	gate, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:           SignUpModeAllowlist,
+		AllowedEmails:  []string{"Jane@Other.com"},
+		AllowedDomains: []string{"example.com"},
+	})
+	assert.NoError(t, err)
+	for _, allowedEmail := range []string{"john@example.com", "john@eu.example.com", "jane@other.com"} {
+		msg, err = checkSignUpAllowed(gate, &allowedEmail, nil, nil, 1000, userContext)
+		assert.NoError(t, err)
+		assert.Nil(t, msg, allowedEmail)
+	}
+	otherEmail := "john@other.com"
+	msg, err = checkSignUpAllowed(gate, &otherEmail, nil, nil, 1000, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sign up is not allowed for this email", *msg)
+	msg, err = checkSignUpAllowed(gate, nil, nil, nil, 1000, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sign up is not allowed for this email", *msg)
+}
+
+func TestInvitationsAreSingleUseAndExpire(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+	userContext := &map[string]interface{}{}
+	invitations := map[string]Invitation{}
+	sentURLs := []string{}
+	var redeemedInvitation *Invitation
+	invitationConfig := makeInMemoryInvitationConfig(invitations, &sentURLs)
+	invitationConfig.OnInvitationRedeemed = func(userID string, invitation Invitation, userContext UserContext) error {
+		redeemedInvitation = &invitation
+		return nil
+	}
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:        SignUpModeInviteOnly,
+		Invitations: invitationConfig,
+	})
+	assert.NoError(t, err)
+
+	inviteToken, err := createInvitation(gate.Invitations, Invitation{Email: "John@Example.com", Metadata: map[string]interface{}{"team": "sales"}, Roles: []string{"admin"}}, 1000, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://supertokens.io/auth/accept-invite?token=" + inviteToken}, sentURLs)
+	_, ok := invitations[inviteToken]
+	assert.False(t, ok, "the token itself must not be stored")
+
+	email := "john@example.com"
+	otherEmail := "jane@example.com"
+	wrongToken := "wrong"
+	msg, err := checkSignUpAllowed(gate, &email, nil, nil, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, "A valid invitation is required to sign up", *msg)
+	msg, err = checkSignUpAllowed(gate, &email, nil, &wrongToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	msg, err = checkSignUpAllowed(gate, &otherEmail, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	msg, err = checkSignUpAllowed(gate, &email, nil, &inviteToken, 1000+defaultInvitationValidityMS+1, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	msg, err = checkSignUpAllowed(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	// consuming the invitation for another email leaves it in place
+	invitation, msg, err := consumeInvitation(gate, &otherEmail, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, invitation)
+	assert.Equal(t, "A valid invitation is required to sign up", *msg)
+
+	invitation, msg, err = consumeInvitation(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+	assert.NotNil(t, invitation)
+
+	// the invitation cannot be used by a concurrent sign up once it is consumed
+	msg, err = checkSignUpAllowed(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	_, msg, err = consumeInvitation(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+
+	err = redeemInvitation(gate.Invitations, "userId", invitation, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, redeemedInvitation.Roles)
+	assert.Equal(t, map[string]interface{}{"team": "sales"}, redeemedInvitation.Metadata)
+}
+
+func TestConsumedInvitationsCanBeRestored(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+	userContext := &map[string]interface{}{}
+	invitations := map[string]Invitation{}
+	sentURLs := []string{}
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:        SignUpModeInviteOnly,
+		Invitations: makeInMemoryInvitationConfig(invitations, &sentURLs),
+	})
+	assert.NoError(t, err)
+
+	inviteToken, err := createInvitation(gate.Invitations, Invitation{Email: "john@example.com"}, 1000, userContext)
+	assert.NoError(t, err)
+	email := "john@example.com"
+	invitation, msg, err := consumeInvitation(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+	assert.Empty(t, invitations)
+
+	err = restoreInvitation(gate.Invitations, invitation, &inviteToken, userContext)
+	assert.NoError(t, err)
+	msg, err = checkSignUpAllowed(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	// expired invitations are not put back
+	_, msg, err = consumeInvitation(gate, &email, nil, &inviteToken, 1000+defaultInvitationValidityMS+1, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	assert.Empty(t, invitations)
+}
+
+func TestPhoneNumberInvitations(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+	userContext := &map[string]interface{}{}
+	sentURLs := []string{}
+	invitationConfig := makeInMemoryInvitationConfig(map[string]Invitation{}, &sentURLs)
+	invitationConfig.CreateAndSendCustomSms = nil
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:        SignUpModeInviteOnly,
+		Invitations: invitationConfig,
+	})
+	assert.NoError(t, err)
+	_, err = createInvitation(gate.Invitations, Invitation{PhoneNumber: "+14155552671"}, 1000, userContext)
+	assert.Error(t, err)
+
+	gate, err = normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:        SignUpModeInviteOnly,
+		Invitations: makeInMemoryInvitationConfig(map[string]Invitation{}, &sentURLs),
+	})
+	assert.NoError(t, err)
+	inviteToken, err := createInvitation(gate.Invitations, Invitation{PhoneNumber: "+14155552671"}, 1000, userContext)
+	assert.NoError(t, err)
+
+	phoneNumber := "+14155552671"
+	otherPhoneNumber := "+14155552672"
+	email := "john@example.com"
+	msg, err := checkSignUpAllowed(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	msg, err = checkSignUpAllowed(gate, nil, &otherPhoneNumber, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+	msg, err = checkSignUpAllowed(gate, nil, &phoneNumber, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+
+	invitation, msg, err := consumeInvitation(gate, nil, &phoneNumber, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+	assert.Equal(t, phoneNumber, invitation.PhoneNumber)
+}
+
+func TestInvitedUsersCanSignUpInAllowlistMode(t *testing.T) {
+	appInfo := getSignUpGateAppInfoForTests(t)
+	userContext := &map[string]interface{}{}
+	sentURLs := []string{}
+	gate, err := normaliseSignUpGateOrThrowError(appInfo, &SignUpGate{
+		Mode:           SignUpModeAllowlist,
+		AllowedDomains: []string{"example.com"},
+		Invitations:    makeInMemoryInvitationConfig(map[string]Invitation{}, &sentURLs),
+	})
+	assert.NoError(t, err)
+
+	inviteToken, err := createInvitation(gate.Invitations, Invitation{Email: "contractor@other.com"}, 1000, userContext)
+	assert.NoError(t, err)
+	email := "contractor@other.com"
+	msg, err := checkSignUpAllowed(gate, &email, nil, &inviteToken, 2000, userContext)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
+}
+
+func TestInviteTokenInUserContext(t *testing.T) {
+	userContext := &map[string]interface{}{}
+	assert.Nil(t, GetInviteToken(userContext))
+	SetInviteToken(userContext, "")
+	assert.Nil(t, GetInviteToken(userContext))
+	SetInviteToken(userContext, "token")
+	assert.Equal(t, "token", *GetInviteToken(userContext))
+}
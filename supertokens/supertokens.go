@@ -29,6 +29,7 @@ type superTokens struct {
 	AppInfo        NormalisedAppinfo
 	RecipeModules  []RecipeModule
 	OnGeneralError func(err error, req *http.Request, res http.ResponseWriter)
+	SignUpGate     NormalisedSignUpGate
 }
 
 // this will be set to true if this is used in a test app environment
@@ -53,6 +54,11 @@ func supertokensInit(config TypeInput) error {
 		return err
 	}
 
+	superTokens.SignUpGate, err = normaliseSignUpGateOrThrowError(superTokens.AppInfo, config.SignUpGate)
+	if err != nil {
+		return err
+	}
+
 	if config.Supertokens != nil {
 		if len(config.Supertokens.ConnectionURI) != 0 {
 			hostList := strings.Split(config.Supertokens.ConnectionURI, ";")